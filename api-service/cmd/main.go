@@ -10,25 +10,44 @@ import (
 	"time"
 
 	"github.com/gorilla/mux"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"google.golang.org/grpc/credentials"
 
 	"github.com/N0F1X3d/todo/api-service/internal/clients/grpcclient"
 	"github.com/N0F1X3d/todo/api-service/internal/config"
 	"github.com/N0F1X3d/todo/api-service/internal/http-server/handlers"
 	"github.com/N0F1X3d/todo/api-service/internal/http-server/middleware"
-	pkgKafka "github.com/N0F1X3d/todo/pkg/kafka"
+	"github.com/N0F1X3d/todo/pkg/eventbus"
 	"github.com/N0F1X3d/todo/pkg/logger"
+	"github.com/N0F1X3d/todo/pkg/tlsconfig"
 )
 
 func main() {
 	// ===== Config =====
 	cfg, err := config.Load()
-	if err != nil {
+	clientOpts := grpcclient.DefaultOptions()
+	if err == nil {
+		clientOpts = cfg.GRPCClientOptions()
+	} else {
 		cfg = &config.Config{
 			ServiceName: "todo-api-service",
 			HTTPHost:    "0.0.0.0",
 			HTTPPort:    8080,
 			GRPCHost:    "localhost",
 			GRPCPort:    50051,
+
+			ReadinessCacheTTL:  3 * time.Second,
+			ReadinessTimeout:   2 * time.Second,
+			ShutdownDrainDelay: 3 * time.Second,
+
+			EventBus:     eventbus.DriverKafka,
+			KafkaBrokers: []string{"localhost:9092"},
+			KafkaTopic:   "task-events",
+
+			EventBusAsync:        true,
+			EventBusQueueSize:    1024,
+			EventBusDrainTimeout: 5 * time.Second,
 		}
 	}
 
@@ -37,22 +56,78 @@ func main() {
 
 	appLogger.Info("Starting API Service",
 		"http_addr", cfg.HTTPAddress(),
-		"grpc_addr", cfg.GRPCAddress(),
+		"grpc_targets", cfg.GRPCTargetList(),
 	)
 
+	// ===== gRPC TLS =====
+	if cfg.GRPCTLSEnabled {
+		tlsReloader, err := tlsconfig.NewReloader(cfg.GRPCTLSCertFile, cfg.GRPCTLSKeyFile, cfg.GRPCTLSCAFile)
+		if err != nil {
+			appLogger.Fatal("Failed to load gRPC TLS certificates", "error", err)
+		}
+
+		serverName := cfg.GRPCTLSServerName
+		if serverName == "" {
+			serverName = cfg.GRPCHost
+		}
+		clientOpts.Creds = credentials.NewTLS(tlsconfig.ClientConfig(tlsReloader, serverName))
+
+		reloadCtx, reloadCancel := context.WithCancel(context.Background())
+		defer reloadCancel()
+		go tlsReloader.Watch(reloadCtx, cfg.GRPCTLSReloadInterval, appLogger)
+
+		appLogger.Info("gRPC TLS enabled", "server_name", serverName, "mtls", cfg.GRPCTLSCertFile != "")
+	}
+
 	// ===== gRPC client =====
-	grpcClient, err := grpcclient.NewTaskClient(cfg.GRPCAddress(), appLogger)
+	target := grpcclient.ResolveTarget(cfg.GRPCTargetList(), cfg.GRPCSubsetSize, cfg.SubsetClientID())
+	clientOpts.DialOptions = append(clientOpts.DialOptions, target.DialOptions...)
+	clientOpts.Metrics = grpcclient.NewConnMetrics(prometheus.DefaultRegisterer)
+
+	appLogger.Info("db-service target resolved",
+		"target", target.Address,
+		"backends", target.Backends,
+		"lb_policy", clientOpts.LoadBalancingPolicy,
+	)
+
+	grpcClient, err := grpcclient.NewTaskClient(target.Address, clientOpts, appLogger)
 	if err != nil {
-		appLogger.Fatal("Failed to connect to db-service", err)
+		appLogger.Fatal("Failed to connect to db-service", "error", err)
 	}
 	defer grpcClient.Close()
 
-	// ===== Kafka producer =====
-	producer := pkgKafka.NewProducer([]string{"kafka:9092"}, "task-events")
-	defer producer.Close()
+	// ===== Event bus =====
+	var eventBusCheck func(context.Context) error
+	var publisher eventbus.Publisher
+	publisher, err = eventbus.NewPublisher(context.Background(), cfg.EventBusConfig())
+	if err != nil {
+		appLogger.Fatal("Failed to create event publisher", "error", err, "driver", cfg.EventBus)
+	}
+	// Синхронный publisher - зависимость готовности; асинхронный копит события
+	// в спуле, поэтому недоступный брокер не снимает готовность
+	if pinger, ok := publisher.(eventbus.Pinger); ok && !cfg.EventBusAsync {
+		eventBusCheck = pinger.Ping
+	}
+	if cfg.EventBusAsync {
+		publisher, err = eventbus.NewAsync(publisher,
+			eventbus.WithQueueSize(cfg.EventBusQueueSize),
+			eventbus.WithSpool(cfg.EventBusSpoolPath),
+			eventbus.WithDrainTimeout(cfg.EventBusDrainTimeout),
+			eventbus.WithMetrics(eventbus.NewMetrics(prometheus.DefaultRegisterer)),
+		)
+		if err != nil {
+			appLogger.Fatal("Failed to open event spool", "error", err, "path", cfg.EventBusSpoolPath)
+		}
+	}
 
 	// ===== Handlers =====
-	taskHandler := handlers.NewTaskHandler(grpcClient, producer, appLogger)
+	taskHandler := handlers.NewTaskHandler(grpcClient, publisher, appLogger)
+
+	healthHandler := handlers.NewHealthHandler(cfg.ServiceName, cfg.ReadinessCacheTTL, cfg.ReadinessTimeout, appLogger)
+	healthHandler.AddCheck("db-service", grpcClient.Check)
+	if eventBusCheck != nil {
+		healthHandler.AddCheck(cfg.EventBus, eventBusCheck)
+	}
 
 	// ===== Router =====
 	router := mux.NewRouter().StrictSlash(true)
@@ -73,11 +148,18 @@ func main() {
 		})
 	}).Methods(http.MethodGet)
 
+	router.HandleFunc("/livez", healthHandler.Livez).Methods(http.MethodGet)
+	router.HandleFunc("/readyz", healthHandler.Readyz).Methods(http.MethodGet)
+
+	// ===== Metrics =====
+	router.Handle("/metrics", promhttp.Handler()).Methods(http.MethodGet)
+
 	// ===== Middleware =====
 	handler := middleware.Chain(
 		router,
 		middleware.CORSMiddleware,
 		middleware.SecurityHeadersMiddleware,
+		middleware.RequestIDMiddleware,
 		func(next http.Handler) http.Handler {
 			return middleware.LoggingMiddleware(next, appLogger)
 		},
@@ -99,18 +181,27 @@ func main() {
 	go func() {
 		appLogger.Info("API Service started", "address", cfg.HTTPAddress())
 		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
-			appLogger.Fatal("Failed to start server", err)
+			appLogger.Fatal("Failed to start server", "error", err)
 		}
 	}()
 
 	<-stop
 	appLogger.Info("Shutting down API Service...")
 
+	// Сначала снимаем готовность и даем балансировщику время вывести сервис из ротации
+	healthHandler.SetShuttingDown()
+	time.Sleep(cfg.ShutdownDrainDelay)
+
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
 	if err := server.Shutdown(ctx); err != nil {
-		appLogger.Error("Server forced to shutdown", err)
+		appLogger.Error("Server forced to shutdown", "error", err)
+	}
+
+	// Отправляем события, накопленные в очереди
+	if err := publisher.Close(); err != nil {
+		appLogger.Error("Failed to drain event publisher", "error", err)
 	}
 
 	appLogger.Info("API Service stopped")
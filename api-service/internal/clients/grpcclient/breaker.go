@@ -0,0 +1,198 @@
+package grpcclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// ErrCircuitOpen возвращается, пока circuit breaker не пропускает запросы к db-service
+var ErrCircuitOpen = errors.New("circuit breaker is open")
+
+// CircuitOpenError - ошибка открытого circuit breaker с подсказкой,
+// через сколько стоит повторить запрос
+type CircuitOpenError struct {
+	RetryAfter time.Duration
+}
+
+func (e *CircuitOpenError) Error() string {
+	return fmt.Sprintf("%s, retry after %s", ErrCircuitOpen, e.RetryAfter)
+}
+
+func (e *CircuitOpenError) Is(target error) bool {
+	return target == ErrCircuitOpen
+}
+
+// BreakerState - состояние circuit breaker
+type BreakerState int
+
+const (
+	BreakerClosed BreakerState = iota
+	BreakerOpen
+	BreakerHalfOpen
+)
+
+func (s BreakerState) String() string {
+	switch s {
+	case BreakerClosed:
+		return "closed"
+	case BreakerOpen:
+		return "open"
+	case BreakerHalfOpen:
+		return "half-open"
+	default:
+		return "unknown"
+	}
+}
+
+// BreakerSettings - настройки circuit breaker
+type BreakerSettings struct {
+	// FailureThreshold - сколько ошибок подряд открывают breaker (0 - breaker выключен)
+	FailureThreshold int
+	// OpenTimeout - сколько breaker остается открытым до пробного запроса
+	OpenTimeout time.Duration
+}
+
+// CircuitBreaker быстро отклоняет запросы, пока db-service недоступен.
+// Closed: запросы проходят, ошибки считаются подряд.
+// Open: запросы отклоняются с CircuitOpenError до истечения OpenTimeout.
+// HalfOpen: пропускается один пробный запрос; успех закрывает breaker, ошибка снова открывает.
+type CircuitBreaker struct {
+	settings BreakerSettings
+	now      func() time.Time
+
+	mu            sync.Mutex
+	state         BreakerState
+	failures      int
+	openedAt      time.Time
+	probeInFlight bool
+}
+
+// NewCircuitBreaker создает CircuitBreaker
+func NewCircuitBreaker(settings BreakerSettings) *CircuitBreaker {
+	return &CircuitBreaker{
+		settings: settings,
+		now:      time.Now,
+	}
+}
+
+// State возвращает текущее состояние breaker
+func (b *CircuitBreaker) State() BreakerState {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.advanceLocked()
+	return b.state
+}
+
+// Allow проверяет, можно ли выполнить запрос
+func (b *CircuitBreaker) Allow() error {
+	if b.settings.FailureThreshold <= 0 {
+		return nil
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.advanceLocked()
+
+	switch b.state {
+	case BreakerOpen:
+		return &CircuitOpenError{RetryAfter: b.settings.OpenTimeout - b.now().Sub(b.openedAt)}
+	case BreakerHalfOpen:
+		if b.probeInFlight {
+			return &CircuitOpenError{RetryAfter: b.settings.OpenTimeout}
+		}
+		b.probeInFlight = true
+	}
+
+	return nil
+}
+
+// Record учитывает результат запроса
+func (b *CircuitBreaker) Record(success bool) {
+	if b.settings.FailureThreshold <= 0 {
+		return
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if success {
+		b.state = BreakerClosed
+		b.failures = 0
+		b.probeInFlight = false
+		return
+	}
+
+	b.failures++
+	if b.state == BreakerHalfOpen || b.failures >= b.settings.FailureThreshold {
+		b.state = BreakerOpen
+		b.openedAt = b.now()
+		b.probeInFlight = false
+	}
+}
+
+// Release освобождает пробный запрос HalfOpen, не учитывая его результат:
+// отмененный вызывающим запрос ничего не говорит о доступности db-service
+func (b *CircuitBreaker) Release() {
+	if b.settings.FailureThreshold <= 0 {
+		return
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.probeInFlight = false
+}
+
+// advanceLocked переводит Open в HalfOpen по истечении OpenTimeout
+func (b *CircuitBreaker) advanceLocked() {
+	if b.state == BreakerOpen && b.now().Sub(b.openedAt) >= b.settings.OpenTimeout {
+		b.state = BreakerHalfOpen
+		b.probeInFlight = false
+	}
+}
+
+// UnaryClientInterceptor применяет breaker ко всем unary вызовам.
+// Ошибкой считаются только признаки недоступности db-service
+// (Unavailable, DeadlineExceeded); бизнес-ошибки (NotFound и т.п.) breaker не открывают.
+// Отмененные вызовы не учитываются вовсе.
+func (b *CircuitBreaker) UnaryClientInterceptor() grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		if err := b.Allow(); err != nil {
+			return err
+		}
+
+		err := invoker(ctx, method, req, reply, cc, opts...)
+		if isCanceled(err) {
+			b.Release()
+			return err
+		}
+		b.Record(!isUnavailable(err))
+		return err
+	}
+}
+
+func isCanceled(err error) bool {
+	return errors.Is(err, context.Canceled) || status.Code(err) == codes.Canceled
+}
+
+func isUnavailable(err error) bool {
+	if err == nil {
+		return false
+	}
+
+	switch status.Code(err) {
+	case codes.Unavailable, codes.DeadlineExceeded:
+		return true
+	default:
+		return false
+	}
+}
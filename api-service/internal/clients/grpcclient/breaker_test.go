@@ -0,0 +1,102 @@
+package grpcclient_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/N0F1X3d/todo/api-service/internal/clients/grpcclient"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestCircuitBreaker_OpensAfterThreshold(t *testing.T) {
+	b := grpcclient.NewCircuitBreaker(grpcclient.BreakerSettings{FailureThreshold: 3, OpenTimeout: time.Minute})
+
+	for i := 0; i < 2; i++ {
+		assert.NoError(t, b.Allow())
+		b.Record(false)
+	}
+	assert.Equal(t, grpcclient.BreakerClosed, b.State())
+
+	assert.NoError(t, b.Allow())
+	b.Record(false)
+	assert.Equal(t, grpcclient.BreakerOpen, b.State())
+
+	assert.True(t, errors.Is(b.Allow(), grpcclient.ErrCircuitOpen))
+}
+
+func TestCircuitBreaker_SuccessResetsFailures(t *testing.T) {
+	b := grpcclient.NewCircuitBreaker(grpcclient.BreakerSettings{FailureThreshold: 2, OpenTimeout: time.Minute})
+
+	b.Record(false)
+	b.Record(true)
+	b.Record(false)
+
+	assert.Equal(t, grpcclient.BreakerClosed, b.State())
+}
+
+func TestCircuitBreaker_HalfOpenProbe(t *testing.T) {
+	b := grpcclient.NewCircuitBreaker(grpcclient.BreakerSettings{FailureThreshold: 1, OpenTimeout: 20 * time.Millisecond})
+
+	b.Record(false)
+	assert.Equal(t, grpcclient.BreakerOpen, b.State())
+
+	time.Sleep(30 * time.Millisecond)
+	assert.Equal(t, grpcclient.BreakerHalfOpen, b.State())
+
+	// Пропускается только один пробный запрос
+	assert.NoError(t, b.Allow())
+	assert.Error(t, b.Allow())
+
+	b.Record(true)
+	assert.Equal(t, grpcclient.BreakerClosed, b.State())
+	assert.NoError(t, b.Allow())
+}
+
+func TestCircuitBreaker_HalfOpenProbeFailureReopens(t *testing.T) {
+	b := grpcclient.NewCircuitBreaker(grpcclient.BreakerSettings{FailureThreshold: 1, OpenTimeout: 20 * time.Millisecond})
+
+	b.Record(false)
+	time.Sleep(30 * time.Millisecond)
+
+	assert.NoError(t, b.Allow())
+	b.Record(false)
+
+	assert.Equal(t, grpcclient.BreakerOpen, b.State())
+	assert.Error(t, b.Allow())
+}
+
+func TestCircuitBreaker_CanceledProbeDoesNotClose(t *testing.T) {
+	b := grpcclient.NewCircuitBreaker(grpcclient.BreakerSettings{FailureThreshold: 1, OpenTimeout: 20 * time.Millisecond})
+	interceptor := b.UnaryClientInterceptor()
+
+	b.Record(false)
+	time.Sleep(30 * time.Millisecond)
+
+	for _, canceled := range []error{status.Error(codes.Canceled, "canceled"), context.Canceled} {
+		err := interceptor(context.Background(), "/test", nil, nil, nil,
+			func(context.Context, string, any, any, *grpc.ClientConn, ...grpc.CallOption) error {
+				return canceled
+			})
+		assert.ErrorIs(t, err, canceled)
+		assert.Equal(t, grpcclient.BreakerHalfOpen, b.State())
+	}
+
+	// Слот пробного запроса освобожден
+	assert.NoError(t, b.Allow())
+	assert.Error(t, b.Allow())
+}
+
+func TestCircuitBreaker_Disabled(t *testing.T) {
+	b := grpcclient.NewCircuitBreaker(grpcclient.BreakerSettings{FailureThreshold: 0})
+
+	for i := 0; i < 10; i++ {
+		b.Record(false)
+	}
+
+	assert.NoError(t, b.Allow())
+}
@@ -0,0 +1,32 @@
+package grpcclient
+
+import (
+	"context"
+
+	"github.com/N0F1X3d/todo/pkg/kafka"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// eventMetaInterceptor передает метаданные запроса (request ID, trace ID, пользователь из X-Actor)
+// в db-service, где они попадают в события задач
+func eventMetaInterceptor() grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		meta := kafka.EventMetaFromContext(ctx)
+
+		var kv []string
+		if meta.RequestID != "" {
+			kv = append(kv, kafka.MetadataRequestID, meta.RequestID)
+		}
+		if meta.TraceID != "" {
+			kv = append(kv, kafka.MetadataTraceID, meta.TraceID)
+		}
+		if meta.ForwardedActor != "" {
+			kv = append(kv, kafka.MetadataActor, meta.ForwardedActor)
+		}
+		if len(kv) > 0 {
+			ctx = metadata.AppendToOutgoingContext(ctx, kv...)
+		}
+		return invoker(ctx, method, req, reply, cc, opts...)
+	}
+}
@@ -0,0 +1,115 @@
+package grpcclient
+
+import (
+	"context"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/connectivity"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/stats"
+	"google.golang.org/grpc/status"
+)
+
+// ConnMetrics собирает метрики соединений с репликами db-service:
+// открытые соединения и RPC по каждому бэкенду и состояние канала в целом
+type ConnMetrics struct {
+	connections  *prometheus.GaugeVec
+	connEvents   *prometheus.CounterVec
+	rpcs         *prometheus.CounterVec
+	channelState *prometheus.GaugeVec
+}
+
+// NewConnMetrics создает и регистрирует метрики в reg
+func NewConnMetrics(reg prometheus.Registerer) *ConnMetrics {
+	m := &ConnMetrics{
+		connections: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "grpc_client_connections",
+			Help: "Open transport connections to db-service by backend address.",
+		}, []string{"backend"}),
+		connEvents: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "grpc_client_connection_events_total",
+			Help: "Transport connection events to db-service by backend address.",
+		}, []string{"backend", "event"}),
+		rpcs: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "grpc_client_rpcs_total",
+			Help: "Completed RPCs to db-service by backend address and status code.",
+		}, []string{"backend", "code"}),
+		channelState: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "grpc_client_channel_state",
+			Help: "Current connectivity state of the db-service channel (1 for the active state).",
+		}, []string{"state"}),
+	}
+
+	reg.MustRegister(m.connections, m.connEvents, m.rpcs, m.channelState)
+	return m
+}
+
+type connAddrKey struct{}
+
+// TagConn запоминает адрес бэкенда для HandleConn
+func (m *ConnMetrics) TagConn(ctx context.Context, info *stats.ConnTagInfo) context.Context {
+	return context.WithValue(ctx, connAddrKey{}, info.RemoteAddr.String())
+}
+
+// HandleConn учитывает открытие и закрытие соединений с бэкендом
+func (m *ConnMetrics) HandleConn(ctx context.Context, s stats.ConnStats) {
+	backend, _ := ctx.Value(connAddrKey{}).(string)
+
+	switch s.(type) {
+	case *stats.ConnBegin:
+		m.connections.WithLabelValues(backend).Inc()
+		m.connEvents.WithLabelValues(backend, "open").Inc()
+	case *stats.ConnEnd:
+		m.connections.WithLabelValues(backend).Dec()
+		m.connEvents.WithLabelValues(backend, "close").Inc()
+	}
+}
+
+func (m *ConnMetrics) TagRPC(ctx context.Context, _ *stats.RPCTagInfo) context.Context {
+	return ctx
+}
+
+func (m *ConnMetrics) HandleRPC(context.Context, stats.RPCStats) {}
+
+// UnaryClientInterceptor считает RPC по бэкенду, который обслужил вызов
+func (m *ConnMetrics) UnaryClientInterceptor() grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		var p peer.Peer
+		err := invoker(ctx, method, req, reply, cc, append(opts, grpc.Peer(&p))...)
+
+		backend := "none"
+		if p.Addr != nil {
+			backend = p.Addr.String()
+		}
+		m.rpcs.WithLabelValues(backend, status.Code(err).String()).Inc()
+
+		return err
+	}
+}
+
+// WatchState обновляет grpc_client_channel_state до отмены ctx
+func (m *ConnMetrics) WatchState(ctx context.Context, conn *grpc.ClientConn) {
+	states := []connectivity.State{
+		connectivity.Idle,
+		connectivity.Connecting,
+		connectivity.Ready,
+		connectivity.TransientFailure,
+		connectivity.Shutdown,
+	}
+
+	for {
+		current := conn.GetState()
+		for _, state := range states {
+			value := 0.0
+			if state == current {
+				value = 1
+			}
+			m.channelState.WithLabelValues(state.String()).Set(value)
+		}
+
+		if !conn.WaitForStateChange(ctx, current) {
+			return
+		}
+	}
+}
@@ -0,0 +1,154 @@
+package grpcclient
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	pb "github.com/N0F1X3d/todo/pkg/proto/v2"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/keepalive"
+)
+
+// Timeouts - дедлайны отдельных RPC к db-service
+type Timeouts struct {
+	Create   time.Duration
+	Get      time.Duration
+	List     time.Duration
+	Complete time.Duration
+	Delete   time.Duration
+}
+
+// RetryPolicy - политика повторов для идемпотентных RPC (GetTaskByID, GetAllTasks).
+// Паузы между попытками растут экспоненциально; gRPC добавляет к ним случайный jitter.
+type RetryPolicy struct {
+	// MaxAttempts - общее число попыток, включая первую (1 - без повторов)
+	MaxAttempts       int
+	InitialBackoff    time.Duration
+	MaxBackoff        time.Duration
+	BackoffMultiplier float64
+}
+
+// Options - настройки TaskClient
+type Options struct {
+	// Creds - transport credentials; nil - соединение без TLS
+	Creds    credentials.TransportCredentials
+	Timeouts Timeouts
+	Retry    RetryPolicy
+	Breaker  BreakerSettings
+	// LoadBalancingPolicy - политика балансировки между репликами ("round_robin", "pick_first")
+	LoadBalancingPolicy string
+	// HealthCheck включает клиентскую проверку каждой реплики через grpc.health.v1:
+	// реплики в NOT_SERVING исключаются из балансировки
+	HealthCheck bool
+	// Keepalive - параметры keepalive ping (Time == 0 - выключены)
+	Keepalive keepalive.ClientParameters
+	// Metrics - метрики соединений; nil - не собираются
+	Metrics *ConnMetrics
+	// DialOptions - дополнительные опции соединения (например, dialer в тестах)
+	DialOptions []grpc.DialOption
+}
+
+// DefaultOptions возвращает настройки по умолчанию
+func DefaultOptions() Options {
+	return Options{
+		Timeouts: Timeouts{
+			Create:   5 * time.Second,
+			Get:      5 * time.Second,
+			List:     5 * time.Second,
+			Complete: 5 * time.Second,
+			Delete:   5 * time.Second,
+		},
+		Retry: RetryPolicy{
+			MaxAttempts:       3,
+			InitialBackoff:    100 * time.Millisecond,
+			MaxBackoff:        time.Second,
+			BackoffMultiplier: 2,
+		},
+		Breaker: BreakerSettings{
+			FailureThreshold: 5,
+			OpenTimeout:      10 * time.Second,
+		},
+		LoadBalancingPolicy: "round_robin",
+		HealthCheck:         true,
+		Keepalive: keepalive.ClientParameters{
+			Time:                30 * time.Second,
+			Timeout:             10 * time.Second,
+			PermitWithoutStream: true,
+		},
+	}
+}
+
+type methodName struct {
+	Service string `json:"service"`
+	Method  string `json:"method"`
+}
+
+type retryPolicyConfig struct {
+	MaxAttempts          int      `json:"maxAttempts"`
+	InitialBackoff       string   `json:"initialBackoff"`
+	MaxBackoff           string   `json:"maxBackoff"`
+	BackoffMultiplier    float64  `json:"backoffMultiplier"`
+	RetryableStatusCodes []string `json:"retryableStatusCodes"`
+}
+
+type methodConfig struct {
+	Name        []methodName       `json:"name"`
+	RetryPolicy *retryPolicyConfig `json:"retryPolicy,omitempty"`
+}
+
+type healthCheckConfig struct {
+	ServiceName string `json:"serviceName"`
+}
+
+type serviceConfig struct {
+	LoadBalancingConfig []map[string]struct{} `json:"loadBalancingConfig,omitempty"`
+	HealthCheckConfig   *healthCheckConfig    `json:"healthCheckConfig,omitempty"`
+	MethodConfig        []methodConfig        `json:"methodConfig,omitempty"`
+}
+
+// serviceConfigJSON строит gRPC service config: политику балансировки,
+// клиентскую проверку здоровья реплик и retry policy для идемпотентных RPC.
+// Неидемпотентные Create/Complete/Delete не повторяются, чтобы не выполнить запись дважды.
+func serviceConfigJSON(opts Options) (string, error) {
+	service := pb.TaskService_ServiceDesc.ServiceName
+
+	var cfg serviceConfig
+	if opts.LoadBalancingPolicy != "" {
+		cfg.LoadBalancingConfig = []map[string]struct{}{{opts.LoadBalancingPolicy: {}}}
+	}
+	if opts.HealthCheck {
+		cfg.HealthCheckConfig = &healthCheckConfig{ServiceName: service}
+	}
+
+	policy := opts.Retry
+	if policy.MaxAttempts > 1 {
+		cfg.MethodConfig = []methodConfig{
+			{
+				Name: []methodName{
+					{Service: service, Method: "GetTaskByID"},
+					{Service: service, Method: "GetAllTasks"},
+				},
+				RetryPolicy: &retryPolicyConfig{
+					MaxAttempts:          policy.MaxAttempts,
+					InitialBackoff:       durationString(policy.InitialBackoff),
+					MaxBackoff:           durationString(policy.MaxBackoff),
+					BackoffMultiplier:    policy.BackoffMultiplier,
+					RetryableStatusCodes: []string{"UNAVAILABLE"},
+				},
+			},
+		}
+	}
+
+	data, err := json.Marshal(cfg)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// durationString форматирует длительность в формате google.protobuf.Duration ("0.1s")
+func durationString(d time.Duration) string {
+	return fmt.Sprintf("%gs", d.Seconds())
+}
@@ -0,0 +1,93 @@
+package grpcclient
+
+import (
+	"hash/fnv"
+	"sort"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/resolver"
+	"google.golang.org/grpc/resolver/manual"
+)
+
+// staticScheme - схема resolver для фиксированного списка реплик db-service
+const staticScheme = "static"
+
+// Target описывает, куда подключается TaskClient
+type Target struct {
+	// Address - строка target для grpc.NewClient
+	Address string
+	// DialOptions - опции, необходимые для разрешения target (resolver)
+	DialOptions []grpc.DialOption
+	// Backends - адреса реплик после subsetting (пусто для DNS)
+	Backends []string
+}
+
+// ResolveTarget строит target для списка реплик db-service.
+//
+// Один адрес разрешается через DNS (dns:///host:port): все A-записи имени
+// становятся отдельными бэкендами, например при docker compose --scale.
+// Несколько адресов передаются статическим resolver. Если subsetSize > 0
+// и реплик больше, клиент использует только subsetSize из них, выбранных
+// rendezvous hashing по clientID: подмножество стабильно для клиента,
+// а разные клиенты равномерно распределяются по репликам.
+func ResolveTarget(addrs []string, subsetSize int, clientID string) Target {
+	if len(addrs) == 1 {
+		addr := addrs[0]
+		if !strings.Contains(addr, ":///") {
+			addr = "dns:///" + addr
+		}
+		return Target{Address: addr}
+	}
+
+	backends := Subset(addrs, subsetSize, clientID)
+
+	state := resolver.State{Addresses: make([]resolver.Address, 0, len(backends))}
+	for _, addr := range backends {
+		state.Addresses = append(state.Addresses, resolver.Address{Addr: addr})
+	}
+
+	r := manual.NewBuilderWithScheme(staticScheme)
+	r.InitialState(state)
+
+	return Target{
+		Address:     staticScheme + ":///db-service",
+		DialOptions: []grpc.DialOption{grpc.WithResolvers(r)},
+		Backends:    backends,
+	}
+}
+
+// Subset выбирает size адресов из addrs детерминированно для clientID.
+// Если size <= 0 или не меньше числа адресов, возвращаются все адреса.
+func Subset(addrs []string, size int, clientID string) []string {
+	if size <= 0 || size >= len(addrs) {
+		return append([]string(nil), addrs...)
+	}
+
+	type scored struct {
+		addr  string
+		score uint64
+	}
+
+	scores := make([]scored, 0, len(addrs))
+	for _, addr := range addrs {
+		h := fnv.New64a()
+		_, _ = h.Write([]byte(clientID))
+		_, _ = h.Write([]byte{0})
+		_, _ = h.Write([]byte(addr))
+		scores = append(scores, scored{addr: addr, score: h.Sum64()})
+	}
+
+	sort.Slice(scores, func(i, j int) bool {
+		if scores[i].score == scores[j].score {
+			return scores[i].addr < scores[j].addr
+		}
+		return scores[i].score > scores[j].score
+	})
+
+	subset := make([]string, 0, size)
+	for _, s := range scores[:size] {
+		subset = append(subset, s.addr)
+	}
+	return subset
+}
@@ -0,0 +1,161 @@
+package grpcclient_test
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/N0F1X3d/todo/api-service/internal/clients/grpcclient"
+	"github.com/N0F1X3d/todo/pkg/logger"
+	pb "github.com/N0F1X3d/todo/pkg/proto/v2"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+// startReplica запускает реплику db-service на случайном TCP порту
+func startReplica(t *testing.T, srv *fakeTaskServer, servingStatus healthpb.HealthCheckResponse_ServingStatus) string {
+	t.Helper()
+
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	healthServer := health.NewServer()
+	healthServer.SetServingStatus(pb.TaskService_ServiceDesc.ServiceName, servingStatus)
+
+	grpcServer := grpc.NewServer()
+	pb.RegisterTaskServiceServer(grpcServer, srv)
+	healthpb.RegisterHealthServer(grpcServer, healthServer)
+	go func() { _ = grpcServer.Serve(lis) }()
+	t.Cleanup(grpcServer.Stop)
+
+	return lis.Addr().String()
+}
+
+func newBalancedClient(t *testing.T, addrs []string, opts grpcclient.Options) *grpcclient.TaskClient {
+	t.Helper()
+
+	target := grpcclient.ResolveTarget(addrs, 0, "test")
+	opts.DialOptions = append(opts.DialOptions, target.DialOptions...)
+
+	testLogger := logger.New("api-service", "test-logs")
+	client, err := grpcclient.NewTaskClient(target.Address, opts, testLogger)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = client.Close() })
+
+	return client
+}
+
+func TestSubset_Deterministic(t *testing.T) {
+	addrs := []string{"db-1:50051", "db-2:50051", "db-3:50051", "db-4:50051", "db-5:50051"}
+
+	first := grpcclient.Subset(addrs, 2, "api-1")
+	second := grpcclient.Subset(addrs, 2, "api-1")
+
+	assert.Len(t, first, 2)
+	assert.Equal(t, first, second)
+}
+
+func TestSubset_SpreadsClients(t *testing.T) {
+	addrs := []string{"db-1:50051", "db-2:50051", "db-3:50051", "db-4:50051"}
+
+	used := make(map[string]bool)
+	for _, clientID := range []string{"api-1", "api-2", "api-3", "api-4", "api-5", "api-6", "api-7", "api-8"} {
+		for _, addr := range grpcclient.Subset(addrs, 1, clientID) {
+			used[addr] = true
+		}
+	}
+
+	assert.Greater(t, len(used), 1)
+}
+
+func TestSubset_AllWhenSizeNotSet(t *testing.T) {
+	addrs := []string{"db-1:50051", "db-2:50051"}
+
+	assert.Equal(t, addrs, grpcclient.Subset(addrs, 0, "api-1"))
+	assert.Equal(t, addrs, grpcclient.Subset(addrs, 5, "api-1"))
+}
+
+func TestResolveTarget_SingleAddressUsesDNS(t *testing.T) {
+	target := grpcclient.ResolveTarget([]string{"db-service:50051"}, 0, "api-1")
+
+	assert.Equal(t, "dns:///db-service:50051", target.Address)
+	assert.Empty(t, target.DialOptions)
+}
+
+func TestTaskClient_RoundRobinAcrossReplicas(t *testing.T) {
+	first := &fakeTaskServer{}
+	second := &fakeTaskServer{}
+	addrs := []string{
+		startReplica(t, first, healthpb.HealthCheckResponse_SERVING),
+		startReplica(t, second, healthpb.HealthCheckResponse_SERVING),
+	}
+	client := newBalancedClient(t, addrs, grpcclient.DefaultOptions())
+
+	// Ждем, пока обе реплики станут READY и попадут в picker
+	require.Eventually(t, func() bool {
+		_, err := client.GetTaskByID(context.Background(), 1)
+		require.NoError(t, err)
+		return first.getCalls.Load() > 0 && second.getCalls.Load() > 0
+	}, 5*time.Second, time.Millisecond)
+
+	firstBefore, secondBefore := first.getCalls.Load(), second.getCalls.Load()
+	for i := 0; i < 10; i++ {
+		_, err := client.GetTaskByID(context.Background(), 1)
+		require.NoError(t, err)
+	}
+
+	assert.Equal(t, int32(5), first.getCalls.Load()-firstBefore)
+	assert.Equal(t, int32(5), second.getCalls.Load()-secondBefore)
+}
+
+func TestTaskClient_SkipsNotServingReplica(t *testing.T) {
+	healthy := &fakeTaskServer{}
+	draining := &fakeTaskServer{}
+	addrs := []string{
+		startReplica(t, healthy, healthpb.HealthCheckResponse_SERVING),
+		startReplica(t, draining, healthpb.HealthCheckResponse_NOT_SERVING),
+	}
+	client := newBalancedClient(t, addrs, grpcclient.DefaultOptions())
+
+	for i := 0; i < 10; i++ {
+		_, err := client.GetTaskByID(context.Background(), 1)
+		require.NoError(t, err)
+	}
+
+	assert.Equal(t, int32(10), healthy.getCalls.Load())
+	assert.Zero(t, draining.getCalls.Load())
+}
+
+func TestTaskClient_ConnMetrics(t *testing.T) {
+	srv := &fakeTaskServer{}
+	addr := startReplica(t, srv, healthpb.HealthCheckResponse_SERVING)
+
+	reg := prometheus.NewRegistry()
+	opts := grpcclient.DefaultOptions()
+	opts.Metrics = grpcclient.NewConnMetrics(reg)
+	client := newBalancedClient(t, []string{addr}, opts)
+
+	for i := 0; i < 3; i++ {
+		_, err := client.GetTaskByID(context.Background(), 1)
+		require.NoError(t, err)
+	}
+
+	expected := fmt.Sprintf(`
+# HELP grpc_client_rpcs_total Completed RPCs to db-service by backend address and status code.
+# TYPE grpc_client_rpcs_total counter
+grpc_client_rpcs_total{backend=%q,code="OK"} 3
+`, addr)
+	assert.NoError(t, testutil.GatherAndCompare(reg, strings.NewReader(expected), "grpc_client_rpcs_total"))
+
+	connections, err := testutil.GatherAndCount(reg, "grpc_client_connections")
+	require.NoError(t, err)
+	assert.Equal(t, 1, connections)
+}
@@ -3,48 +3,121 @@ package grpcclient
 import (
 	"context"
 	"fmt"
-	"time"
 
 	"github.com/N0F1X3d/todo/pkg/logger"
-	pb "github.com/N0F1X3d/todo/pkg/proto"
+	pb "github.com/N0F1X3d/todo/pkg/proto/v2"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/connectivity"
 	"google.golang.org/grpc/credentials/insecure"
+	_ "google.golang.org/grpc/health" // клиентская проверка здоровья реплик (healthCheckConfig)
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
 )
 
 type TaskClient struct {
-	conn   *grpc.ClientConn
-	client pb.TaskServiceClient
-	log    *logger.Logger
+	conn     *grpc.ClientConn
+	client   pb.TaskServiceClient
+	health   healthpb.HealthClient
+	breaker  *CircuitBreaker
+	timeouts Timeouts
+	cancel   context.CancelFunc
+	log      *logger.Logger
 }
 
-func NewTaskClient(addr string, log *logger.Logger) (*TaskClient, error) {
+// NewTaskClient создает клиент db-service с балансировкой между репликами,
+// повторами идемпотентных RPC, circuit breaker и дедлайнами из opts.
+// target - строка для grpc.NewClient (см. ResolveTarget).
+func NewTaskClient(target string, opts Options, log *logger.Logger) (*TaskClient, error) {
 	const op = "NewTaskClient"
 	log = log.WithComponent("grpc-client").WithFunction("TaskClient")
-	conn, err := grpc.NewClient(
-		addr,
-		grpc.WithTransportCredentials(insecure.NewCredentials()),
-	)
+
+	creds := opts.Creds
+	if creds == nil {
+		creds = insecure.NewCredentials()
+	}
+
+	svcConfig, err := serviceConfigJSON(opts)
+	if err != nil {
+		log.ErrorWithContext("failed to build service config", err, op)
+		return nil, err
+	}
+
+	breaker := NewCircuitBreaker(opts.Breaker)
+
+	interceptors := []grpc.UnaryClientInterceptor{eventMetaInterceptor(), breaker.UnaryClientInterceptor()}
+	dialOpts := []grpc.DialOption{
+		grpc.WithTransportCredentials(creds),
+		grpc.WithDefaultServiceConfig(svcConfig),
+	}
+	if opts.Keepalive.Time > 0 {
+		dialOpts = append(dialOpts, grpc.WithKeepaliveParams(opts.Keepalive))
+	}
+	if opts.Metrics != nil {
+		interceptors = append(interceptors, opts.Metrics.UnaryClientInterceptor())
+		dialOpts = append(dialOpts, grpc.WithStatsHandler(opts.Metrics))
+	}
+	dialOpts = append(dialOpts, grpc.WithChainUnaryInterceptor(interceptors...))
+	dialOpts = append(dialOpts, opts.DialOptions...)
+
+	conn, err := grpc.NewClient(target, dialOpts...)
 	if err != nil {
 		log.ErrorWithContext("failed to create client", err, op)
 		return nil, err
 	}
 
-	log.Info("grpc clinet created, address:", addr, op)
+	ctx, cancel := context.WithCancel(context.Background())
+	if opts.Metrics != nil {
+		go opts.Metrics.WatchState(ctx, conn)
+	}
+
+	log.Info("grpc client created", "target", target, "lb_policy", opts.LoadBalancingPolicy, "function", op)
 
 	return &TaskClient{
-		conn:   conn,
-		client: pb.NewTaskServiceClient(conn),
-		log:    log,
+		conn:     conn,
+		client:   pb.NewTaskServiceClient(conn),
+		health:   healthpb.NewHealthClient(conn),
+		breaker:  breaker,
+		timeouts: opts.Timeouts,
+		cancel:   cancel,
+		log:      log,
 	}, nil
 }
 
+// BreakerState возвращает текущее состояние circuit breaker
+func (c *TaskClient) BreakerState() BreakerState {
+	return c.breaker.State()
+}
+
+// Check проверяет готовность db-service: состояние соединения и grpc.health.v1 Check
+func (c *TaskClient) Check(ctx context.Context) error {
+	const op = "Check"
+
+	if state := c.conn.GetState(); state == connectivity.TransientFailure || state == connectivity.Shutdown {
+		return fmt.Errorf("grpc connection state: %s", state)
+	}
+
+	resp, err := c.health.Check(ctx, &healthpb.HealthCheckRequest{
+		Service: pb.TaskService_ServiceDesc.ServiceName,
+	})
+	if err != nil {
+		c.log.ErrorWithContext("health check failed", err, op)
+		return err
+	}
+
+	if resp.GetStatus() != healthpb.HealthCheckResponse_SERVING {
+		return fmt.Errorf("db-service status: %s", resp.GetStatus())
+	}
+
+	return nil
+}
+
 func (c *TaskClient) Close() error {
 	c.log.Info("closing grpc connection")
+	c.cancel()
 	return c.conn.Close()
 }
 
 // CreateTask создает новую задачу
-func (c *TaskClient) CreateTask(ctx context.Context, title, description string) (*pb.TaskResponse, error) {
+func (c *TaskClient) CreateTask(ctx context.Context, title, description string) (*pb.Task, error) {
 	const op = "CreateTask"
 
 	log := c.log.WithFunction(op)
@@ -54,7 +127,7 @@ func (c *TaskClient) CreateTask(ctx context.Context, title, description string)
 		"description": description,
 	})
 
-	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	ctx, cancel := context.WithTimeout(ctx, c.timeouts.Create)
 	defer cancel()
 
 	resp, err := c.client.CreateTask(ctx, &pb.CreateTaskRequest{
@@ -71,14 +144,14 @@ func (c *TaskClient) CreateTask(ctx context.Context, title, description string)
 }
 
 // GetAllTasks получает все задачи
-func (c *TaskClient) GetAllTasks(ctx context.Context) ([]*pb.TaskResponse, error) {
+func (c *TaskClient) GetAllTasks(ctx context.Context) ([]*pb.Task, error) {
 	const op = "GetAllTasks"
 
 	log := c.log.WithFunction(op)
 
 	log.LogRequest(op, nil)
 
-	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	ctx, cancel := context.WithTimeout(ctx, c.timeouts.List)
 	defer cancel()
 
 	resp, err := c.client.GetAllTasks(ctx, &pb.GetAllTasksRequest{})
@@ -92,14 +165,14 @@ func (c *TaskClient) GetAllTasks(ctx context.Context) ([]*pb.TaskResponse, error
 }
 
 // DeleteTask удаляет задачу по ID
-func (c *TaskClient) DeleteTask(ctx context.Context, id int32) error {
+func (c *TaskClient) DeleteTask(ctx context.Context, id int64) error {
 	const op = "DeleteTask"
 
 	log := c.log.WithFunction(op)
 
 	log.LogRequest(op, map[string]interface{}{"id": id})
 
-	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	ctx, cancel := context.WithTimeout(ctx, c.timeouts.Delete)
 	defer cancel()
 
 	resp, err := c.client.DeleteTask(ctx, &pb.DeleteTaskRequest{
@@ -119,14 +192,14 @@ func (c *TaskClient) DeleteTask(ctx context.Context, id int32) error {
 }
 
 // CompleteTask отмечает задачу выполненной
-func (c *TaskClient) CompleteTask(ctx context.Context, id int32) (*pb.TaskResponse, error) {
+func (c *TaskClient) CompleteTask(ctx context.Context, id int64) (*pb.Task, error) {
 	const op = "CompleteTask"
 
 	log := c.log.WithFunction(op)
 
 	log.LogRequest(op, map[string]interface{}{"id": id})
 
-	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	ctx, cancel := context.WithTimeout(ctx, c.timeouts.Complete)
 	defer cancel()
 
 	resp, err := c.client.CompleteTask(ctx, &pb.CompleteTaskRequest{
@@ -143,14 +216,14 @@ func (c *TaskClient) CompleteTask(ctx context.Context, id int32) (*pb.TaskRespon
 }
 
 // GetTaskByID возвращает задачу по ее ID
-func (c *TaskClient) GetTaskByID(ctx context.Context, id int32) (*pb.TaskResponse, error) {
+func (c *TaskClient) GetTaskByID(ctx context.Context, id int64) (*pb.Task, error) {
 	const op = "GetTaskByID"
 
 	log := c.log.WithFunction(op)
 
 	log.LogRequest(op, map[string]interface{}{"id": id})
 
-	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	ctx, cancel := context.WithTimeout(ctx, c.timeouts.Get)
 	defer cancel()
 
 	resp, err := c.client.GetTaskByID(ctx, &pb.GetTaskByIDRequest{
@@ -0,0 +1,192 @@
+package grpcclient_test
+
+import (
+	"context"
+	"errors"
+	"net"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/N0F1X3d/todo/api-service/internal/clients/grpcclient"
+	"github.com/N0F1X3d/todo/pkg/kafka"
+	"github.com/N0F1X3d/todo/pkg/logger"
+	pb "github.com/N0F1X3d/todo/pkg/proto/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// fakeTaskServer отвечает Unavailable первые failures вызовов каждого метода
+type fakeTaskServer struct {
+	pb.UnimplementedTaskServiceServer
+
+	failures    int32
+	delay       time.Duration
+	createCalls atomic.Int32
+	listCalls   atomic.Int32
+	getCalls    atomic.Int32
+	createMD    atomic.Pointer[metadata.MD]
+}
+
+func (s *fakeTaskServer) CreateTask(ctx context.Context, req *pb.CreateTaskRequest) (*pb.Task, error) {
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		s.createMD.Store(&md)
+	}
+	if s.createCalls.Add(1) <= s.failures {
+		return nil, status.Error(codes.Unavailable, "db-service unavailable")
+	}
+	return &pb.Task{Id: 1, Title: req.GetTitle()}, nil
+}
+
+func (s *fakeTaskServer) GetAllTasks(ctx context.Context, req *pb.GetAllTasksRequest) (*pb.GetAllTasksResponse, error) {
+	if s.listCalls.Add(1) <= s.failures {
+		return nil, status.Error(codes.Unavailable, "db-service unavailable")
+	}
+	return &pb.GetAllTasksResponse{Tasks: []*pb.Task{{Id: 1}}}, nil
+}
+
+func (s *fakeTaskServer) GetTaskByID(ctx context.Context, req *pb.GetTaskByIDRequest) (*pb.Task, error) {
+	s.getCalls.Add(1)
+	if s.delay > 0 {
+		select {
+		case <-time.After(s.delay):
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+	if req.GetId() == 404 {
+		return nil, status.Error(codes.NotFound, "task not found")
+	}
+	return &pb.Task{Id: req.GetId()}, nil
+}
+
+func newTestClient(t *testing.T, srv *fakeTaskServer, opts grpcclient.Options) *grpcclient.TaskClient {
+	t.Helper()
+
+	lis := bufconn.Listen(1024 * 1024)
+	grpcServer := grpc.NewServer()
+	pb.RegisterTaskServiceServer(grpcServer, srv)
+	go func() { _ = grpcServer.Serve(lis) }()
+	t.Cleanup(grpcServer.Stop)
+
+	opts.DialOptions = append(opts.DialOptions, grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+		return lis.DialContext(ctx)
+	}))
+
+	testLogger := logger.New("api-service", "test-logs")
+	client, err := grpcclient.NewTaskClient("passthrough:///bufnet", opts, testLogger)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = client.Close() })
+
+	return client
+}
+
+func fastRetryOptions() grpcclient.Options {
+	opts := grpcclient.DefaultOptions()
+	opts.Retry.InitialBackoff = time.Millisecond
+	opts.Retry.MaxBackoff = 5 * time.Millisecond
+	return opts
+}
+
+func TestTaskClient_GetAllTasks_RetriesUnavailable(t *testing.T) {
+	srv := &fakeTaskServer{failures: 2}
+	client := newTestClient(t, srv, fastRetryOptions())
+
+	tasks, err := client.GetAllTasks(context.Background())
+
+	require.NoError(t, err)
+	assert.Len(t, tasks, 1)
+	assert.Equal(t, int32(3), srv.listCalls.Load())
+}
+
+func TestTaskClient_GetAllTasks_GivesUpAfterMaxAttempts(t *testing.T) {
+	srv := &fakeTaskServer{failures: 10}
+	client := newTestClient(t, srv, fastRetryOptions())
+
+	_, err := client.GetAllTasks(context.Background())
+
+	assert.Equal(t, codes.Unavailable, status.Code(err))
+	assert.Equal(t, int32(3), srv.listCalls.Load())
+}
+
+func TestTaskClient_CreateTask_NotRetried(t *testing.T) {
+	srv := &fakeTaskServer{failures: 1}
+	client := newTestClient(t, srv, fastRetryOptions())
+
+	_, err := client.CreateTask(context.Background(), "title", "description")
+
+	assert.Equal(t, codes.Unavailable, status.Code(err))
+	assert.Equal(t, int32(1), srv.createCalls.Load())
+}
+
+func TestTaskClient_PropagatesEventMeta(t *testing.T) {
+	srv := &fakeTaskServer{}
+	client := newTestClient(t, srv, fastRetryOptions())
+
+	ctx := kafka.ContextWithEventMeta(context.Background(), kafka.EventMeta{
+		ForwardedActor: "alice",
+		RequestID:      "req-1",
+		TraceID:        "4bf92f3577b34da6a3ce929d0e0e4736",
+	})
+	_, err := client.CreateTask(ctx, "title", "description")
+	require.NoError(t, err)
+
+	md := srv.createMD.Load()
+	require.NotNil(t, md)
+	assert.Equal(t, []string{"alice"}, md.Get(kafka.MetadataActor))
+	assert.Equal(t, []string{"req-1"}, md.Get(kafka.MetadataRequestID))
+	assert.Equal(t, []string{"4bf92f3577b34da6a3ce929d0e0e4736"}, md.Get(kafka.MetadataTraceID))
+}
+
+func TestTaskClient_PerMethodDeadline(t *testing.T) {
+	srv := &fakeTaskServer{delay: 200 * time.Millisecond}
+	opts := fastRetryOptions()
+	opts.Timeouts.Get = 20 * time.Millisecond
+	client := newTestClient(t, srv, opts)
+
+	_, err := client.GetTaskByID(context.Background(), 1)
+
+	assert.Equal(t, codes.DeadlineExceeded, status.Code(err))
+}
+
+func TestTaskClient_CircuitBreakerOpensAndFailsFast(t *testing.T) {
+	srv := &fakeTaskServer{failures: 100}
+	opts := fastRetryOptions()
+	opts.Retry.MaxAttempts = 1
+	opts.Breaker = grpcclient.BreakerSettings{FailureThreshold: 3, OpenTimeout: time.Minute}
+	client := newTestClient(t, srv, opts)
+
+	for i := 0; i < 3; i++ {
+		_, err := client.CreateTask(context.Background(), "title", "")
+		assert.Equal(t, codes.Unavailable, status.Code(err))
+	}
+	assert.Equal(t, grpcclient.BreakerOpen, client.BreakerState())
+
+	_, err := client.CreateTask(context.Background(), "title", "")
+
+	var circuitErr *grpcclient.CircuitOpenError
+	require.True(t, errors.As(err, &circuitErr))
+	assert.True(t, errors.Is(err, grpcclient.ErrCircuitOpen))
+	assert.Greater(t, circuitErr.RetryAfter, time.Duration(0))
+	// Запрос не дошел до сервера
+	assert.Equal(t, int32(3), srv.createCalls.Load())
+}
+
+func TestTaskClient_BusinessErrorsDoNotOpenBreaker(t *testing.T) {
+	srv := &fakeTaskServer{}
+	opts := fastRetryOptions()
+	opts.Breaker = grpcclient.BreakerSettings{FailureThreshold: 2, OpenTimeout: time.Minute}
+	client := newTestClient(t, srv, opts)
+
+	for i := 0; i < 5; i++ {
+		_, err := client.GetTaskByID(context.Background(), 404)
+		assert.Equal(t, codes.NotFound, status.Code(err))
+	}
+
+	assert.Equal(t, grpcclient.BreakerClosed, client.BreakerState())
+}
@@ -2,9 +2,14 @@ package config
 
 import (
 	"fmt"
+	"os"
 	"time"
 
 	"github.com/ilyakaznacheev/cleanenv"
+	"google.golang.org/grpc/keepalive"
+
+	"github.com/N0F1X3d/todo/api-service/internal/clients/grpcclient"
+	"github.com/N0F1X3d/todo/pkg/eventbus"
 )
 
 type Config struct {
@@ -21,9 +26,62 @@ type Config struct {
 	GRPCHost string `env:"GRPC_HOST" env-default:"localhost"`
 	GRPCPort int    `env:"GRPC_PORT" env-default:"50051"`
 
-	// Kafka (будущее)
-	KafkaBrokers string `env:"KAFKA_BROKERS" env-default:"localhost:9092"`
-	KafkaTopic   string `env:"KAFKA_TOPIC" env-default:"todo-events"`
+	// gRPC балансировка между репликами db-service.
+	// GRPC_TARGETS - список адресов через запятую; если пуст, используется GRPC_HOST:GRPC_PORT через DNS
+	GRPCTargets                      []string      `env:"GRPC_TARGETS" env-separator:","`
+	GRPCLBPolicy                     string        `env:"GRPC_LB_POLICY" env-default:"round_robin"`
+	GRPCHealthCheck                  bool          `env:"GRPC_HEALTH_CHECK" env-default:"true"`
+	GRPCSubsetSize                   int           `env:"GRPC_SUBSET_SIZE" env-default:"0"`
+	GRPCSubsetClientID               string        `env:"GRPC_SUBSET_CLIENT_ID"`
+	GRPCKeepaliveTime                time.Duration `env:"GRPC_KEEPALIVE_TIME" env-default:"30s"`
+	GRPCKeepaliveTimeout             time.Duration `env:"GRPC_KEEPALIVE_TIMEOUT" env-default:"10s"`
+	GRPCKeepalivePermitWithoutStream bool          `env:"GRPC_KEEPALIVE_PERMIT_WITHOUT_STREAM" env-default:"true"`
+
+	// gRPC TLS/mTLS (db-service)
+	GRPCTLSEnabled        bool          `env:"GRPC_TLS_ENABLED" env-default:"false"`
+	GRPCTLSCertFile       string        `env:"GRPC_TLS_CERT_FILE"`
+	GRPCTLSKeyFile        string        `env:"GRPC_TLS_KEY_FILE"`
+	GRPCTLSCAFile         string        `env:"GRPC_TLS_CA_FILE"`
+	GRPCTLSServerName     string        `env:"GRPC_TLS_SERVER_NAME"`
+	GRPCTLSReloadInterval time.Duration `env:"GRPC_TLS_RELOAD_INTERVAL" env-default:"30s"`
+
+	// gRPC дедлайны отдельных RPC
+	GRPCTimeoutCreate   time.Duration `env:"GRPC_TIMEOUT_CREATE" env-default:"5s"`
+	GRPCTimeoutGet      time.Duration `env:"GRPC_TIMEOUT_GET" env-default:"5s"`
+	GRPCTimeoutList     time.Duration `env:"GRPC_TIMEOUT_LIST" env-default:"5s"`
+	GRPCTimeoutComplete time.Duration `env:"GRPC_TIMEOUT_COMPLETE" env-default:"5s"`
+	GRPCTimeoutDelete   time.Duration `env:"GRPC_TIMEOUT_DELETE" env-default:"5s"`
+
+	// gRPC повторы идемпотентных RPC (GetTaskByID, GetAllTasks)
+	GRPCRetryMaxAttempts       int           `env:"GRPC_RETRY_MAX_ATTEMPTS" env-default:"3"`
+	GRPCRetryInitialBackoff    time.Duration `env:"GRPC_RETRY_INITIAL_BACKOFF" env-default:"100ms"`
+	GRPCRetryMaxBackoff        time.Duration `env:"GRPC_RETRY_MAX_BACKOFF" env-default:"1s"`
+	GRPCRetryBackoffMultiplier float64       `env:"GRPC_RETRY_BACKOFF_MULTIPLIER" env-default:"2"`
+
+	// gRPC circuit breaker
+	GRPCBreakerFailureThreshold int           `env:"GRPC_BREAKER_FAILURE_THRESHOLD" env-default:"5"`
+	GRPCBreakerOpenTimeout      time.Duration `env:"GRPC_BREAKER_OPEN_TIMEOUT" env-default:"10s"`
+
+	// Health checks (/livez, /readyz)
+	ReadinessCacheTTL  time.Duration `env:"READINESS_CACHE_TTL" env-default:"3s"`
+	ReadinessTimeout   time.Duration `env:"READINESS_TIMEOUT" env-default:"2s"`
+	ShutdownDrainDelay time.Duration `env:"SHUTDOWN_DRAIN_DELAY" env-default:"3s"`
+
+	// Шина событий (list-tasks): kafka, nats, memory или noop
+	EventBus     string   `env:"EVENT_BUS" env-default:"kafka"`
+	KafkaBrokers []string `env:"KAFKA_BROKERS" env-separator:"," env-default:"localhost:9092"`
+	KafkaTopic   string   `env:"KAFKA_TOPIC" env-default:"task-events"`
+	NATSURL      string   `env:"NATS_URL" env-default:"nats://localhost:4222"`
+	// KafkaCodec - формат событий: json или protobuf
+	KafkaCodec string `env:"KAFKA_CODEC" env-default:"json"`
+	// KafkaSchemaRegistryPath - файл schema registry; пусто - схема не регистрируется
+	KafkaSchemaRegistryPath string `env:"KAFKA_SCHEMA_REGISTRY_PATH"`
+
+	// Асинхронная публикация: очередь в памяти и спул на диске, пока брокер недоступен
+	EventBusAsync        bool          `env:"EVENT_BUS_ASYNC" env-default:"true"`
+	EventBusQueueSize    int           `env:"EVENT_BUS_QUEUE_SIZE" env-default:"1024"`
+	EventBusSpoolPath    string        `env:"EVENT_BUS_SPOOL_PATH"`
+	EventBusDrainTimeout time.Duration `env:"EVENT_BUS_DRAIN_TIMEOUT" env-default:"5s"`
 }
 
 func Load() (*Config, error) {
@@ -42,6 +100,65 @@ func (c *Config) GRPCAddress() string {
 	return fmt.Sprintf("%s:%d", c.GRPCHost, c.GRPCPort)
 }
 
+// GRPCTargetList возвращает адреса реплик db-service
+func (c *Config) GRPCTargetList() []string {
+	if len(c.GRPCTargets) > 0 {
+		return c.GRPCTargets
+	}
+	return []string{c.GRPCAddress()}
+}
+
+// SubsetClientID возвращает идентификатор клиента для subsetting (по умолчанию hostname)
+func (c *Config) SubsetClientID() string {
+	if c.GRPCSubsetClientID != "" {
+		return c.GRPCSubsetClientID
+	}
+	hostname, _ := os.Hostname()
+	return hostname
+}
+
+// GRPCClientOptions возвращает настройки клиента db-service
+func (c *Config) GRPCClientOptions() grpcclient.Options {
+	return grpcclient.Options{
+		Timeouts: grpcclient.Timeouts{
+			Create:   c.GRPCTimeoutCreate,
+			Get:      c.GRPCTimeoutGet,
+			List:     c.GRPCTimeoutList,
+			Complete: c.GRPCTimeoutComplete,
+			Delete:   c.GRPCTimeoutDelete,
+		},
+		Retry: grpcclient.RetryPolicy{
+			MaxAttempts:       c.GRPCRetryMaxAttempts,
+			InitialBackoff:    c.GRPCRetryInitialBackoff,
+			MaxBackoff:        c.GRPCRetryMaxBackoff,
+			BackoffMultiplier: c.GRPCRetryBackoffMultiplier,
+		},
+		Breaker: grpcclient.BreakerSettings{
+			FailureThreshold: c.GRPCBreakerFailureThreshold,
+			OpenTimeout:      c.GRPCBreakerOpenTimeout,
+		},
+		LoadBalancingPolicy: c.GRPCLBPolicy,
+		HealthCheck:         c.GRPCHealthCheck,
+		Keepalive: keepalive.ClientParameters{
+			Time:                c.GRPCKeepaliveTime,
+			Timeout:             c.GRPCKeepaliveTimeout,
+			PermitWithoutStream: c.GRPCKeepalivePermitWithoutStream,
+		},
+	}
+}
+
+// EventBusConfig возвращает настройки шины событий
+func (c *Config) EventBusConfig() eventbus.Config {
+	return eventbus.Config{
+		Driver:             c.EventBus,
+		Topic:              c.KafkaTopic,
+		Codec:              c.KafkaCodec,
+		KafkaBrokers:       c.KafkaBrokers,
+		SchemaRegistryPath: c.KafkaSchemaRegistryPath,
+		NATSURL:            c.NATSURL,
+	}
+}
+
 func (c *Config) IsProduction() bool {
 	return c.Environment == "production"
 }
@@ -4,7 +4,7 @@ import (
 	"errors"
 	"strings"
 
-	pb "github.com/N0F1X3d/todo/pkg/proto"
+	pb "github.com/N0F1X3d/todo/pkg/proto/v2"
 )
 
 // CreateTaskRequest - запрос на создание задачи
@@ -37,7 +37,7 @@ func (r *CreateTaskRequest) ToProto() *pb.CreateTaskRequest {
 
 // DeleteTaskRequest - запрос на удаление задачи
 type DeleteTaskRequest struct {
-	ID int32 `json:"id"`
+	ID int64 `json:"id"`
 }
 
 // Validate проверяет корректность запроса
@@ -50,7 +50,7 @@ func (r *DeleteTaskRequest) Validate() error {
 
 // CompleteTaskRequest - запрос на выполнение задачи
 type CompleteTaskRequest struct {
-	ID int32 `json:"id"`
+	ID int64 `json:"id"`
 }
 
 // Validate проверяет корректность запроса
@@ -1,12 +1,14 @@
 package dto
 
 import (
-	pb "github.com/N0F1X3d/todo/pkg/proto"
+	"github.com/N0F1X3d/todo/pkg/proto/convert"
+	pb "github.com/N0F1X3d/todo/pkg/proto/v2"
 )
 
 // TaskResponse - ответ с информацией о задаче
 type TaskResponse struct {
-	ID          int32  `json:"id"`
+	ID          int64  `json:"id"`
+	UUID        string `json:"uuid,omitempty"`
 	Title       string `json:"title"`
 	Description string `json:"description"`
 	Completed   bool   `json:"completed"`
@@ -15,18 +17,19 @@ type TaskResponse struct {
 }
 
 // TaskResponseFromProto создает DTO из protobuf сообщения
-func TaskResponseFromProto(protoTask *pb.TaskResponse) *TaskResponse {
+func TaskResponseFromProto(protoTask *pb.Task) *TaskResponse {
 	if protoTask == nil {
 		return nil
 	}
 
 	return &TaskResponse{
-		ID:          protoTask.Id,
-		Title:       protoTask.Title,
-		Description: protoTask.Description,
-		Completed:   protoTask.Completed,
-		CreatedAt:   protoTask.CreatedAt,
-		UpdatedAt:   protoTask.UpdatedAt,
+		ID:          protoTask.GetId(),
+		UUID:        protoTask.GetUuid(),
+		Title:       protoTask.GetTitle(),
+		Description: protoTask.GetDescription(),
+		Completed:   protoTask.GetCompleted(),
+		CreatedAt:   convert.FormatTimestamp(protoTask.GetCreatedAt()),
+		UpdatedAt:   convert.FormatTimestamp(protoTask.GetUpdatedAt()),
 	}
 }
 
@@ -34,7 +37,7 @@ func TaskResponseFromProto(protoTask *pb.TaskResponse) *TaskResponse {
 type TaskListResponse []*TaskResponse
 
 // TaskListResponseFromProto создает список DTO из protobuf сообщения
-func TaskListResponseFromProto(protoTasks []*pb.TaskResponse) TaskListResponse {
+func TaskListResponseFromProto(protoTasks []*pb.Task) TaskListResponse {
 	if protoTasks == nil {
 		return TaskListResponse{}
 	}
@@ -54,14 +57,14 @@ type DeleteTaskResponse struct {
 
 // CompleteTaskResponse - ответ на выполнение задачи
 type CompleteTaskResponse struct {
-	ID        int32  `json:"id"`
+	ID        int64  `json:"id"`
 	Title     string `json:"title"`
 	Completed bool   `json:"completed"`
 	Message   string `json:"message,omitempty"`
 }
 
 // CompleteTaskResponseFromProto создает DTO из protobuf сообщения
-func CompleteTaskResponseFromProto(protoTask *pb.TaskResponse) *CompleteTaskResponse {
+func CompleteTaskResponseFromProto(protoTask *pb.Task) *CompleteTaskResponse {
 	if protoTask == nil {
 		return nil
 	}
@@ -2,11 +2,15 @@ package handlers
 
 import (
 	"encoding/json"
+	"errors"
+	"math"
 	"net/http"
+	"strconv"
 	"time"
 
 	"github.com/N0F1X3d/todo/api-service/internal/clients/grpcclient"
 	"github.com/N0F1X3d/todo/api-service/internal/dto"
+	"github.com/N0F1X3d/todo/pkg/eventbus"
 	"github.com/N0F1X3d/todo/pkg/kafka"
 	"github.com/N0F1X3d/todo/pkg/logger"
 	"google.golang.org/grpc/codes"
@@ -15,21 +19,20 @@ import (
 
 type TaskHandler struct {
 	grpcClient *grpcclient.TaskClient
-	producer   *kafka.Producer
+	publisher  eventbus.Publisher
 	log        *logger.Logger
 }
 
-func NewTaskHandler(client *grpcclient.TaskClient, producer *kafka.Producer, log *logger.Logger) *TaskHandler {
+func NewTaskHandler(client *grpcclient.TaskClient, publisher eventbus.Publisher, log *logger.Logger) *TaskHandler {
 	return &TaskHandler{
 		grpcClient: client,
-		producer:   producer,
+		publisher:  publisher,
 		log:        log,
 	}
 }
 
-// POST /create
+// POST /create. Событие create-task публикует db-service через outbox.
 func (h *TaskHandler) CreateTask(w http.ResponseWriter, r *http.Request) {
-	const op = "CreateTask"
 	ctx := r.Context()
 
 	if r.Method != http.MethodPost {
@@ -48,8 +51,6 @@ func (h *TaskHandler) CreateTask(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	dbRequestTime := time.Now()
-
 	task, err := h.grpcClient.CreateTask(ctx, req.Title, req.Description)
 	if err != nil {
 		handleGrpcError(w, err)
@@ -58,14 +59,6 @@ func (h *TaskHandler) CreateTask(w http.ResponseWriter, r *http.Request) {
 
 	resp := dto.TaskResponseFromProto(task)
 
-	event := kafka.TaskEvent{
-		Action:        "create-task",
-		DBRequestTime: dbRequestTime,
-	}
-	if err := h.producer.Send(ctx, "create", event); err != nil {
-		h.log.ErrorWithContext("failed to send event", err, op)
-	}
-
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusCreated)
 	json.NewEncoder(w).Encode(resp)
@@ -85,17 +78,17 @@ func (h *TaskHandler) ListTasks(w http.ResponseWriter, r *http.Request) {
 
 	tasks, err := h.grpcClient.GetAllTasks(ctx)
 	if err != nil {
+		if writeCircuitOpen(w, err) {
+			return
+		}
 		http.Error(w, "Failed to get tasks", http.StatusInternalServerError)
 		return
 	}
 
 	resp := dto.TaskListResponseFromProto(tasks)
 
-	event := kafka.TaskEvent{
-		Action:        "list-tasks",
-		DBRequestTime: dbRequestTime,
-	}
-	if err := h.producer.Send(ctx, "list", event); err != nil {
+	event := kafka.NewTaskEvent(ctx, kafka.ActionListTasks, dbRequestTime)
+	if err := h.publisher.Publish(ctx, "list", event); err != nil {
 		h.log.ErrorWithContext("failed to send event", err, op)
 	}
 
@@ -103,9 +96,8 @@ func (h *TaskHandler) ListTasks(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(resp)
 }
 
-// DELETE /delete
+// DELETE /delete. Событие delete-task публикует db-service через outbox.
 func (h *TaskHandler) DeleteTask(w http.ResponseWriter, r *http.Request) {
-	const op = "DeleteTask"
 	ctx := r.Context()
 
 	if r.Method != http.MethodDelete {
@@ -124,31 +116,20 @@ func (h *TaskHandler) DeleteTask(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	dbRequestTime := time.Now()
-
 	err := h.grpcClient.DeleteTask(ctx, req.ID)
 	if err != nil {
 		handleGrpcError(w, err)
 		return
 	}
 
-	event := kafka.TaskEvent{
-		Action:        "delete-task",
-		DBRequestTime: dbRequestTime,
-	}
-	if err := h.producer.Send(ctx, "delete", event); err != nil {
-		h.log.ErrorWithContext("failed to send event", err, op)
-	}
-
 	w.WriteHeader(http.StatusOK)
 	json.NewEncoder(w).Encode(map[string]string{
 		"message": "Task deleted successfully",
 	})
 }
 
-// PUT /done
+// PUT /done. Событие complete-task публикует db-service через outbox.
 func (h *TaskHandler) CompleteTask(w http.ResponseWriter, r *http.Request) {
-	const op = "CompleteTask"
 	ctx := r.Context()
 
 	if r.Method != http.MethodPut {
@@ -167,8 +148,6 @@ func (h *TaskHandler) CompleteTask(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	dbRequestTime := time.Now()
-
 	task, err := h.grpcClient.CompleteTask(ctx, req.ID)
 	if err != nil {
 		handleGrpcError(w, err)
@@ -177,20 +156,16 @@ func (h *TaskHandler) CompleteTask(w http.ResponseWriter, r *http.Request) {
 
 	resp := dto.TaskResponseFromProto(task)
 
-	event := kafka.TaskEvent{
-		Action:        "complete-task",
-		DBRequestTime: dbRequestTime,
-	}
-	if err := h.producer.Send(ctx, "complete", event); err != nil {
-		h.log.ErrorWithContext("failed to send event", err, op)
-	}
-
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(resp)
 }
 
 // Общая обработка gRPC ошибок
 func handleGrpcError(w http.ResponseWriter, err error) {
+	if writeCircuitOpen(w, err) {
+		return
+	}
+
 	if st, ok := status.FromError(err); ok {
 		switch st.Code() {
 		case codes.InvalidArgument:
@@ -199,6 +174,8 @@ func handleGrpcError(w http.ResponseWriter, err error) {
 			http.Error(w, st.Message(), http.StatusNotFound)
 		case codes.FailedPrecondition:
 			http.Error(w, st.Message(), http.StatusConflict)
+		case codes.Unavailable:
+			http.Error(w, "Service temporarily unavailable", http.StatusServiceUnavailable)
 		default:
 			http.Error(w, "Internal server error", http.StatusInternalServerError)
 		}
@@ -207,3 +184,21 @@ func handleGrpcError(w http.ResponseWriter, err error) {
 
 	http.Error(w, "Internal server error", http.StatusInternalServerError)
 }
+
+// writeCircuitOpen отвечает 503 с Retry-After, если запрос отклонен circuit breaker.
+// Возвращает true, если ответ записан.
+func writeCircuitOpen(w http.ResponseWriter, err error) bool {
+	var circuitErr *grpcclient.CircuitOpenError
+	if !errors.As(err, &circuitErr) {
+		return false
+	}
+
+	retryAfter := int(math.Ceil(circuitErr.RetryAfter.Seconds()))
+	if retryAfter < 1 {
+		retryAfter = 1
+	}
+
+	w.Header().Set("Retry-After", strconv.Itoa(retryAfter))
+	http.Error(w, "Service temporarily unavailable", http.StatusServiceUnavailable)
+	return true
+}
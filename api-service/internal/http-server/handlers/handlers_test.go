@@ -0,0 +1,133 @@
+package handlers_test
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/N0F1X3d/todo/api-service/internal/clients/grpcclient"
+	"github.com/N0F1X3d/todo/api-service/internal/http-server/handlers"
+	"github.com/N0F1X3d/todo/pkg/eventbus"
+	"github.com/N0F1X3d/todo/pkg/kafka"
+	"github.com/N0F1X3d/todo/pkg/logger"
+	pb "github.com/N0F1X3d/todo/pkg/proto/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+type fakeTaskServer struct {
+	pb.UnimplementedTaskServiceServer
+}
+
+func (s *fakeTaskServer) GetAllTasks(ctx context.Context, req *pb.GetAllTasksRequest) (*pb.GetAllTasksResponse, error) {
+	return &pb.GetAllTasksResponse{Tasks: []*pb.Task{{Id: 1, Title: "test"}}}, nil
+}
+
+// newTestClient создает клиент к fakeTaskServer через bufconn
+func newTestClient(t *testing.T) *grpcclient.TaskClient {
+	t.Helper()
+
+	lis := bufconn.Listen(1024 * 1024)
+	grpcServer := grpc.NewServer()
+	pb.RegisterTaskServiceServer(grpcServer, &fakeTaskServer{})
+	go func() { _ = grpcServer.Serve(lis) }()
+	t.Cleanup(grpcServer.Stop)
+
+	opts := grpcclient.DefaultOptions()
+	opts.DialOptions = append(opts.DialOptions, grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+		return lis.DialContext(ctx)
+	}))
+
+	testLogger := logger.New("api-service", "test-logs")
+	client, err := grpcclient.NewTaskClient("passthrough:///bufnet", opts, testLogger)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = client.Close() })
+
+	return client
+}
+
+// newUnavailableClient создает клиент к адресу, где никто не слушает,
+// и открывает его circuit breaker одной неудачной попыткой
+func newUnavailableClient(t *testing.T) *grpcclient.TaskClient {
+	t.Helper()
+
+	opts := grpcclient.DefaultOptions()
+	opts.Retry.MaxAttempts = 1
+	opts.Timeouts.List = time.Second
+	opts.Breaker = grpcclient.BreakerSettings{FailureThreshold: 1, OpenTimeout: 30 * time.Second}
+
+	testLogger := logger.New("api-service", "test-logs")
+	client, err := grpcclient.NewTaskClient("passthrough:///127.0.0.1:1", opts, testLogger)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = client.Close() })
+
+	_, err = client.GetAllTasks(context.Background())
+	require.Error(t, err)
+	require.Equal(t, grpcclient.BreakerOpen, client.BreakerState())
+
+	return client
+}
+
+func TestTaskHandler_ListTasks_PublishesEvent(t *testing.T) {
+	testLogger := logger.New("api-service", "test-logs")
+	bus := eventbus.NewMemory()
+	h := handlers.NewTaskHandler(newTestClient(t), bus, testLogger)
+
+	ctx := kafka.ContextWithEventMeta(context.Background(), kafka.EventMeta{
+		RequestID:      "req-1",
+		ForwardedActor: "alice",
+	})
+	rec := httptest.NewRecorder()
+	h.ListTasks(rec, httptest.NewRequest(http.MethodGet, "/list", nil).WithContext(ctx))
+
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	published := bus.Published()
+	require.Len(t, published, 1)
+	assert.Equal(t, "list", published[0].Key)
+
+	event := published[0].Event
+	assert.Equal(t, kafka.ActionListTasks, event.Action)
+	assert.Equal(t, kafka.SchemaVersion, event.SchemaVersion)
+	assert.NotEmpty(t, event.EventID)
+	assert.Equal(t, "req-1", event.RequestID)
+	assert.Equal(t, "alice", event.ForwardedActor)
+	assert.Empty(t, event.Actor)
+	assert.False(t, event.DBRequestTime.IsZero())
+}
+
+func TestTaskHandler_ListTasks_CircuitOpen(t *testing.T) {
+	testLogger := logger.New("api-service", "test-logs")
+	bus := eventbus.NewMemory()
+	h := handlers.NewTaskHandler(newUnavailableClient(t), bus, testLogger)
+
+	rec := httptest.NewRecorder()
+	h.ListTasks(rec, httptest.NewRequest(http.MethodGet, "/list", nil))
+
+	assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+
+	retryAfter, err := strconv.Atoi(rec.Header().Get("Retry-After"))
+	require.NoError(t, err)
+	assert.Greater(t, retryAfter, 0)
+	assert.LessOrEqual(t, retryAfter, 30)
+	assert.Empty(t, bus.Published())
+}
+
+func TestTaskHandler_CompleteTask_CircuitOpen(t *testing.T) {
+	testLogger := logger.New("api-service", "test-logs")
+	h := handlers.NewTaskHandler(newUnavailableClient(t), eventbus.Noop{}, testLogger)
+
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPut, "/done", strings.NewReader(`{"id": 1}`))
+	h.CompleteTask(rec, req)
+
+	assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+	assert.NotEmpty(t, rec.Header().Get("Retry-After"))
+}
@@ -0,0 +1,153 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/N0F1X3d/todo/pkg/logger"
+)
+
+// CheckFunc проверяет доступность одной зависимости (db-service, Kafka и т.д.)
+type CheckFunc func(ctx context.Context) error
+
+// DependencyStatus - результат проверки одной зависимости
+type DependencyStatus struct {
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+}
+
+// ReadinessResponse - ответ /readyz с разбивкой по зависимостям
+type ReadinessResponse struct {
+	Status    string                      `json:"status"`
+	Service   string                      `json:"service"`
+	Checks    map[string]DependencyStatus `json:"checks,omitempty"`
+	CheckedAt time.Time                   `json:"checked_at"`
+}
+
+type namedCheck struct {
+	name  string
+	check CheckFunc
+}
+
+// HealthHandler обслуживает /livez и /readyz.
+// Результаты проверок зависимостей кешируются на cacheTTL,
+// чтобы частые пробы балансировщика не нагружали зависимости.
+type HealthHandler struct {
+	serviceName string
+	cacheTTL    time.Duration
+	timeout     time.Duration
+	log         *logger.Logger
+
+	checks       []namedCheck
+	shuttingDown atomic.Bool
+
+	mu     sync.Mutex
+	cached *ReadinessResponse
+}
+
+// NewHealthHandler создает HealthHandler
+func NewHealthHandler(serviceName string, cacheTTL, timeout time.Duration, log *logger.Logger) *HealthHandler {
+	return &HealthHandler{
+		serviceName: serviceName,
+		cacheTTL:    cacheTTL,
+		timeout:     timeout,
+		log:         log.WithComponent("health").WithFunction("HealthHandler"),
+	}
+}
+
+// AddCheck регистрирует проверку зависимости. Вызывается до запуска сервера.
+func (h *HealthHandler) AddCheck(name string, check CheckFunc) {
+	h.checks = append(h.checks, namedCheck{name: name, check: check})
+}
+
+// SetShuttingDown переводит сервис в состояние "не готов",
+// чтобы балансировщик успел вывести его из ротации до остановки
+func (h *HealthHandler) SetShuttingDown() {
+	h.shuttingDown.Store(true)
+	h.log.Info("readiness set to false: shutting down")
+}
+
+// GET /livez
+func (h *HealthHandler) Livez(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]string{
+		"status":  "alive",
+		"service": h.serviceName,
+	})
+}
+
+// GET /readyz
+func (h *HealthHandler) Readyz(w http.ResponseWriter, r *http.Request) {
+	var resp *ReadinessResponse
+	if h.shuttingDown.Load() {
+		resp = &ReadinessResponse{
+			Status:    "shutting_down",
+			Service:   h.serviceName,
+			CheckedAt: time.Now(),
+		}
+	} else {
+		resp = h.readiness()
+	}
+
+	code := http.StatusOK
+	if resp.Status != "ready" {
+		code = http.StatusServiceUnavailable
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	json.NewEncoder(w).Encode(resp)
+}
+
+// readiness возвращает закешированный результат или выполняет проверки заново.
+// Проверки не зависят от контекста запроса: результат кешируется для всех,
+// и отключившийся клиент не должен оставить в кеше "not_ready".
+func (h *HealthHandler) readiness() *ReadinessResponse {
+	const op = "readiness"
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.cached != nil && time.Since(h.cached.CheckedAt) < h.cacheTTL {
+		return h.cached
+	}
+
+	type result struct {
+		name string
+		err  error
+	}
+
+	results := make(chan result, len(h.checks))
+	for _, nc := range h.checks {
+		go func(nc namedCheck) {
+			checkCtx, cancel := context.WithTimeout(context.Background(), h.timeout)
+			defer cancel()
+			results <- result{name: nc.name, err: nc.check(checkCtx)}
+		}(nc)
+	}
+
+	resp := &ReadinessResponse{
+		Status:  "ready",
+		Service: h.serviceName,
+		Checks:  make(map[string]DependencyStatus, len(h.checks)),
+	}
+	for range h.checks {
+		res := <-results
+		if res.err != nil {
+			h.log.ErrorWithContext("dependency not ready", res.err, op, "dependency", res.name)
+			resp.Status = "not_ready"
+			resp.Checks[res.name] = DependencyStatus{Status: "error", Error: res.err.Error()}
+			continue
+		}
+		resp.Checks[res.name] = DependencyStatus{Status: "ok"}
+	}
+	resp.CheckedAt = time.Now()
+
+	h.cached = resp
+	return resp
+}
@@ -0,0 +1,140 @@
+package handlers_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/N0F1X3d/todo/api-service/internal/http-server/handlers"
+	"github.com/N0F1X3d/todo/pkg/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func doReadyz(t *testing.T, h *handlers.HealthHandler) (int, handlers.ReadinessResponse) {
+	rec := httptest.NewRecorder()
+	h.Readyz(rec, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+
+	var resp handlers.ReadinessResponse
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&resp))
+	return rec.Code, resp
+}
+
+func TestHealthHandler_Livez(t *testing.T) {
+	testLogger := logger.New("api-service", "test-logs")
+	h := handlers.NewHealthHandler("todo-api-service", time.Second, time.Second, testLogger)
+	h.AddCheck("db-service", func(ctx context.Context) error { return errors.New("down") })
+
+	rec := httptest.NewRecorder()
+	h.Livez(rec, httptest.NewRequest(http.MethodGet, "/livez", nil))
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+}
+
+func TestHealthHandler_Readyz_AllHealthy(t *testing.T) {
+	testLogger := logger.New("api-service", "test-logs")
+	h := handlers.NewHealthHandler("todo-api-service", time.Second, time.Second, testLogger)
+	h.AddCheck("db-service", func(ctx context.Context) error { return nil })
+	h.AddCheck("kafka", func(ctx context.Context) error { return nil })
+
+	code, resp := doReadyz(t, h)
+
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, "ready", resp.Status)
+	assert.Equal(t, "ok", resp.Checks["db-service"].Status)
+	assert.Equal(t, "ok", resp.Checks["kafka"].Status)
+}
+
+func TestHealthHandler_Readyz_DependencyDown(t *testing.T) {
+	testLogger := logger.New("api-service", "test-logs")
+	h := handlers.NewHealthHandler("todo-api-service", time.Second, time.Second, testLogger)
+	h.AddCheck("db-service", func(ctx context.Context) error { return nil })
+	h.AddCheck("kafka", func(ctx context.Context) error { return errors.New("connection refused") })
+
+	code, resp := doReadyz(t, h)
+
+	assert.Equal(t, http.StatusServiceUnavailable, code)
+	assert.Equal(t, "not_ready", resp.Status)
+	assert.Equal(t, "ok", resp.Checks["db-service"].Status)
+	assert.Equal(t, "error", resp.Checks["kafka"].Status)
+	assert.Equal(t, "connection refused", resp.Checks["kafka"].Error)
+}
+
+func TestHealthHandler_Readyz_CachesResults(t *testing.T) {
+	testLogger := logger.New("api-service", "test-logs")
+	h := handlers.NewHealthHandler("todo-api-service", time.Hour, time.Second, testLogger)
+
+	var calls atomic.Int32
+	h.AddCheck("db-service", func(ctx context.Context) error {
+		calls.Add(1)
+		return nil
+	})
+
+	for i := 0; i < 5; i++ {
+		code, _ := doReadyz(t, h)
+		assert.Equal(t, http.StatusOK, code)
+	}
+
+	assert.Equal(t, int32(1), calls.Load())
+}
+
+func TestHealthHandler_Readyz_ClientDisconnectDoesNotCacheFailure(t *testing.T) {
+	testLogger := logger.New("api-service", "test-logs")
+	h := handlers.NewHealthHandler("todo-api-service", time.Hour, time.Second, testLogger)
+	h.AddCheck("db-service", func(ctx context.Context) error {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(10 * time.Millisecond):
+			return nil
+		}
+	})
+
+	// Клиент отключился до конца проверки
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	h.Readyz(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/readyz", nil).WithContext(ctx))
+
+	code, resp := doReadyz(t, h)
+
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, "ready", resp.Status)
+}
+
+func TestHealthHandler_Readyz_CacheExpires(t *testing.T) {
+	testLogger := logger.New("api-service", "test-logs")
+	h := handlers.NewHealthHandler("todo-api-service", 10*time.Millisecond, time.Second, testLogger)
+
+	var calls atomic.Int32
+	h.AddCheck("db-service", func(ctx context.Context) error {
+		calls.Add(1)
+		return nil
+	})
+
+	doReadyz(t, h)
+	time.Sleep(20 * time.Millisecond)
+	doReadyz(t, h)
+
+	assert.Equal(t, int32(2), calls.Load())
+}
+
+func TestHealthHandler_Readyz_ShuttingDown(t *testing.T) {
+	testLogger := logger.New("api-service", "test-logs")
+	h := handlers.NewHealthHandler("todo-api-service", time.Hour, time.Second, testLogger)
+	h.AddCheck("db-service", func(ctx context.Context) error { return nil })
+
+	code, _ := doReadyz(t, h)
+	assert.Equal(t, http.StatusOK, code)
+
+	// Закешированный успешный результат не должен скрывать начало остановки
+	h.SetShuttingDown()
+
+	code, resp := doReadyz(t, h)
+	assert.Equal(t, http.StatusServiceUnavailable, code)
+	assert.Equal(t, "shutting_down", resp.Status)
+}
@@ -2,11 +2,23 @@ package middleware
 
 import (
 	"net/http"
+	"strings"
 	"time"
 
+	"github.com/N0F1X3d/todo/pkg/kafka"
 	"github.com/N0F1X3d/todo/pkg/logger"
 )
 
+// Заголовки с метаданными запроса
+const (
+	HeaderRequestID   = "X-Request-ID"
+	HeaderActor       = "X-Actor"
+	HeaderTraceParent = "traceparent"
+)
+
+// maxRequestIDLength ограничивает X-Request-ID от клиента: длиннее - генерируем свой
+const maxRequestIDLength = 128
+
 // Chain объединяет несколько middleware
 func Chain(handler http.Handler, middlewares ...func(http.Handler) http.Handler) http.Handler {
 	for i := len(middlewares) - 1; i >= 0; i-- {
@@ -39,19 +51,63 @@ func LoggingMiddleware(next http.Handler, log *logger.Logger) http.Handler {
 			"method", r.Method,
 			"path", r.URL.Path,
 			"remote_addr", r.RemoteAddr,
+			"request_id", kafka.EventMetaFromContext(r.Context()).RequestID,
 			"status", rw.statusCode,
 			"duration_ms", time.Since(start).Milliseconds(),
 		)
 	})
 }
 
+// RequestIDMiddleware сохраняет в ctx метаданные запроса для событий задач:
+// X-Request-ID (генерируется, если не передан, и возвращается в ответе),
+// trace ID из W3C traceparent и пользователя из X-Actor (ForwardedActor: заголовок не проверяется)
+func RequestIDMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requestID := r.Header.Get(HeaderRequestID)
+		if requestID == "" || len(requestID) > maxRequestIDLength {
+			requestID = kafka.NewEventID()
+		}
+		w.Header().Set(HeaderRequestID, requestID)
+
+		ctx := kafka.ContextWithEventMeta(r.Context(), kafka.EventMeta{
+			ForwardedActor: r.Header.Get(HeaderActor),
+			RequestID:      requestID,
+			TraceID:        traceIDFromParent(r.Header.Get(HeaderTraceParent)),
+		})
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// traceIDFromParent достает trace-id из заголовка traceparent
+// (version-traceid-parentid-flags). Для некорректного заголовка возвращает "".
+func traceIDFromParent(header string) string {
+	parts := strings.Split(strings.TrimSpace(header), "-")
+	if len(parts) < 4 || len(parts[1]) != 32 || !isLowerHex(parts[1]) {
+		return ""
+	}
+	if parts[1] == strings.Repeat("0", 32) {
+		return ""
+	}
+	return parts[1]
+}
+
+func isLowerHex(s string) bool {
+	for _, c := range s {
+		if (c < '0' || c > '9') && (c < 'a' || c > 'f') {
+			return false
+		}
+	}
+	return true
+}
+
 // CORSMiddleware настраивает CORS заголовки
 func CORSMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		// Set CORS headers
 		w.Header().Set("Access-Control-Allow-Origin", "*")
 		w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
-		w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
+		w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization, X-Request-ID, X-Actor, traceparent")
+		w.Header().Set("Access-Control-Expose-Headers", "X-Request-ID")
 
 		// Handle preflight requests
 		if r.Method == "OPTIONS" {
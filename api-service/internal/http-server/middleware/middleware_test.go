@@ -0,0 +1,49 @@
+package middleware_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/N0F1X3d/todo/api-service/internal/http-server/middleware"
+	"github.com/N0F1X3d/todo/pkg/kafka"
+	"github.com/stretchr/testify/assert"
+)
+
+func serveWithMeta(req *http.Request) (kafka.EventMeta, *httptest.ResponseRecorder) {
+	var meta kafka.EventMeta
+	handler := middleware.RequestIDMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		meta = kafka.EventMetaFromContext(r.Context())
+	}))
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	return meta, rec
+}
+
+func TestRequestIDMiddleware_PropagatesHeaders(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/list", nil)
+	req.Header.Set(middleware.HeaderRequestID, "req-1")
+	req.Header.Set(middleware.HeaderActor, "alice")
+	req.Header.Set(middleware.HeaderTraceParent, "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+
+	meta, rec := serveWithMeta(req)
+
+	assert.Equal(t, "req-1", meta.RequestID)
+	assert.Equal(t, "alice", meta.ForwardedActor)
+	assert.Empty(t, meta.Actor)
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", meta.TraceID)
+	assert.Equal(t, "req-1", rec.Header().Get(middleware.HeaderRequestID))
+}
+
+func TestRequestIDMiddleware_GeneratesRequestID(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/list", nil)
+	req.Header.Set(middleware.HeaderTraceParent, "not-a-traceparent")
+
+	meta, rec := serveWithMeta(req)
+
+	assert.Len(t, meta.RequestID, 36)
+	assert.Equal(t, meta.RequestID, rec.Header().Get(middleware.HeaderRequestID))
+	assert.Empty(t, meta.TraceID)
+	assert.Empty(t, meta.ForwardedActor)
+}
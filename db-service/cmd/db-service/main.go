@@ -2,29 +2,45 @@ package main
 
 import (
 	"context"
-	"database/sql"
+	"errors"
 	"log"
 	"net"
+	"net/http"
 	"os"
 	"os/signal"
 	"syscall"
 	"time"
 
 	"github.com/golang-migrate/migrate/v4"
-	migratepg "github.com/golang-migrate/migrate/v4/database/postgres"
+	migratepgx "github.com/golang-migrate/migrate/v4/database/pgx/v5"
 	_ "github.com/golang-migrate/migrate/v4/source/file"
-	_ "github.com/lib/pq"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/jackc/pgx/v5/stdlib"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	grpchealth "google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/keepalive"
+	"google.golang.org/grpc/reflection"
 
 	"github.com/redis/go-redis/v9"
 
+	"github.com/N0F1X3d/todo/db-service/internal/cache"
 	appconfig "github.com/N0F1X3d/todo/db-service/internal/config"
-	"github.com/N0F1X3d/todo/db-service/internal/repository"
+	"github.com/N0F1X3d/todo/db-service/internal/dbmetrics"
+	"github.com/N0F1X3d/todo/db-service/internal/health"
+	"github.com/N0F1X3d/todo/db-service/internal/outbox"
 	"github.com/N0F1X3d/todo/db-service/internal/server"
 	"github.com/N0F1X3d/todo/db-service/internal/service"
+	"github.com/N0F1X3d/todo/pkg/kafka"
 	"github.com/N0F1X3d/todo/pkg/logger"
+	"github.com/N0F1X3d/todo/pkg/schemaregistry"
+	"github.com/N0F1X3d/todo/pkg/tlsconfig"
 
 	pb "github.com/N0F1X3d/todo/pkg/proto"
+	pbv2 "github.com/N0F1X3d/todo/pkg/proto/v2"
 )
 
 func main() {
@@ -41,30 +57,12 @@ func main() {
 	}
 
 	grpcAddr := cfg.GRPC.Address()
-	dbDSN := cfg.DB.DSNWithTimeout()
 
 	// ========================
 	// Logger
 	// ========================
 	logg := logger.New(cfg.App.Name, "main-logs").WithComponent("main")
 
-	// ========================
-	// Database
-	// ========================
-	db, err := sql.Open("postgres", dbDSN)
-	if err != nil {
-		log.Fatalf("failed to open db: %v", err)
-	}
-
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-	defer cancel()
-
-	if err := db.PingContext(ctx); err != nil {
-		log.Fatalf("failed to ping db: %v", err)
-	}
-
-	logg.Info("connected to postgres")
-
 	// ========================
 	// Redis (кеш задач)
 	// ========================
@@ -91,34 +89,135 @@ func main() {
 		}
 	}
 
+	// LRU в памяти процесса перед Redis; без Redis кеш выключен
+	taskCache := cache.NewTaskCache(redisClient, cfg.Redis.TTL, logg).
+		WithLocal(cfg.Redis.LocalCacheSize, cfg.Redis.LocalCacheTTL).
+		WithInvalidationChannel(cfg.Redis.InvalidationChannel).
+		WithNegativeTTL(cfg.Redis.NegativeTTL).
+		WithStaleWhileRevalidate(cfg.Redis.StaleWhileRevalidate).
+		WithMetrics(cache.NewMetrics(prometheus.DefaultRegisterer))
+
 	// ========================
-	// Migrations
+	// Storage (postgres / sqlite / memory)
 	// ========================
-	runMigrations(db)
+	store, err := openStorage(cfg, taskCache, logg)
+	if err != nil {
+		log.Fatalf("failed to open storage: %v", err)
+	}
 
 	defer func() {
-		if err := db.Close(); err != nil {
-			logg.Error("failed to close db", err)
+		if err := store.close(); err != nil {
+			logg.Error("failed to close storage", "error", err)
 		}
 	}()
 
 	// ========================
-	// Repository
+	// Service
 	// ========================
-	taskRepo := repository.NewTaskRepository(db, logg, redisClient, cfg.Redis.TTL)
+	taskService := service.NewTaskService(store.repo, logg)
 
 	// ========================
-	// Service
+	// gRPC Server
 	// ========================
-	taskService := service.NewTaskService(taskRepo, logg)
+	keepaliveParams := keepalive.ServerParameters{}
+	if cfg.GRPC.MaxConnectionAge > 0 {
+		keepaliveParams.MaxConnectionAge = cfg.GRPC.MaxConnectionAge
+		keepaliveParams.MaxConnectionAgeGrace = cfg.GRPC.MaxConnectionAgeGrace
+	}
+
+	serverOpts := []grpc.ServerOption{
+		grpc.ChainUnaryInterceptor(
+			server.PeerIdentityInterceptor(logg),
+			server.EventMetaInterceptor(),
+		),
+		grpc.KeepaliveEnforcementPolicy(keepalive.EnforcementPolicy{
+			MinTime:             cfg.GRPC.KeepaliveMinTime,
+			PermitWithoutStream: true,
+		}),
+		grpc.KeepaliveParams(keepaliveParams),
+	}
+
+	var tlsReloader *tlsconfig.Reloader
+	if cfg.GRPC.TLS.Enabled {
+		caFile := ""
+		if cfg.GRPC.TLS.RequireClientCert {
+			caFile = cfg.GRPC.TLS.CAFile
+		}
+
+		tlsReloader, err = tlsconfig.NewReloader(cfg.GRPC.TLS.CertFile, cfg.GRPC.TLS.KeyFile, caFile)
+		if err != nil {
+			log.Fatalf("failed to load tls certificates: %v", err)
+		}
+
+		serverOpts = append(serverOpts, grpc.Creds(credentials.NewTLS(
+			tlsconfig.ServerConfig(tlsReloader, cfg.GRPC.TLS.RequireClientCert),
+		)))
+		logg.Info("gRPC TLS enabled", "mtls", cfg.GRPC.TLS.RequireClientCert)
+	}
+
+	grpcServer := grpc.NewServer(serverOpts...)
+
+	// v2 - основной контракт, v1 обслуживается для совместимости со старыми клиентами
+	pbv2.RegisterTaskServiceServer(grpcServer, server.NewTaskServerV2(taskService, logg))
+	pb.RegisterTaskServiceServer(grpcServer, server.NewTaskServer(taskService, logg))
 
 	// ========================
-	// gRPC Server
+	// Health checking (grpc.health.v1)
 	// ========================
-	grpcServer := grpc.NewServer()
-	taskServer := server.NewTaskServer(taskService, logg)
+	healthServer := grpchealth.NewServer()
+	healthpb.RegisterHealthServer(grpcServer, healthServer)
+
+	// Пока зависимости не проверены, сервер не готов принимать запросы
+	healthServices := []string{
+		"",
+		pbv2.TaskService_ServiceDesc.ServiceName,
+		pb.TaskService_ServiceDesc.ServiceName,
+	}
+	for _, service := range healthServices {
+		healthServer.SetServingStatus(service, healthpb.HealthCheckResponse_NOT_SERVING)
+	}
 
-	pb.RegisterTaskServiceServer(grpcServer, taskServer)
+	healthChecker := health.NewChecker(
+		healthServer,
+		healthServices,
+		cfg.Health.Interval,
+		cfg.Health.Timeout,
+		logg,
+	)
+	if store.check != nil {
+		healthChecker.AddCheck(cfg.Storage.Driver, store.check)
+	}
+	if cfg.Health.CheckRedis && redisClient != nil {
+		healthChecker.AddCheck("redis", func(ctx context.Context) error {
+			return redisClient.Ping(ctx).Err()
+		})
+	}
+
+	// ========================
+	// Metrics (Prometheus)
+	// ========================
+	var metricsServer *http.Server
+	if cfg.Metrics.Enabled {
+		if store.pool != nil {
+			prometheus.MustRegister(dbmetrics.NewPoolCollector(store.pool))
+		}
+
+		mux := http.NewServeMux()
+		mux.Handle("/metrics", promhttp.Handler())
+		metricsServer = &http.Server{
+			Addr:              cfg.Metrics.Address(),
+			Handler:           mux,
+			ReadHeaderTimeout: 5 * time.Second,
+		}
+	}
+
+	// ========================
+	// Reflection (для grpcurl в dev)
+	// ========================
+	if cfg.GRPC.Reflection {
+		reflection.Register(grpcServer)
+		logg.Info("gRPC reflection enabled")
+	}
 
 	lis, err := net.Listen("tcp", grpcAddr)
 	if err != nil {
@@ -135,6 +234,57 @@ func main() {
 	)
 	defer stop()
 
+	// Фоновые задачи: проверки зависимостей и перечитывание TLS сертификатов
+	bgCtx, bgCancel := context.WithCancel(context.Background())
+	defer bgCancel()
+	go healthChecker.Run(bgCtx)
+	go taskCache.Run(bgCtx)
+
+	if store.pool != nil && cfg.DB.StatsInterval > 0 {
+		go dbmetrics.LogStats(bgCtx, store.pool, cfg.DB.StatsInterval, logg)
+	}
+	if store.router != nil {
+		go store.router.Run(bgCtx, cfg.DB.ReplicaCheckInterval, cfg.Health.Timeout)
+	}
+
+	// Outbox есть у каждого хранилища: события задач пишутся вместе с изменением задачи
+	if cfg.Outbox.Enabled {
+		codec, _ := kafka.CodecByName(cfg.Kafka.Codec) // проверен в Validate
+		producer := kafka.NewProducer(cfg.Kafka.Brokers, cfg.Kafka.Topic, kafka.WithCodec(codec))
+		defer producer.Close()
+
+		if cfg.Kafka.SchemaRegistryPath != "" {
+			registry := schemaregistry.NewFileRegistry(cfg.Kafka.SchemaRegistryPath)
+			if err := producer.RegisterSchema(bgCtx, registry); err != nil {
+				log.Fatalf("failed to register event schema: %v", err)
+			}
+		}
+
+		relay := outbox.NewRelay(
+			store.outbox,
+			producer,
+			cfg.Outbox.BatchSize,
+			cfg.Outbox.PollInterval,
+			cfg.Outbox.MaxBackoff,
+			cfg.Outbox.MaxAttempts,
+			logg,
+		)
+		go relay.Run(bgCtx)
+	}
+
+	if tlsReloader != nil {
+		go tlsReloader.Watch(bgCtx, cfg.GRPC.TLS.ReloadInterval, logg)
+	}
+
+	if metricsServer != nil {
+		go func() {
+			logg.Info("metrics server started", "addr", metricsServer.Addr)
+			if err := metricsServer.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+				logg.Error("metrics server error", "error", err)
+			}
+		}()
+	}
+
 	go func() {
 		logg.Info("gRPC server started", "addr", grpcAddr)
 		if err := grpcServer.Serve(lis); err != nil {
@@ -145,7 +295,20 @@ func main() {
 	<-shutdownCtx.Done()
 	logg.Info("shutdown signal received")
 
+	// Сначала сообщаем клиентам, что сервер больше не принимает запросы,
+	// затем дожидаемся завершения текущих
+	bgCancel()
+	healthChecker.Shutdown()
+
 	grpcServer.GracefulStop()
+
+	if metricsServer != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := metricsServer.Shutdown(ctx); err != nil {
+			logg.Error("failed to stop metrics server", "error", err)
+		}
+	}
 	logg.Info("server stopped gracefully")
 }
 
@@ -158,17 +321,21 @@ func getEnv(key, defaultVal string) string {
 	return defaultVal
 }
 
-// runMigrations применяет миграции через уже открытое соединение *sql.DB.
+// runMigrations применяет миграции через соединение из пула pgx.
 // Это решает проблему "no scheme", потому что migrate.New(...) ожидает URL вида postgres://...
-func runMigrations(db *sql.DB) {
-	driver, err := migratepg.WithInstance(db, &migratepg.Config{})
+func runMigrations(pool *pgxpool.Pool) {
+	// Закрытие *sql.DB не закрывает сам пул
+	db := stdlib.OpenDBFromPool(pool)
+	defer db.Close()
+
+	driver, err := migratepgx.WithInstance(db, &migratepgx.Config{})
 	if err != nil {
 		log.Fatalf("migration driver init error: %v", err)
 	}
 
 	m, err := migrate.NewWithDatabaseInstance(
 		"file:///app/migrations",
-		"postgres",
+		"pgx5",
 		driver,
 	)
 	if err != nil {
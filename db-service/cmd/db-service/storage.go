@@ -0,0 +1,146 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/N0F1X3d/todo/db-service/internal/cache"
+	appconfig "github.com/N0F1X3d/todo/db-service/internal/config"
+	"github.com/N0F1X3d/todo/db-service/internal/dbrouter"
+	"github.com/N0F1X3d/todo/db-service/internal/health"
+	"github.com/N0F1X3d/todo/db-service/internal/outbox"
+	"github.com/N0F1X3d/todo/db-service/internal/repository"
+	"github.com/N0F1X3d/todo/db-service/internal/repository/memory"
+	"github.com/N0F1X3d/todo/db-service/internal/repository/sqlite"
+	"github.com/N0F1X3d/todo/pkg/logger"
+)
+
+// storage - хранилище задач, выбранное через storage.driver
+type storage struct {
+	repo repository.TaskRepositoryInterface
+	// pool - пул соединений PostgreSQL для метрик (nil для остальных драйверов)
+	pool *pgxpool.Pool
+	// router - read-реплики PostgreSQL (nil, если не настроены)
+	router *dbrouter.Router
+	// outbox - события задач, которые пишет repo, для outbox.Relay
+	outbox outbox.Store
+	// check - проверка доступности для grpc.health.v1 (nil - не требуется)
+	check health.CheckFunc
+	close func() error
+}
+
+// openStorage открывает хранилище задач. Кеш задач используется только с PostgreSQL.
+func openStorage(cfg *appconfig.Config, taskCache *cache.TaskCache, logg *logger.Logger) (*storage, error) {
+	switch cfg.Storage.Driver {
+	case appconfig.StorageDriverPostgres:
+		poolCfg, err := cfg.DB.PoolConfig()
+		if err != nil {
+			return nil, err
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+
+		pool, err := pgxpool.NewWithConfig(ctx, poolCfg)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open db pool: %w", err)
+		}
+
+		if err := pool.Ping(ctx); err != nil {
+			pool.Close()
+			return nil, fmt.Errorf("failed to ping db: %w", err)
+		}
+		logg.Info("connected to postgres",
+			"max_conns", poolCfg.MaxConns,
+			"min_conns", poolCfg.MinConns,
+			"query_exec_mode", cfg.DB.QueryExecMode,
+		)
+
+		runMigrations(pool)
+
+		router, err := openReplicas(ctx, cfg, logg)
+		if err != nil {
+			pool.Close()
+			return nil, err
+		}
+
+		return &storage{
+			repo:   repository.NewTaskRepository(pool, router, taskCache, logg),
+			pool:   pool,
+			router: router,
+			outbox: outbox.NewPostgresStore(pool),
+			check:  pool.Ping,
+			close: func() error {
+				if router != nil {
+					router.Close()
+				}
+				pool.Close()
+				return nil
+			},
+		}, nil
+
+	case appconfig.StorageDriverSQLite:
+		db, err := sqlite.Open(cfg.Storage.SQLitePath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open sqlite: %w", err)
+		}
+		logg.Info("using sqlite storage", "path", cfg.Storage.SQLitePath)
+
+		return &storage{
+			repo:   sqlite.NewTaskRepository(db, logg),
+			outbox: outbox.NewSQLiteStore(db),
+			check:  db.PingContext,
+			close:  db.Close,
+		}, nil
+
+	case appconfig.StorageDriverMemory:
+		logg.Warn("using in-memory storage, tasks and unsent events are lost on restart")
+
+		events := outbox.NewMemoryStore()
+		return &storage{
+			repo:   memory.NewTaskRepository(logg).WithOutbox(events),
+			outbox: events,
+			close:  func() error { return nil },
+		}, nil
+
+	default:
+		return nil, fmt.Errorf("unknown storage driver %q", cfg.Storage.Driver)
+	}
+}
+
+// openReplicas открывает пулы read-реплик. Недоступная при старте реплика не мешает
+// запуску: роутер читает с primary, пока проверка не покажет, что реплика поднялась.
+func openReplicas(ctx context.Context, cfg *appconfig.Config, logg *logger.Logger) (*dbrouter.Router, error) {
+	if len(cfg.DB.ReplicaDSNs) == 0 {
+		return nil, nil
+	}
+
+	configs, err := cfg.DB.ReplicaPoolConfigs()
+	if err != nil {
+		return nil, err
+	}
+
+	pools := make([]*pgxpool.Pool, 0, len(configs))
+	for _, poolCfg := range configs {
+		pool, err := pgxpool.NewWithConfig(ctx, poolCfg)
+		if err != nil {
+			for _, opened := range pools {
+				opened.Close()
+			}
+			return nil, fmt.Errorf("failed to open replica pool: %w", err)
+		}
+		pools = append(pools, pool)
+	}
+
+	router := dbrouter.New(pools, cfg.DB.ReadYourWritesWindow, logg)
+	router.CheckReplicas(ctx, cfg.Health.Timeout)
+	logg.Info("read replicas configured",
+		"replicas", len(pools),
+		"read_your_writes_window", cfg.DB.ReadYourWritesWindow.String(),
+	)
+
+	return router, nil
+}
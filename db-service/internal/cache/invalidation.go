@@ -0,0 +1,98 @@
+package cache
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// DefaultInvalidationChannel - канал Redis pub/sub для инвалидации локальных кешей
+const DefaultInvalidationChannel = "tasks:cache:invalidate"
+
+// Сообщение об инвалидации: "<instanceID> <ключ задачи>" или "<instanceID> tasks:list:gen <поколение>"
+
+func newInstanceID() string {
+	b := make([]byte, 8)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// publish сообщает другим репликам, что запись key изменилась
+func (c *TaskCache) publish(ctx context.Context, key string) {
+	const op = "publish"
+
+	if err := c.client.Publish(ctx, c.channel, c.instanceID+" "+key).Err(); err != nil {
+		c.log.Warn("failed to publish cache invalidation", "function", op, "key", key, "error", err)
+	}
+}
+
+// Run слушает сообщения об инвалидации от других реплик, пока не отменен ctx.
+// После (пере)подписки локальный кеш очищается: сообщения, отправленные
+// во время разрыва соединения, потеряны.
+func (c *TaskCache) Run(ctx context.Context) {
+	const op = "Run"
+
+	if !c.Enabled() || c.local == nil {
+		return
+	}
+
+	pubsub := c.client.Subscribe(ctx, c.channel)
+	defer pubsub.Close()
+
+	for {
+		msg, err := pubsub.Receive(ctx)
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			c.log.Warn("cache invalidation subscription failed", "function", op, "error", err)
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(time.Second):
+			}
+			continue
+		}
+
+		switch m := msg.(type) {
+		case *redis.Subscription:
+			if m.Kind == "subscribe" {
+				c.local.purge()
+				c.expireGeneration()
+				c.log.Debug("subscribed to cache invalidation", "function", op, "channel", m.Channel)
+			}
+		case *redis.Message:
+			c.handleInvalidation(m.Payload)
+		}
+	}
+}
+
+func (c *TaskCache) handleInvalidation(payload string) {
+	fields := strings.Fields(payload)
+	if len(fields) < 2 || fields[0] == c.instanceID {
+		return
+	}
+
+	key := fields[1]
+	if key == listGenKey {
+		if len(fields) != 3 {
+			return
+		}
+		gen, err := strconv.ParseInt(fields[2], 10, 64)
+		if err != nil {
+			return
+		}
+		c.storeGeneration(gen)
+		c.metrics.invalidation(KindList)
+		return
+	}
+
+	c.local.delete(key)
+	c.metrics.invalidation(KindTask)
+}
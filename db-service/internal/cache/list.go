@@ -0,0 +1,160 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/N0F1X3d/todo/db-service/internal/models"
+	"github.com/redis/go-redis/v9"
+)
+
+// Списки задач кешируются под ключом tasks:list:<поколение>:<фильтр>.
+// Любая запись увеличивает поколение (INCR tasks:list:gen), поэтому списки
+// прошлых поколений больше не читаются и просто истекают по TTL.
+const listGenKey = "tasks:list:gen"
+
+// ListFilterAll - фильтр списка всех задач (GetAllTasks)
+const ListFilterAll = "all"
+
+func listKey(gen int64, filter string) string {
+	return fmt.Sprintf("tasks:list:%d:%s", gen, filter)
+}
+
+// ListGeneration возвращает текущее поколение списков. Поколение нужно получить
+// до чтения списка из БД: если запись случится во время чтения, результат
+// ляжет под старое поколение и не будет прочитан. ok == false - кеш недоступен.
+func (c *TaskCache) ListGeneration(ctx context.Context) (int64, bool) {
+	const op = "ListGeneration"
+
+	if !c.Enabled() {
+		return 0, false
+	}
+
+	if c.local != nil {
+		c.genMu.Lock()
+		gen, valid := c.gen, time.Now().Before(c.genExpires)
+		c.genMu.Unlock()
+		if valid {
+			return gen, true
+		}
+	}
+
+	gen, err := c.client.Get(ctx, listGenKey).Int64()
+	if err != nil && !errors.Is(err, redis.Nil) {
+		c.log.Warn("failed to get list generation", "function", op, "error", err)
+		return 0, false
+	}
+
+	c.storeGeneration(gen)
+	return gen, true
+}
+
+// GetList возвращает список задач поколения gen
+func (c *TaskCache) GetList(ctx context.Context, gen int64, filter string) ([]models.Task, bool) {
+	const op = "GetList"
+
+	if !c.Enabled() {
+		return nil, false
+	}
+
+	key := listKey(gen, filter)
+	if value, ok := c.local.get(key); ok {
+		c.metrics.lookup(TierLocal, KindList, true)
+		return append([]models.Task(nil), value.([]models.Task)...), true
+	}
+	if c.local != nil {
+		c.metrics.lookup(TierLocal, KindList, false)
+	}
+
+	data, err := c.client.Get(ctx, key).Bytes()
+	if err != nil {
+		if !errors.Is(err, redis.Nil) {
+			c.log.Warn("failed to get task list from cache", "function", op, "key", key, "error", err)
+		}
+		c.metrics.lookup(TierRedis, KindList, false)
+		return nil, false
+	}
+
+	var tasks []models.Task
+	if err := json.Unmarshal(data, &tasks); err != nil {
+		c.log.Warn("failed to unmarshal task list from cache", "function", op, "key", key, "error", err)
+		c.metrics.lookup(TierRedis, KindList, false)
+		return nil, false
+	}
+
+	c.metrics.lookup(TierRedis, KindList, true)
+	c.local.set(key, append([]models.Task(nil), tasks...))
+	return tasks, true
+}
+
+// SetList кладет список задач поколения gen в кеш
+func (c *TaskCache) SetList(ctx context.Context, gen int64, filter string, tasks []models.Task) {
+	const op = "SetList"
+
+	if !c.Enabled() {
+		return
+	}
+
+	data, err := json.Marshal(tasks)
+	if err != nil {
+		c.log.Warn("failed to marshal task list for cache", "function", op, "error", err)
+		return
+	}
+
+	key := listKey(gen, filter)
+	if err := c.client.Set(ctx, key, data, c.ttl).Err(); err != nil {
+		c.log.Warn("failed to set task list cache", "function", op, "key", key, "error", err)
+		return
+	}
+
+	c.local.set(key, append([]models.Task(nil), tasks...))
+}
+
+// InvalidateLists начинает новое поколение списков и сообщает о нем другим репликам
+func (c *TaskCache) InvalidateLists(ctx context.Context) {
+	const op = "InvalidateLists"
+
+	if !c.Enabled() {
+		return
+	}
+
+	gen, err := c.client.Incr(ctx, listGenKey).Result()
+	if err != nil {
+		c.log.Warn("failed to bump list generation", "function", op, "error", err)
+		// Следующее чтение перечитает поколение из Redis
+		c.expireGeneration()
+		return
+	}
+
+	c.storeGeneration(gen)
+	c.publish(ctx, fmt.Sprintf("%s %d", listGenKey, gen))
+}
+
+// storeGeneration запоминает поколение списков на время жизни локального кеша.
+// Поколение только растет: опоздавшее сообщение другой реплики со старым поколением
+// не откатывает свежее (иначе списки читались бы без собственной записи). Старое
+// значение принимается, только если запомненное уже истекло - тогда оно прочитано из Redis.
+func (c *TaskCache) storeGeneration(gen int64) {
+	if c.local == nil {
+		return
+	}
+
+	now := time.Now()
+	c.genMu.Lock()
+	defer c.genMu.Unlock()
+
+	if gen < c.gen && now.Before(c.genExpires) {
+		return
+	}
+	c.gen = gen
+	c.genExpires = now.Add(c.localTTL)
+}
+
+func (c *TaskCache) expireGeneration() {
+	c.genMu.Lock()
+	c.genExpires = time.Time{}
+	c.genMu.Unlock()
+}
@@ -0,0 +1,166 @@
+package cache_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/N0F1X3d/todo/db-service/internal/cache"
+	"github.com/N0F1X3d/todo/db-service/internal/models"
+	"github.com/alicebob/miniredis/v2"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// runInvalidation запускает подписку c и ждет, пока она появится в Redis
+func runInvalidation(t *testing.T, mr *miniredis.Miniredis, c *cache.TaskCache, subscribers int) {
+	t.Helper()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	go c.Run(ctx)
+
+	require.Eventually(t, func() bool {
+		return mr.PubSubNumSub(cache.DefaultInvalidationChannel)[cache.DefaultInvalidationChannel] == subscribers
+	}, time.Second, 5*time.Millisecond)
+}
+
+func TestTaskCache_ListByGeneration(t *testing.T) {
+	c, _ := newTestCache(t)
+	ctx := context.Background()
+	tasks := []models.Task{*newTask(1, "first", false, time.Now())}
+
+	gen, ok := c.ListGeneration(ctx)
+	require.True(t, ok)
+
+	c.SetList(ctx, gen, cache.ListFilterAll, tasks)
+
+	cached, ok := c.GetList(ctx, gen, cache.ListFilterAll)
+	require.True(t, ok)
+	assert.Equal(t, "first", cached[0].Title)
+}
+
+func TestTaskCache_InvalidateListsStartsNewGeneration(t *testing.T) {
+	c, _ := newTestCache(t)
+	ctx := context.Background()
+
+	gen, ok := c.ListGeneration(ctx)
+	require.True(t, ok)
+	c.SetList(ctx, gen, cache.ListFilterAll, []models.Task{*newTask(1, "first", false, time.Now())})
+
+	c.InvalidateLists(ctx)
+
+	newGen, ok := c.ListGeneration(ctx)
+	require.True(t, ok)
+	assert.Greater(t, newGen, gen)
+
+	_, ok = c.GetList(ctx, newGen, cache.ListFilterAll)
+	assert.False(t, ok)
+}
+
+func TestTaskCache_ListStoredBeforeWriteIsNotServed(t *testing.T) {
+	c, _ := newTestCache(t)
+	ctx := context.Background()
+
+	// Чтение взяло поколение, затем произошла запись, и только потом список лег в кеш
+	gen, ok := c.ListGeneration(ctx)
+	require.True(t, ok)
+	c.InvalidateLists(ctx)
+	c.SetList(ctx, gen, cache.ListFilterAll, []models.Task{*newTask(1, "stale", false, time.Now())})
+
+	newGen, ok := c.ListGeneration(ctx)
+	require.True(t, ok)
+	_, ok = c.GetList(ctx, newGen, cache.ListFilterAll)
+	assert.False(t, ok)
+}
+
+func TestTaskCache_ListLocalTierCounters(t *testing.T) {
+	mr := miniredis.RunT(t)
+	c, metrics := newTwoTierCache(t, mr)
+	ctx := context.Background()
+
+	gen, ok := c.ListGeneration(ctx)
+	require.True(t, ok)
+
+	_, ok = c.GetList(ctx, gen, cache.ListFilterAll)
+	require.False(t, ok)
+	c.SetList(ctx, gen, cache.ListFilterAll, []models.Task{*newTask(1, "first", false, time.Now())})
+	_, ok = c.GetList(ctx, gen, cache.ListFilterAll)
+	require.True(t, ok)
+
+	assert.Equal(t, 1.0, testutil.ToFloat64(metrics.Requests(cache.TierLocal, cache.KindList, "miss")))
+	assert.Equal(t, 1.0, testutil.ToFloat64(metrics.Requests(cache.TierRedis, cache.KindList, "miss")))
+	assert.Equal(t, 1.0, testutil.ToFloat64(metrics.Requests(cache.TierLocal, cache.KindList, "hit")))
+}
+
+func TestTaskCache_InvalidationAcrossReplicas(t *testing.T) {
+	mr := miniredis.RunT(t)
+	first, _ := newTwoTierCache(t, mr)
+	second, metrics := newTwoTierCache(t, mr)
+	runInvalidation(t, mr, first, 1)
+	runInvalidation(t, mr, second, 2)
+	ctx := context.Background()
+	now := time.Now()
+
+	first.Set(ctx, newTask(1, "task", false, now))
+
+	// Вторая реплика кладет задачу в свой LRU
+	task, ok := second.Get(ctx, 1)
+	require.True(t, ok)
+	require.False(t, task.Completed)
+
+	// Первая реплика завершает задачу - LRU второй реплики должен сброситься
+	first.Set(ctx, newTask(1, "task", true, now.Add(time.Second)))
+
+	require.Eventually(t, func() bool {
+		task, ok := second.Get(ctx, 1)
+		return ok && task.Completed
+	}, time.Second, 5*time.Millisecond)
+	assert.Positive(t, testutil.ToFloat64(metrics.Invalidations(cache.KindTask)))
+}
+
+func TestTaskCache_ListInvalidationAcrossReplicas(t *testing.T) {
+	mr := miniredis.RunT(t)
+	first, _ := newTwoTierCache(t, mr)
+	second, _ := newTwoTierCache(t, mr)
+	runInvalidation(t, mr, first, 1)
+	runInvalidation(t, mr, second, 2)
+	ctx := context.Background()
+
+	gen, ok := second.ListGeneration(ctx)
+	require.True(t, ok)
+
+	first.InvalidateLists(ctx)
+
+	// Вторая реплика узнает о новом поколении без обращения к Redis
+	require.Eventually(t, func() bool {
+		newGen, ok := second.ListGeneration(ctx)
+		return ok && newGen > gen
+	}, time.Second, 5*time.Millisecond)
+}
+
+func TestTaskCache_StaleGenerationMessageIsIgnored(t *testing.T) {
+	mr := miniredis.RunT(t)
+	c, metrics := newTwoTierCache(t, mr)
+	runInvalidation(t, mr, c, 1)
+	ctx := context.Background()
+
+	c.InvalidateLists(ctx)
+	c.InvalidateLists(ctx)
+	gen, ok := c.ListGeneration(ctx)
+	require.True(t, ok)
+
+	// Опоздавшее сообщение другой реплики с предыдущим поколением, за ним - метка,
+	// по которой видно, что оба сообщения обработаны
+	mr.Publish(cache.DefaultInvalidationChannel, fmt.Sprintf("peer tasks:list:gen %d", gen-1))
+	mr.Publish(cache.DefaultInvalidationChannel, "peer "+c.Key(1))
+	require.Eventually(t, func() bool {
+		return testutil.ToFloat64(metrics.Invalidations(cache.KindTask)) == 1
+	}, time.Second, 5*time.Millisecond)
+
+	stored, ok := c.ListGeneration(ctx)
+	require.True(t, ok)
+	assert.Equal(t, gen, stored)
+}
@@ -0,0 +1,178 @@
+package cache_test
+
+import (
+	"context"
+	"database/sql"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/N0F1X3d/todo/db-service/internal/cache"
+	"github.com/N0F1X3d/todo/db-service/internal/models"
+	"github.com/N0F1X3d/todo/pkg/logger"
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// slowLoader имитирует медленный запрос к БД и считает обращения к ней
+func slowLoader(calls *atomic.Int32, task *models.Task, err error) cache.LoadFunc {
+	return func() (*models.Task, error) {
+		calls.Add(1)
+		time.Sleep(50 * time.Millisecond)
+		if err != nil {
+			return nil, err
+		}
+		loaded := *task
+		return &loaded, nil
+	}
+}
+
+// loadConcurrently вызывает GetOrLoad из n горутин одновременно
+func loadConcurrently(t *testing.T, c *cache.TaskCache, n int, load cache.LoadFunc) {
+	t.Helper()
+
+	var wg sync.WaitGroup
+	start := make(chan struct{})
+	for range n {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			<-start
+			task, err := c.GetOrLoad(context.Background(), 1, load)
+			if assert.NoError(t, err) {
+				assert.Equal(t, "task", task.Title)
+			}
+		}()
+	}
+	close(start)
+	wg.Wait()
+}
+
+func TestTaskCache_GetOrLoadCollapsesConcurrentMisses(t *testing.T) {
+	c, _ := newTestCache(t)
+	var calls atomic.Int32
+
+	loadConcurrently(t, c, 20, slowLoader(&calls, newTask(1, "task", false, time.Now()), nil))
+
+	assert.Equal(t, int32(1), calls.Load())
+
+	// Результат загрузки лег в кеш
+	_, err := c.GetOrLoad(context.Background(), 1, slowLoader(&calls, nil, sql.ErrNoRows))
+	require.NoError(t, err)
+	assert.Equal(t, int32(1), calls.Load())
+}
+
+func TestTaskCache_GetOrLoadCollapsesWithoutRedis(t *testing.T) {
+	c := cache.NewTaskCache(nil, time.Minute, logger.New("db-service", "test-logs"))
+	var calls atomic.Int32
+
+	loadConcurrently(t, c, 20, slowLoader(&calls, newTask(1, "task", false, time.Now()), nil))
+
+	assert.Equal(t, int32(1), calls.Load())
+}
+
+func TestTaskCache_NegativeEntry(t *testing.T) {
+	c, mr := newTestCache(t)
+	c.WithNegativeTTL(5 * time.Second)
+	ctx := context.Background()
+	var calls atomic.Int32
+
+	_, err := c.GetOrLoad(ctx, 1, slowLoader(&calls, nil, sql.ErrNoRows))
+	require.ErrorIs(t, err, sql.ErrNoRows)
+
+	// Повторный запрос не доходит до БД
+	_, err = c.GetOrLoad(ctx, 1, slowLoader(&calls, nil, sql.ErrNoRows))
+	require.ErrorIs(t, err, sql.ErrNoRows)
+	assert.Equal(t, int32(1), calls.Load())
+
+	// Отметка живет только negative TTL
+	mr.FastForward(6 * time.Second)
+	_, err = c.GetOrLoad(ctx, 1, slowLoader(&calls, nil, sql.ErrNoRows))
+	require.ErrorIs(t, err, sql.ErrNoRows)
+	assert.Equal(t, int32(2), calls.Load())
+}
+
+func TestTaskCache_SetClearsNegativeEntry(t *testing.T) {
+	c, _ := newTestCache(t)
+	c.WithNegativeTTL(5 * time.Second)
+	ctx := context.Background()
+	var calls atomic.Int32
+
+	_, err := c.GetOrLoad(ctx, 1, slowLoader(&calls, nil, sql.ErrNoRows))
+	require.ErrorIs(t, err, sql.ErrNoRows)
+
+	// CreateTask кладет созданную задачу в кеш
+	c.Set(ctx, newTask(1, "task", false, time.Now()))
+
+	task, err := c.GetOrLoad(ctx, 1, slowLoader(&calls, nil, sql.ErrNoRows))
+	require.NoError(t, err)
+	assert.Equal(t, "task", task.Title)
+	assert.Equal(t, int32(1), calls.Load())
+}
+
+func TestTaskCache_TombstoneIsNegativeHit(t *testing.T) {
+	c, _ := newTestCache(t)
+	ctx := context.Background()
+	var calls atomic.Int32
+
+	c.Delete(ctx, 1)
+
+	// Удаленная задача отдается как отсутствующая без обращения к БД
+	_, err := c.GetOrLoad(ctx, 1, slowLoader(&calls, nil, sql.ErrNoRows))
+	require.ErrorIs(t, err, sql.ErrNoRows)
+	assert.Zero(t, calls.Load())
+}
+
+func TestTaskCache_StaleWhileRevalidate(t *testing.T) {
+	mr := miniredis.RunT(t)
+	rdb := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { _ = rdb.Close() })
+	c := cache.NewTaskCache(rdb, 50*time.Millisecond, logger.New("db-service", "test-logs")).
+		WithStaleWhileRevalidate(time.Minute)
+	ctx := context.Background()
+	created := time.Now()
+	var calls atomic.Int32
+
+	c.Set(ctx, newTask(1, "old", false, created))
+	time.Sleep(60 * time.Millisecond)
+
+	// Устаревшая запись отдается сразу, а обновление идет в фоне одним запросом
+	fresh := newTask(1, "new", false, created.Add(time.Second))
+	for range 5 {
+		task, err := c.GetOrLoad(ctx, 1, slowLoader(&calls, fresh, nil))
+		require.NoError(t, err)
+		assert.Equal(t, "old", task.Title)
+	}
+
+	require.Eventually(t, func() bool {
+		task, ok := c.Get(ctx, 1)
+		return ok && task.Title == "new"
+	}, time.Second, 5*time.Millisecond)
+	assert.Equal(t, int32(1), calls.Load())
+}
+
+func TestTaskCache_GetOrLoadPrimaryDoesNotJoinReplicaLoad(t *testing.T) {
+	// Без Redis GetOrLoad тоже схлопывает промахи
+	c := cache.NewTaskCache(nil, time.Minute, logger.New("db-service", "test-logs"))
+	var replicaCalls, primaryCalls atomic.Int32
+
+	// Медленная загрузка с реплики, начатая до записи, видит старую задачу
+	replicaDone := make(chan *models.Task, 1)
+	go func() {
+		task, err := c.GetOrLoad(context.Background(), 1, slowLoader(&replicaCalls, newTask(1, "before", false, time.Now()), nil))
+		assert.NoError(t, err)
+		replicaDone <- task
+	}()
+	require.Eventually(t, func() bool { return replicaCalls.Load() == 1 }, time.Second, time.Millisecond)
+
+	// Автор записи читает с primary и не получает результат реплики
+	task, err := c.GetOrLoadPrimary(context.Background(), 1, slowLoader(&primaryCalls, newTask(1, "after", true, time.Now()), nil))
+	require.NoError(t, err)
+	assert.Equal(t, "after", task.Title)
+	assert.Equal(t, int32(1), primaryCalls.Load())
+
+	assert.Equal(t, "before", (<-replicaDone).Title)
+}
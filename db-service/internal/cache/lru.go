@@ -0,0 +1,112 @@
+package cache
+
+import (
+	"container/list"
+	"sync"
+	"time"
+)
+
+// lru - потокобезопасный LRU с TTL записей, первый уровень кеша в памяти процесса.
+// Методы nil-безопасны: nil означает, что локальный кеш выключен.
+type lru struct {
+	mu    sync.Mutex
+	size  int
+	ttl   time.Duration
+	ll    *list.List
+	items map[string]*list.Element
+}
+
+type lruEntry struct {
+	key     string
+	value   any
+	expires time.Time
+}
+
+// newLRU создает LRU на size записей. При size <= 0 или ttl <= 0 возвращает nil.
+func newLRU(size int, ttl time.Duration) *lru {
+	if size <= 0 || ttl <= 0 {
+		return nil
+	}
+	return &lru{
+		size:  size,
+		ttl:   ttl,
+		ll:    list.New(),
+		items: make(map[string]*list.Element, size),
+	}
+}
+
+func (l *lru) get(key string) (any, bool) {
+	if l == nil {
+		return nil, false
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	el, ok := l.items[key]
+	if !ok {
+		return nil, false
+	}
+
+	entry := el.Value.(*lruEntry)
+	if time.Now().After(entry.expires) {
+		l.removeElement(el)
+		return nil, false
+	}
+
+	l.ll.MoveToFront(el)
+	return entry.value, true
+}
+
+func (l *lru) set(key string, value any) {
+	if l == nil {
+		return
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	expires := time.Now().Add(l.ttl)
+	if el, ok := l.items[key]; ok {
+		entry := el.Value.(*lruEntry)
+		entry.value = value
+		entry.expires = expires
+		l.ll.MoveToFront(el)
+		return
+	}
+
+	l.items[key] = l.ll.PushFront(&lruEntry{key: key, value: value, expires: expires})
+	if l.ll.Len() > l.size {
+		l.removeElement(l.ll.Back())
+	}
+}
+
+func (l *lru) delete(key string) {
+	if l == nil {
+		return
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if el, ok := l.items[key]; ok {
+		l.removeElement(el)
+	}
+}
+
+func (l *lru) purge() {
+	if l == nil {
+		return
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.ll.Init()
+	l.items = make(map[string]*list.Element, l.size)
+}
+
+func (l *lru) removeElement(el *list.Element) {
+	l.ll.Remove(el)
+	delete(l.items, el.Value.(*lruEntry).key)
+}
@@ -0,0 +1,66 @@
+package cache
+
+import "github.com/prometheus/client_golang/prometheus"
+
+// Уровни кеша
+const (
+	TierLocal = "local"
+	TierRedis = "redis"
+)
+
+// Виды записей кеша
+const (
+	KindTask = "task"
+	KindList = "list"
+)
+
+// Metrics - счетчики попаданий и промахов по уровням кеша.
+// Методы nil-безопасны: кеш без метрик просто ничего не считает.
+type Metrics struct {
+	requests      *prometheus.CounterVec
+	invalidations *prometheus.CounterVec
+}
+
+// NewMetrics регистрирует метрики кеша в reg
+func NewMetrics(reg prometheus.Registerer) *Metrics {
+	m := &Metrics{
+		requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "task_cache_requests_total",
+			Help: "Task cache lookups by tier, entry kind and result (hit or miss).",
+		}, []string{"tier", "kind", "result"}),
+		invalidations: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "task_cache_invalidations_received_total",
+			Help: "Invalidation messages received from other db-service replicas.",
+		}, []string{"kind"}),
+	}
+	reg.MustRegister(m.requests, m.invalidations)
+	return m
+}
+
+// Requests возвращает счетчик обращений к уровню tier (для тестов и отладки)
+func (m *Metrics) Requests(tier, kind, result string) prometheus.Counter {
+	return m.requests.WithLabelValues(tier, kind, result)
+}
+
+// Invalidations возвращает счетчик полученных сообщений об инвалидации
+func (m *Metrics) Invalidations(kind string) prometheus.Counter {
+	return m.invalidations.WithLabelValues(kind)
+}
+
+func (m *Metrics) lookup(tier, kind string, hit bool) {
+	if m == nil {
+		return
+	}
+	result := "miss"
+	if hit {
+		result = "hit"
+	}
+	m.requests.WithLabelValues(tier, kind, result).Inc()
+}
+
+func (m *Metrics) invalidation(kind string) {
+	if m == nil {
+		return
+	}
+	m.invalidations.WithLabelValues(kind).Inc()
+}
@@ -0,0 +1,343 @@
+package cache
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/N0F1X3d/todo/db-service/internal/models"
+	"github.com/N0F1X3d/todo/pkg/logger"
+	"github.com/redis/go-redis/v9"
+	"golang.org/x/sync/singleflight"
+)
+
+// Запись задачи хранится в Redis hash:
+//
+//	version     - updated_at задачи в микросекундах
+//	data        - задача в JSON
+//	deleted     - "1" для tombstone после удаления
+//	missing     - "1" для negative cache: задачи с таким id нет
+//	fresh_until - до какого момента (unix ms) запись свежая; после - отдается, но обновляется в фоне
+//
+// Запись заменяется только более новой версией, а tombstone блокирует запись
+// до истечения TTL. Поэтому реплика, прочитавшая из БД устаревшую строку,
+// не может перезаписать в кеше результат записи другой реплики.
+// Запись задачи снимает отметку missing - так CreateTask очищает negative cache.
+var setIfNewerScript = redis.NewScript(`
+local deleted = redis.call('HGET', KEYS[1], 'deleted')
+if deleted == '1' then
+	return 0
+end
+local current = redis.call('HGET', KEYS[1], 'version')
+if current and tonumber(current) > tonumber(ARGV[1]) then
+	return 0
+end
+redis.call('HSET', KEYS[1], 'version', ARGV[1], 'data', ARGV[2], 'deleted', '0', 'missing', '0', 'fresh_until', ARGV[4])
+redis.call('PEXPIRE', KEYS[1], ARGV[3])
+return 1
+`)
+
+// setMissingScript ставит отметку missing, только если о задаче ничего не известно:
+// параллельно созданная задача или tombstone важнее
+var setMissingScript = redis.NewScript(`
+if redis.call('EXISTS', KEYS[1]) == 1 then
+	return 0
+end
+redis.call('HSET', KEYS[1], 'missing', '1')
+redis.call('PEXPIRE', KEYS[1], ARGV[1])
+return 1
+`)
+
+// LoadFunc читает задачу из источника данных при промахе кеша.
+// Отсутствие задачи обозначается sql.ErrNoRows.
+type LoadFunc func() (*models.Task, error)
+
+type lookupState int
+
+const (
+	lookupMiss lookupState = iota
+	lookupHit
+	// lookupStale - запись есть, но срок свежести истек: отдаем и обновляем в фоне
+	lookupStale
+	// lookupMissing - задачи нет: negative cache или tombstone
+	lookupMissing
+)
+
+// TaskCache - двухуровневый кеш задач, безопасный при нескольких репликах db-service:
+// LRU в памяти процесса перед Redis. Изменения рассылаются другим репликам через
+// Redis pub/sub (см. Run), а записи LRU живут не дольше localTTL даже без рассылки.
+type TaskCache struct {
+	client *redis.Client
+	ttl    time.Duration
+	log    *logger.Logger
+
+	local    *lru
+	localTTL time.Duration
+	metrics  *Metrics
+
+	// negativeTTL - сколько помнить, что задачи нет (0 - не помнить)
+	negativeTTL time.Duration
+	// staleWindow - сколько после ttl запись отдается устаревшей, пока обновляется в фоне
+	staleWindow time.Duration
+	// loads схлопывает параллельные чтения одной задачи из БД
+	loads singleflight.Group
+
+	// instanceID отличает свои сообщения об инвалидации от чужих
+	instanceID string
+	channel    string
+
+	genMu      sync.Mutex
+	gen        int64
+	genExpires time.Time
+}
+
+// NewTaskCache создает TaskCache без локального уровня. Если client == nil или ttl <= 0, кеш выключен.
+func NewTaskCache(client *redis.Client, ttl time.Duration, log *logger.Logger) *TaskCache {
+	return &TaskCache{
+		client:     client,
+		ttl:        ttl,
+		log:        log.WithComponent("cache").WithFunction("TaskCache"),
+		instanceID: newInstanceID(),
+		channel:    DefaultInvalidationChannel,
+	}
+}
+
+// WithLocal включает LRU в памяти процесса на size записей с временем жизни ttl
+func (c *TaskCache) WithLocal(size int, ttl time.Duration) *TaskCache {
+	c.local = newLRU(size, ttl)
+	c.localTTL = ttl
+	return c
+}
+
+// WithNegativeTTL включает negative cache: отсутствие задачи помнится ttl
+func (c *TaskCache) WithNegativeTTL(ttl time.Duration) *TaskCache {
+	c.negativeTTL = ttl
+	return c
+}
+
+// WithStaleWhileRevalidate разрешает отдавать запись еще window после истечения
+// свежести, обновляя ее из БД в фоне
+func (c *TaskCache) WithStaleWhileRevalidate(window time.Duration) *TaskCache {
+	c.staleWindow = window
+	return c
+}
+
+// WithMetrics включает счетчики попаданий и промахов
+func (c *TaskCache) WithMetrics(m *Metrics) *TaskCache {
+	c.metrics = m
+	return c
+}
+
+// WithInvalidationChannel задает канал Redis pub/sub для инвалидации между репликами
+func (c *TaskCache) WithInvalidationChannel(channel string) *TaskCache {
+	c.channel = channel
+	return c
+}
+
+// Enabled сообщает, включен ли кеш
+func (c *TaskCache) Enabled() bool {
+	return c != nil && c.client != nil && c.ttl > 0
+}
+
+// Key возвращает ключ задачи в Redis
+func (c *TaskCache) Key(id int) string {
+	return fmt.Sprintf("task:%d", id)
+}
+
+// Get возвращает задачу из кеша. Tombstone и negative cache считаются промахом.
+func (c *TaskCache) Get(ctx context.Context, id int) (*models.Task, bool) {
+	task, state := c.lookup(ctx, id)
+	return task, state == lookupHit || state == lookupStale
+}
+
+// GetOrLoad возвращает задачу из кеша, а при промахе читает ее через load.
+// Параллельные промахи по одной задаче выполняют один load (singleflight),
+// отсутствие задачи кешируется на negativeTTL, а устаревшая запись отдается
+// сразу и обновляется в фоне. Работает и с выключенным кешем: тогда только
+// схлопывает параллельные чтения.
+func (c *TaskCache) GetOrLoad(ctx context.Context, id int, load LoadFunc) (*models.Task, error) {
+	return c.getOrLoad(ctx, id, c.Key(id), load)
+}
+
+// GetOrLoadPrimary - GetOrLoad для чтения, которое должно увидеть последнюю запись
+// клиента (read-your-writes): промах схлопывается только с такими же чтениями
+// с primary, а не с загрузкой с реплики, начатой до записи
+func (c *TaskCache) GetOrLoadPrimary(ctx context.Context, id int, load LoadFunc) (*models.Task, error) {
+	return c.getOrLoad(ctx, id, c.Key(id)+":primary", load)
+}
+
+// getOrLoad - GetOrLoad с ключом singleflight flight
+func (c *TaskCache) getOrLoad(ctx context.Context, id int, flight string, load LoadFunc) (*models.Task, error) {
+	task, state := c.lookup(ctx, id)
+	switch state {
+	case lookupHit:
+		return task, nil
+	case lookupMissing:
+		return nil, sql.ErrNoRows
+	case lookupStale:
+		// Результат фонового обновления не ждем: канал DoChan буферизован
+		c.loads.DoChan(flight, c.loader(context.Background(), id, load))
+		return task, nil
+	}
+
+	v, err, _ := c.loads.Do(flight, c.loader(ctx, id, load))
+	if err != nil {
+		return nil, err
+	}
+
+	loaded := v.(models.Task)
+	return &loaded, nil
+}
+
+// loader читает задачу через load и кладет результат (или его отсутствие) в кеш
+func (c *TaskCache) loader(ctx context.Context, id int, load LoadFunc) func() (any, error) {
+	return func() (any, error) {
+		task, err := load()
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				c.setMissing(ctx, id)
+			}
+			return nil, err
+		}
+
+		c.Set(ctx, task)
+		return *task, nil
+	}
+}
+
+func (c *TaskCache) lookup(ctx context.Context, id int) (*models.Task, lookupState) {
+	const op = "lookup"
+
+	if !c.Enabled() {
+		return nil, lookupMiss
+	}
+
+	key := c.Key(id)
+	if value, ok := c.local.get(key); ok {
+		c.metrics.lookup(TierLocal, KindTask, true)
+		task := value.(models.Task)
+		return &task, lookupHit
+	}
+	if c.local != nil {
+		c.metrics.lookup(TierLocal, KindTask, false)
+	}
+
+	fields, err := c.client.HMGet(ctx, key, "data", "deleted", "missing", "fresh_until").Result()
+	if err != nil {
+		c.log.Warn("failed to get task from cache", "function", op, "task_id", id, "error", err)
+		c.metrics.lookup(TierRedis, KindTask, false)
+		return nil, lookupMiss
+	}
+
+	// id не переиспользуются, поэтому удаленная задача тоже отсутствует
+	if fields[1] == "1" || fields[2] == "1" {
+		c.metrics.lookup(TierRedis, KindTask, true)
+		return nil, lookupMissing
+	}
+
+	data, ok := fields[0].(string)
+	if !ok {
+		c.metrics.lookup(TierRedis, KindTask, false)
+		return nil, lookupMiss
+	}
+
+	var task models.Task
+	if err := json.Unmarshal([]byte(data), &task); err != nil {
+		c.log.Warn("failed to unmarshal task from cache", "function", op, "task_id", id, "error", err)
+		c.metrics.lookup(TierRedis, KindTask, false)
+		return nil, lookupMiss
+	}
+
+	c.metrics.lookup(TierRedis, KindTask, true)
+
+	if freshUntil, ok := fields[3].(string); ok && c.staleWindow > 0 {
+		if ms, err := strconv.ParseInt(freshUntil, 10, 64); err == nil && time.Now().UnixMilli() > ms {
+			return &task, lookupStale
+		}
+	}
+
+	c.local.set(key, task)
+	return &task, lookupHit
+}
+
+// setMissing запоминает, что задачи id нет
+func (c *TaskCache) setMissing(ctx context.Context, id int) {
+	const op = "setMissing"
+
+	if !c.Enabled() || c.negativeTTL <= 0 {
+		return
+	}
+
+	err := setMissingScript.Run(ctx, c.client, []string{c.Key(id)}, c.negativeTTL.Milliseconds()).Err()
+	if err != nil {
+		c.log.Warn("failed to set negative cache", "function", op, "task_id", id, "error", err)
+	}
+}
+
+// Set кладет задачу в кеш, если в кеше нет более новой версии или tombstone
+func (c *TaskCache) Set(ctx context.Context, task *models.Task) {
+	const op = "Set"
+
+	if !c.Enabled() || task == nil {
+		return
+	}
+
+	data, err := json.Marshal(task)
+	if err != nil {
+		c.log.Warn("failed to marshal task for cache", "function", op, "task_id", task.ID, "error", err)
+		return
+	}
+
+	key := c.Key(task.ID)
+	written, err := setIfNewerScript.Run(ctx, c.client,
+		[]string{key},
+		strconv.FormatInt(task.UpdatedAt.UnixMicro(), 10),
+		data,
+		(c.ttl + c.staleWindow).Milliseconds(),
+		time.Now().Add(c.ttl).UnixMilli(),
+	).Int()
+	if err != nil {
+		c.log.Warn("failed to set task cache", "function", op, "task_id", task.ID, "error", err)
+		c.local.delete(key)
+		return
+	}
+
+	// В Redis более новая версия или tombstone - локальную копию не обновляем
+	if written == 0 {
+		c.local.delete(key)
+		return
+	}
+
+	c.local.set(key, *task)
+	c.publish(ctx, key)
+}
+
+// Delete заменяет запись tombstone на время TTL, чтобы параллельное
+// чтение из БД на другой реплике не вернуло удаленную задачу в кеш
+func (c *TaskCache) Delete(ctx context.Context, id int) {
+	const op = "Delete"
+
+	if !c.Enabled() {
+		return
+	}
+
+	key := c.Key(id)
+	c.local.delete(key)
+
+	_, err := c.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.Del(ctx, key)
+		pipe.HSet(ctx, key, "deleted", "1")
+		pipe.PExpire(ctx, key, c.ttl)
+		return nil
+	})
+	if err != nil {
+		c.log.Warn("failed to delete task cache", "function", op, "task_id", id, "error", err)
+	}
+
+	c.publish(ctx, key)
+}
@@ -0,0 +1,214 @@
+package cache_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/N0F1X3d/todo/db-service/internal/cache"
+	"github.com/N0F1X3d/todo/db-service/internal/models"
+	"github.com/N0F1X3d/todo/pkg/logger"
+	"github.com/alicebob/miniredis/v2"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestCache(t *testing.T) (*cache.TaskCache, *miniredis.Miniredis) {
+	t.Helper()
+
+	mr := miniredis.RunT(t)
+	rdb := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { _ = rdb.Close() })
+
+	testLogger := logger.New("db-service", "test-logs")
+	return cache.NewTaskCache(rdb, time.Minute, testLogger), mr
+}
+
+func newTask(id int, title string, completed bool, updatedAt time.Time) *models.Task {
+	return &models.Task{
+		ID:        id,
+		Title:     title,
+		Completed: completed,
+		CreatedAt: updatedAt,
+		UpdatedAt: updatedAt,
+	}
+}
+
+func TestTaskCache_SetGet(t *testing.T) {
+	c, _ := newTestCache(t)
+	ctx := context.Background()
+	now := time.Now()
+
+	c.Set(ctx, newTask(1, "task", false, now))
+
+	task, ok := c.Get(ctx, 1)
+	require.True(t, ok)
+	assert.Equal(t, 1, task.ID)
+	assert.Equal(t, "task", task.Title)
+}
+
+func TestTaskCache_Miss(t *testing.T) {
+	c, _ := newTestCache(t)
+
+	_, ok := c.Get(context.Background(), 42)
+
+	assert.False(t, ok)
+}
+
+func TestTaskCache_StaleWriteIgnored(t *testing.T) {
+	c, _ := newTestCache(t)
+	ctx := context.Background()
+	created := time.Now()
+	completed := created.Add(time.Second)
+
+	// Реплика B завершила задачу и обновила кеш
+	c.Set(ctx, newTask(1, "task", true, completed))
+	// Реплика A прочитала из БД строку до завершения и пытается положить ее в кеш
+	c.Set(ctx, newTask(1, "task", false, created))
+
+	task, ok := c.Get(ctx, 1)
+	require.True(t, ok)
+	assert.True(t, task.Completed)
+}
+
+func TestTaskCache_NewerWriteReplaces(t *testing.T) {
+	c, _ := newTestCache(t)
+	ctx := context.Background()
+	created := time.Now()
+
+	c.Set(ctx, newTask(1, "task", false, created))
+	c.Set(ctx, newTask(1, "task", true, created.Add(time.Second)))
+
+	task, ok := c.Get(ctx, 1)
+	require.True(t, ok)
+	assert.True(t, task.Completed)
+}
+
+func TestTaskCache_DeleteBlocksStaleFill(t *testing.T) {
+	c, _ := newTestCache(t)
+	ctx := context.Background()
+	now := time.Now()
+
+	c.Set(ctx, newTask(1, "task", false, now))
+	c.Delete(ctx, 1)
+
+	_, ok := c.Get(ctx, 1)
+	assert.False(t, ok)
+
+	// Реплика, прочитавшая строку до удаления, не возвращает задачу в кеш
+	c.Set(ctx, newTask(1, "task", false, now))
+
+	_, ok = c.Get(ctx, 1)
+	assert.False(t, ok)
+}
+
+func TestTaskCache_TombstoneExpires(t *testing.T) {
+	c, mr := newTestCache(t)
+	ctx := context.Background()
+
+	c.Delete(ctx, 1)
+	assert.True(t, mr.Exists(c.Key(1)))
+
+	mr.FastForward(2 * time.Minute)
+	assert.False(t, mr.Exists(c.Key(1)))
+}
+
+func TestTaskCache_Disabled(t *testing.T) {
+	testLogger := logger.New("db-service", "test-logs")
+	c := cache.NewTaskCache(nil, time.Minute, testLogger)
+	ctx := context.Background()
+
+	c.Set(ctx, newTask(1, "task", false, time.Now()))
+	c.Delete(ctx, 1)
+	_, ok := c.Get(ctx, 1)
+
+	assert.False(t, c.Enabled())
+	assert.False(t, ok)
+}
+
+// newTwoTierCache создает кеш с LRU и метриками поверх общего Redis mr
+func newTwoTierCache(t *testing.T, mr *miniredis.Miniredis) (*cache.TaskCache, *cache.Metrics) {
+	t.Helper()
+
+	rdb := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { _ = rdb.Close() })
+
+	metrics := cache.NewMetrics(prometheus.NewRegistry())
+	testLogger := logger.New("db-service", "test-logs")
+	c := cache.NewTaskCache(rdb, time.Minute, testLogger).
+		WithLocal(2, time.Minute).
+		WithMetrics(metrics)
+	return c, metrics
+}
+
+func TestTaskCache_LocalTierServesRepeatedReads(t *testing.T) {
+	mr := miniredis.RunT(t)
+	c, metrics := newTwoTierCache(t, mr)
+	ctx := context.Background()
+
+	c.Set(ctx, newTask(1, "task", false, time.Now()))
+
+	// Запись из Redis пропадает, но локальный уровень ее помнит
+	mr.Del(c.Key(1))
+
+	task, ok := c.Get(ctx, 1)
+	require.True(t, ok)
+	assert.Equal(t, "task", task.Title)
+
+	assert.Equal(t, 1.0, testutil.ToFloat64(metrics.Requests(cache.TierLocal, cache.KindTask, "hit")))
+	assert.Zero(t, testutil.ToFloat64(metrics.Requests(cache.TierRedis, cache.KindTask, "hit")))
+}
+
+func TestTaskCache_LocalMissFallsThroughToRedis(t *testing.T) {
+	mr := miniredis.RunT(t)
+	writer, _ := newTwoTierCache(t, mr)
+	reader, metrics := newTwoTierCache(t, mr)
+	ctx := context.Background()
+
+	writer.Set(ctx, newTask(1, "task", false, time.Now()))
+
+	_, ok := reader.Get(ctx, 1)
+	require.True(t, ok)
+	_, ok = reader.Get(ctx, 1)
+	require.True(t, ok)
+	_, ok = reader.Get(ctx, 2)
+	require.False(t, ok)
+
+	assert.Equal(t, 2.0, testutil.ToFloat64(metrics.Requests(cache.TierLocal, cache.KindTask, "miss")))
+	assert.Equal(t, 1.0, testutil.ToFloat64(metrics.Requests(cache.TierLocal, cache.KindTask, "hit")))
+	assert.Equal(t, 1.0, testutil.ToFloat64(metrics.Requests(cache.TierRedis, cache.KindTask, "hit")))
+	assert.Equal(t, 1.0, testutil.ToFloat64(metrics.Requests(cache.TierRedis, cache.KindTask, "miss")))
+}
+
+func TestTaskCache_LocalTierEvictsLeastRecentlyUsed(t *testing.T) {
+	mr := miniredis.RunT(t)
+	c, _ := newTwoTierCache(t, mr)
+	ctx := context.Background()
+	now := time.Now()
+
+	// LRU на две записи: третья вытесняет первую
+	c.Set(ctx, newTask(1, "first", false, now))
+	c.Set(ctx, newTask(2, "second", false, now))
+	c.Set(ctx, newTask(3, "third", false, now))
+	mr.FlushAll()
+
+	_, ok := c.Get(ctx, 1)
+	assert.False(t, ok)
+	_, ok = c.Get(ctx, 3)
+	assert.True(t, ok)
+}
+
+func TestTaskCache_DeleteClearsLocalTier(t *testing.T) {
+	mr := miniredis.RunT(t)
+	c, _ := newTwoTierCache(t, mr)
+	ctx := context.Background()
+
+	c.Set(ctx, newTask(1, "task", false, time.Now()))
+	c.Delete(ctx, 1)
+
+	_, ok := c.Get(ctx, 1)
+	assert.False(t, ok)
+}
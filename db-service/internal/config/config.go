@@ -7,14 +7,23 @@ import (
 	"time"
 
 	"github.com/ilyakaznacheev/cleanenv"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/N0F1X3d/todo/pkg/kafka"
 )
 
 // Config содержит все конфигурации приложения
 type Config struct {
-	App   AppConfig   `yaml:"app" env-prefix:"APP_"`
-	DB    DBConfig    `yaml:"db" env-prefix:"DB_"`
-	GRPC  GRPCConfig  `yaml:"grpc" env-prefix:"GRPC_"`
-	Redis RedisConfig `yaml:"redis" env-prefix:"REDIS_"`
+	App     AppConfig     `yaml:"app" env-prefix:"APP_"`
+	Storage StorageConfig `yaml:"storage" env-prefix:"STORAGE_"`
+	DB      DBConfig      `yaml:"db" env-prefix:"DB_"`
+	GRPC    GRPCConfig    `yaml:"grpc" env-prefix:"GRPC_"`
+	Redis   RedisConfig   `yaml:"redis" env-prefix:"REDIS_"`
+	Health  HealthConfig  `yaml:"health" env-prefix:"HEALTH_"`
+	Metrics MetricsConfig `yaml:"metrics" env-prefix:"METRICS_"`
+	Kafka   KafkaConfig   `yaml:"kafka" env-prefix:"KAFKA_"`
+	Outbox  OutboxConfig  `yaml:"outbox" env-prefix:"OUTBOX_"`
 }
 
 // AppConfig содержит настройки приложения
@@ -25,6 +34,21 @@ type AppConfig struct {
 	Debug   bool   `yaml:"debug" env:"DEBUG" env-default:"false"`
 }
 
+// Драйверы хранилища задач
+const (
+	StorageDriverPostgres = "postgres"
+	StorageDriverSQLite   = "sqlite"
+	StorageDriverMemory   = "memory"
+)
+
+// StorageConfig выбирает хранилище задач
+type StorageConfig struct {
+	// Driver - postgres, sqlite или memory
+	Driver string `yaml:"driver" env:"DRIVER" env-default:"postgres"`
+	// SQLitePath - файл базы для драйвера sqlite
+	SQLitePath string `yaml:"sqlite_path" env:"SQLITE_PATH" env-default:"tasks.db"`
+}
+
 // DBConfig содержит настройки PostgreSQL
 type DBConfig struct {
 	Host     string        `yaml:"host" env:"HOST" env-default:"localhost"`
@@ -35,15 +59,58 @@ type DBConfig struct {
 	SSLMode  string        `yaml:"ssl_mode" env:"SSLMODE" env-default:"disable"`
 	MaxConns int           `yaml:"max_conns" env:"MAX_CONNS" env-default:"10"`
 	Timeout  time.Duration `yaml:"timeout" env:"TIMEOUT" env-default:"5s"`
+	// MinConns - сколько соединений пул держит открытыми даже без нагрузки
+	MinConns int `yaml:"min_conns" env:"MIN_CONNS" env-default:"0"`
+	// MaxConnLifetime - время жизни соединения, после которого пул его пересоздает
+	MaxConnLifetime time.Duration `yaml:"max_conn_lifetime" env:"MAX_CONN_LIFETIME" env-default:"1h"`
+	// MaxConnIdleTime - сколько простаивающее соединение живет в пуле
+	MaxConnIdleTime   time.Duration `yaml:"max_conn_idle_time" env:"MAX_CONN_IDLE_TIME" env-default:"30m"`
+	HealthCheckPeriod time.Duration `yaml:"health_check_period" env:"HEALTH_CHECK_PERIOD" env-default:"1m"`
+	// QueryExecMode - режим выполнения запросов pgx: cache_statement, cache_describe,
+	// describe_exec, exec или simple_protocol (для PgBouncer в режиме transaction)
+	QueryExecMode string `yaml:"query_exec_mode" env:"QUERY_EXEC_MODE" env-default:"cache_statement"`
+	// StatementCacheCapacity - размер кеша подготовленных запросов на соединение
+	StatementCacheCapacity int `yaml:"statement_cache_capacity" env:"STATEMENT_CACHE_CAPACITY" env-default:"512"`
+	// StatsInterval - как часто писать статистику пула в лог (0 - не писать)
+	StatsInterval time.Duration `yaml:"stats_interval" env:"STATS_INTERVAL" env-default:"1m"`
+	// ReplicaDSNs - строки подключения к read-репликам (URL postgres://...).
+	// GetTaskByID и GetAllTasks читают с них, остальные запросы идут на primary.
+	ReplicaDSNs []string `yaml:"replica_dsns" env:"REPLICA_DSNS" env-separator:","`
+	// ReadYourWritesWindow - сколько после записи читать измененные данные с primary
+	ReadYourWritesWindow time.Duration `yaml:"read_your_writes_window" env:"READ_YOUR_WRITES_WINDOW" env-default:"5s"`
+	// ReplicaCheckInterval - как часто проверять доступность реплик
+	ReplicaCheckInterval time.Duration `yaml:"replica_check_interval" env:"REPLICA_CHECK_INTERVAL" env-default:"5s"`
 }
 
 // GRPCConfig содержит настройки gRPC сервера
 type GRPCConfig struct {
 	Host string `yaml:"host" env:"HOST" env-default:"0.0.0.0"`
 	Port int    `yaml:"port" env:"PORT" env-default:"50051"`
+	// Reflection включает gRPC server reflection (для grpcurl в dev)
+	Reflection bool      `yaml:"reflection" env:"REFLECTION" env-default:"false"`
+	TLS        TLSConfig `yaml:"tls" env-prefix:"TLS_"`
+	// KeepaliveMinTime - минимальный интервал keepalive ping от клиентов
+	KeepaliveMinTime time.Duration `yaml:"keepalive_min_time" env:"KEEPALIVE_MIN_TIME" env-default:"10s"`
+	// MaxConnectionAge - время жизни соединения, после которого клиент переподключается
+	// и заново распределяется по репликам (0 - без ограничения)
+	MaxConnectionAge      time.Duration `yaml:"max_connection_age" env:"MAX_CONNECTION_AGE" env-default:"0s"`
+	MaxConnectionAgeGrace time.Duration `yaml:"max_connection_age_grace" env:"MAX_CONNECTION_AGE_GRACE" env-default:"10s"`
 }
 
-// RedisConfig содержит настройки Redis (для будущего кэширования)
+// TLSConfig содержит настройки TLS/mTLS gRPC сервера
+type TLSConfig struct {
+	Enabled  bool   `yaml:"enabled" env:"ENABLED" env-default:"false"`
+	CertFile string `yaml:"cert_file" env:"CERT_FILE"`
+	KeyFile  string `yaml:"key_file" env:"KEY_FILE"`
+	// CAFile - CA для проверки клиентских сертификатов (mTLS)
+	CAFile string `yaml:"ca_file" env:"CA_FILE"`
+	// RequireClientCert требует от клиента сертификат, подписанный CAFile
+	RequireClientCert bool `yaml:"require_client_cert" env:"REQUIRE_CLIENT_CERT" env-default:"false"`
+	// ReloadInterval - как часто проверять файлы сертификатов на ротацию
+	ReloadInterval time.Duration `yaml:"reload_interval" env:"RELOAD_INTERVAL" env-default:"30s"`
+}
+
+// RedisConfig содержит настройки Redis и кеша задач
 type RedisConfig struct {
 	Host     string        `yaml:"host" env:"HOST" env-default:"localhost"`
 	Port     int           `yaml:"port" env:"PORT" env-default:"6379"`
@@ -51,6 +118,55 @@ type RedisConfig struct {
 	DB       int           `yaml:"db" env:"DB" env-default:"0"`
 	Enabled  bool          `yaml:"enabled" env:"ENABLED" env-default:"false"`
 	TTL      time.Duration `yaml:"ttl" env:"TTL" env-default:"60s"`
+	// LocalCacheSize - размер LRU в памяти процесса перед Redis (0 - без локального уровня)
+	LocalCacheSize int `yaml:"local_cache_size" env:"LOCAL_CACHE_SIZE" env-default:"1000"`
+	// LocalCacheTTL - сколько запись живет в LRU, если сообщение об инвалидации потерялось
+	LocalCacheTTL time.Duration `yaml:"local_cache_ttl" env:"LOCAL_CACHE_TTL" env-default:"10s"`
+	// InvalidationChannel - канал pub/sub для инвалидации LRU на других репликах
+	InvalidationChannel string `yaml:"invalidation_channel" env:"INVALIDATION_CHANNEL" env-default:"tasks:cache:invalidate"`
+	// NegativeTTL - сколько помнить, что задачи нет (0 - не кешировать отсутствие)
+	NegativeTTL time.Duration `yaml:"negative_ttl" env:"NEGATIVE_TTL" env-default:"5s"`
+	// StaleWhileRevalidate - сколько после TTL отдавать устаревшую задачу, обновляя ее в фоне
+	StaleWhileRevalidate time.Duration `yaml:"stale_while_revalidate" env:"STALE_WHILE_REVALIDATE" env-default:"30s"`
+}
+
+// HealthConfig содержит настройки проверки зависимостей для grpc.health.v1
+type HealthConfig struct {
+	Interval   time.Duration `yaml:"interval" env:"INTERVAL" env-default:"5s"`
+	Timeout    time.Duration `yaml:"timeout" env:"TIMEOUT" env-default:"2s"`
+	CheckRedis bool          `yaml:"check_redis" env:"CHECK_REDIS" env-default:"false"`
+}
+
+// MetricsConfig содержит настройки HTTP эндпоинта /metrics для Prometheus
+type MetricsConfig struct {
+	Enabled bool   `yaml:"enabled" env:"ENABLED" env-default:"true"`
+	Host    string `yaml:"host" env:"HOST" env-default:"0.0.0.0"`
+	Port    int    `yaml:"port" env:"PORT" env-default:"9090"`
+}
+
+// KafkaConfig содержит настройки Kafka для событий задач
+type KafkaConfig struct {
+	Brokers []string `yaml:"brokers" env:"BROKERS" env-separator:"," env-default:"localhost:9092"`
+	Topic   string   `yaml:"topic" env:"TOPIC" env-default:"task-events"`
+	// Codec - формат событий: json или protobuf (consumer выбирает по заголовку сообщения)
+	Codec string `yaml:"codec" env:"CODEC" env-default:"json"`
+	// SchemaRegistryPath - файл schema registry; пусто - схема не регистрируется
+	SchemaRegistryPath string `yaml:"schema_registry_path" env:"SCHEMA_REGISTRY_PATH"`
+}
+
+// OutboxConfig содержит настройки отправки событий из таблицы outbox в Kafka.
+// События пишутся в outbox хранилища всегда, Enabled включает relay.
+type OutboxConfig struct {
+	Enabled bool `yaml:"enabled" env:"ENABLED" env-default:"true"`
+	// PollInterval - пауза между проверками пустого outbox
+	PollInterval time.Duration `yaml:"poll_interval" env:"POLL_INTERVAL" env-default:"500ms"`
+	// BatchSize - сколько событий отправлять за раз
+	BatchSize int `yaml:"batch_size" env:"BATCH_SIZE" env-default:"100"`
+	// MaxBackoff - предельная пауза между повторами, пока Kafka недоступна
+	MaxBackoff time.Duration `yaml:"max_backoff" env:"MAX_BACKOFF" env-default:"30s"`
+	// MaxAttempts - после стольких неудачных отправок с постоянной ошибкой событие
+	// переносится в outbox_dead_letters; 0 - не переносить
+	MaxAttempts int `yaml:"max_attempts" env:"MAX_ATTEMPTS" env-default:"5"`
 }
 
 // Load загружает конфигурацию из файла и переменных окружения
@@ -119,6 +235,61 @@ func (c *DBConfig) DSNWithTimeout() string {
 	return fmt.Sprintf("%s connect_timeout=%d", c.DSN(), int(c.Timeout.Seconds()))
 }
 
+// queryExecModes - допустимые значения db.query_exec_mode
+var queryExecModes = map[string]pgx.QueryExecMode{
+	"cache_statement": pgx.QueryExecModeCacheStatement,
+	"cache_describe":  pgx.QueryExecModeCacheDescribe,
+	"describe_exec":   pgx.QueryExecModeDescribeExec,
+	"exec":            pgx.QueryExecModeExec,
+	"simple_protocol": pgx.QueryExecModeSimpleProtocol,
+}
+
+// PoolConfig возвращает настройки пула pgx: лимиты соединений, время жизни и кеш запросов
+func (c *DBConfig) PoolConfig() (*pgxpool.Config, error) {
+	return c.poolConfig(c.DSNWithTimeout())
+}
+
+// ReplicaPoolConfigs возвращает настройки пулов реплик с теми же лимитами, что у primary
+func (c *DBConfig) ReplicaPoolConfigs() ([]*pgxpool.Config, error) {
+	configs := make([]*pgxpool.Config, 0, len(c.ReplicaDSNs))
+	for i, dsn := range c.ReplicaDSNs {
+		poolCfg, err := c.poolConfig(dsn)
+		if err != nil {
+			return nil, fmt.Errorf("replica %d: %w", i, err)
+		}
+		configs = append(configs, poolCfg)
+	}
+	return configs, nil
+}
+
+func (c *DBConfig) poolConfig(dsn string) (*pgxpool.Config, error) {
+	poolCfg, err := pgxpool.ParseConfig(dsn)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse db config: %w", err)
+	}
+
+	poolCfg.MaxConns = int32(c.MaxConns)
+	poolCfg.MinConns = int32(c.MinConns)
+	poolCfg.MaxConnLifetime = c.MaxConnLifetime
+	poolCfg.MaxConnIdleTime = c.MaxConnIdleTime
+	poolCfg.HealthCheckPeriod = c.HealthCheckPeriod
+
+	mode, ok := queryExecModes[c.QueryExecMode]
+	if !ok {
+		return nil, fmt.Errorf("unknown db.query_exec_mode %q", c.QueryExecMode)
+	}
+	poolCfg.ConnConfig.DefaultQueryExecMode = mode
+	poolCfg.ConnConfig.StatementCacheCapacity = c.StatementCacheCapacity
+	poolCfg.ConnConfig.DescriptionCacheCapacity = c.StatementCacheCapacity
+
+	return poolCfg, nil
+}
+
+// Address возвращает адрес HTTP сервера метрик
+func (c *MetricsConfig) Address() string {
+	return fmt.Sprintf("%s:%d", c.Host, c.Port)
+}
+
 // Address возвращает адрес для gRPC сервера
 func (c *GRPCConfig) Address() string {
 	return fmt.Sprintf("%s:%d", c.Host, c.Port)
@@ -153,6 +324,13 @@ func (c *Config) Print() {
 	fmt.Printf("Debug: %v\n", c.App.Debug)
 	fmt.Println()
 
+	fmt.Println("=== Storage Configuration ===")
+	fmt.Printf("Driver: %s\n", c.Storage.Driver)
+	if c.Storage.Driver == StorageDriverSQLite {
+		fmt.Printf("SQLite Path: %s\n", c.Storage.SQLitePath)
+	}
+	fmt.Println()
+
 	fmt.Println("=== Database Configuration ===")
 	fmt.Printf("Host: %s\n", c.DB.Host)
 	fmt.Printf("Port: %d\n", c.DB.Port)
@@ -160,6 +338,15 @@ func (c *Config) Print() {
 	fmt.Printf("Database: %s\n", c.DB.Name)
 	fmt.Printf("SSL Mode: %s\n", c.DB.SSLMode)
 	fmt.Printf("Max Connections: %d\n", c.DB.MaxConns)
+	fmt.Printf("Min Connections: %d\n", c.DB.MinConns)
+	fmt.Printf("Max Conn Lifetime: %v\n", c.DB.MaxConnLifetime)
+	fmt.Printf("Max Conn Idle Time: %v\n", c.DB.MaxConnIdleTime)
+	fmt.Printf("Query Exec Mode: %s\n", c.DB.QueryExecMode)
+	fmt.Printf("Statement Cache Capacity: %d\n", c.DB.StatementCacheCapacity)
+	fmt.Printf("Replicas: %d\n", len(c.DB.ReplicaDSNs))
+	if len(c.DB.ReplicaDSNs) > 0 {
+		fmt.Printf("Read Your Writes Window: %v\n", c.DB.ReadYourWritesWindow)
+	}
 	fmt.Printf("Timeout: %v\n", c.DB.Timeout)
 	fmt.Println()
 
@@ -167,6 +354,15 @@ func (c *Config) Print() {
 	fmt.Printf("Host: %s\n", c.GRPC.Host)
 	fmt.Printf("Port: %d\n", c.GRPC.Port)
 	fmt.Printf("Address: %s\n", c.GRPC.Address())
+	fmt.Printf("Reflection: %v\n", c.GRPC.Reflection)
+	fmt.Printf("Keepalive Min Time: %v\n", c.GRPC.KeepaliveMinTime)
+	fmt.Printf("Max Connection Age: %v\n", c.GRPC.MaxConnectionAge)
+	fmt.Printf("TLS: %v\n", c.GRPC.TLS.Enabled)
+	if c.GRPC.TLS.Enabled {
+		fmt.Printf("TLS Cert File: %s\n", c.GRPC.TLS.CertFile)
+		fmt.Printf("TLS CA File: %s\n", c.GRPC.TLS.CAFile)
+		fmt.Printf("TLS Require Client Cert: %v\n", c.GRPC.TLS.RequireClientCert)
+	}
 	fmt.Println()
 
 	fmt.Println("=== Redis Configuration ===")
@@ -175,6 +371,36 @@ func (c *Config) Print() {
 	fmt.Printf("Enabled: %v\n", c.Redis.Enabled)
 	if c.Redis.Enabled {
 		fmt.Printf("DB: %d\n", c.Redis.DB)
+		fmt.Printf("TTL: %v\n", c.Redis.TTL)
+		fmt.Printf("Local Cache Size: %d\n", c.Redis.LocalCacheSize)
+		fmt.Printf("Local Cache TTL: %v\n", c.Redis.LocalCacheTTL)
+		fmt.Printf("Negative TTL: %v\n", c.Redis.NegativeTTL)
+		fmt.Printf("Stale While Revalidate: %v\n", c.Redis.StaleWhileRevalidate)
+	}
+	fmt.Println()
+
+	fmt.Println("=== Health Configuration ===")
+	fmt.Printf("Interval: %v\n", c.Health.Interval)
+	fmt.Printf("Timeout: %v\n", c.Health.Timeout)
+	fmt.Printf("Check Redis: %v\n", c.Health.CheckRedis)
+	fmt.Println()
+
+	fmt.Println("=== Metrics Configuration ===")
+	fmt.Printf("Enabled: %v\n", c.Metrics.Enabled)
+	if c.Metrics.Enabled {
+		fmt.Printf("Address: %s\n", c.Metrics.Address())
+	}
+	fmt.Println()
+
+	fmt.Println("=== Outbox Configuration ===")
+	fmt.Printf("Enabled: %v\n", c.Outbox.Enabled)
+	if c.Outbox.Enabled {
+		fmt.Printf("Kafka Brokers: %s\n", strings.Join(c.Kafka.Brokers, ","))
+		fmt.Printf("Kafka Topic: %s\n", c.Kafka.Topic)
+		fmt.Printf("Kafka Codec: %s\n", c.Kafka.Codec)
+		fmt.Printf("Poll Interval: %v\n", c.Outbox.PollInterval)
+		fmt.Printf("Batch Size: %d\n", c.Outbox.BatchSize)
+		fmt.Printf("Max Attempts: %d\n", c.Outbox.MaxAttempts)
 	}
 	fmt.Println("============================")
 }
@@ -188,18 +414,51 @@ func (c *Config) Validate() error {
 		errors = append(errors, "app.name is required")
 	}
 
-	// Проверка DB
-	if c.DB.Host == "" {
-		errors = append(errors, "db.host is required")
-	}
-	if c.DB.Port <= 0 || c.DB.Port > 65535 {
-		errors = append(errors, "db.port must be between 1 and 65535")
-	}
-	if c.DB.User == "" {
-		errors = append(errors, "db.user is required")
+	// Проверка Storage
+	switch c.Storage.Driver {
+	case StorageDriverPostgres, StorageDriverMemory:
+	case StorageDriverSQLite:
+		if c.Storage.SQLitePath == "" {
+			errors = append(errors, "storage.sqlite_path is required for sqlite driver")
+		}
+	default:
+		errors = append(errors, "storage.driver must be one of postgres, sqlite, memory")
 	}
-	if c.DB.Name == "" {
-		errors = append(errors, "db.name is required")
+
+	// Проверка DB (только для PostgreSQL)
+	if c.Storage.Driver == StorageDriverPostgres {
+		if c.DB.Host == "" {
+			errors = append(errors, "db.host is required")
+		}
+		if c.DB.Port <= 0 || c.DB.Port > 65535 {
+			errors = append(errors, "db.port must be between 1 and 65535")
+		}
+		if c.DB.User == "" {
+			errors = append(errors, "db.user is required")
+		}
+		if c.DB.Name == "" {
+			errors = append(errors, "db.name is required")
+		}
+		if c.DB.MaxConns <= 0 {
+			errors = append(errors, "db.max_conns must be positive")
+		}
+		if c.DB.MinConns < 0 || c.DB.MinConns > c.DB.MaxConns {
+			errors = append(errors, "db.min_conns must be between 0 and db.max_conns")
+		}
+		if _, ok := queryExecModes[c.DB.QueryExecMode]; !ok {
+			errors = append(errors, "db.query_exec_mode must be one of cache_statement, cache_describe, describe_exec, exec, simple_protocol")
+		}
+		if c.DB.StatementCacheCapacity < 0 {
+			errors = append(errors, "db.statement_cache_capacity must not be negative")
+		}
+		if len(c.DB.ReplicaDSNs) > 0 {
+			if c.DB.ReadYourWritesWindow < 0 {
+				errors = append(errors, "db.read_your_writes_window must not be negative")
+			}
+			if c.DB.ReplicaCheckInterval <= 0 {
+				errors = append(errors, "db.replica_check_interval must be positive")
+			}
+		}
 	}
 
 	// Проверка GRPC
@@ -207,6 +466,72 @@ func (c *Config) Validate() error {
 		errors = append(errors, "grpc.port must be between 1 and 65535")
 	}
 
+	if c.GRPC.TLS.Enabled {
+		if c.GRPC.TLS.CertFile == "" || c.GRPC.TLS.KeyFile == "" {
+			errors = append(errors, "grpc.tls.cert_file and grpc.tls.key_file are required when tls is enabled")
+		}
+		if c.GRPC.TLS.RequireClientCert && c.GRPC.TLS.CAFile == "" {
+			errors = append(errors, "grpc.tls.ca_file is required when client certificates are required")
+		}
+		if c.GRPC.TLS.ReloadInterval <= 0 {
+			errors = append(errors, "grpc.tls.reload_interval must be positive")
+		}
+	}
+
+	// Проверка Redis
+	if c.Redis.NegativeTTL < 0 {
+		errors = append(errors, "redis.negative_ttl must not be negative")
+	}
+	if c.Redis.StaleWhileRevalidate < 0 {
+		errors = append(errors, "redis.stale_while_revalidate must not be negative")
+	}
+	if c.Redis.Enabled && c.Redis.LocalCacheSize > 0 {
+		if c.Redis.LocalCacheTTL <= 0 {
+			errors = append(errors, "redis.local_cache_ttl must be positive when local cache is enabled")
+		}
+		if c.Redis.InvalidationChannel == "" {
+			errors = append(errors, "redis.invalidation_channel is required when local cache is enabled")
+		}
+	}
+
+	// Проверка Metrics
+	if c.Metrics.Enabled && (c.Metrics.Port <= 0 || c.Metrics.Port > 65535) {
+		errors = append(errors, "metrics.port must be between 1 and 65535")
+	}
+
+	// Проверка Outbox
+	if c.Outbox.Enabled {
+		if len(c.Kafka.Brokers) == 0 {
+			errors = append(errors, "kafka.brokers is required when outbox relay is enabled")
+		}
+		if c.Kafka.Topic == "" {
+			errors = append(errors, "kafka.topic is required when outbox relay is enabled")
+		}
+		if _, err := kafka.CodecByName(c.Kafka.Codec); err != nil {
+			errors = append(errors, err.Error())
+		}
+		if c.Outbox.PollInterval <= 0 {
+			errors = append(errors, "outbox.poll_interval must be positive")
+		}
+		if c.Outbox.BatchSize <= 0 {
+			errors = append(errors, "outbox.batch_size must be positive")
+		}
+		if c.Outbox.MaxBackoff < c.Outbox.PollInterval {
+			errors = append(errors, "outbox.max_backoff must not be less than outbox.poll_interval")
+		}
+		if c.Outbox.MaxAttempts < 0 {
+			errors = append(errors, "outbox.max_attempts must not be negative")
+		}
+	}
+
+	// Проверка Health
+	if c.Health.Interval <= 0 {
+		errors = append(errors, "health.interval must be positive")
+	}
+	if c.Health.Timeout <= 0 {
+		errors = append(errors, "health.timeout must be positive")
+	}
+
 	if len(errors) > 0 {
 		return fmt.Errorf("configuration validation failed: %s", strings.Join(errors, ", "))
 	}
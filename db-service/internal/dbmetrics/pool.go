@@ -0,0 +1,121 @@
+// Package dbmetrics публикует статистику пула соединений PostgreSQL в Prometheus и в лог
+package dbmetrics
+
+import (
+	"context"
+	"time"
+
+	"github.com/N0F1X3d/todo/pkg/logger"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// PoolCollector снимает pgxpool.Stat при каждом scrape
+type PoolCollector struct {
+	pool *pgxpool.Pool
+
+	acquiredConns     *prometheus.Desc
+	idleConns         *prometheus.Desc
+	constructingConns *prometheus.Desc
+	totalConns        *prometheus.Desc
+	maxConns          *prometheus.Desc
+
+	acquires         *prometheus.Desc
+	acquireSeconds   *prometheus.Desc
+	emptyAcquires    *prometheus.Desc
+	canceledAcquires *prometheus.Desc
+	newConns         *prometheus.Desc
+	lifetimeDestroys *prometheus.Desc
+	idleDestroys     *prometheus.Desc
+}
+
+// NewPoolCollector создает коллектор для пула pool
+func NewPoolCollector(pool *pgxpool.Pool) *PoolCollector {
+	desc := func(name, help string) *prometheus.Desc {
+		return prometheus.NewDesc("db_pool_"+name, help, nil, nil)
+	}
+
+	return &PoolCollector{
+		pool: pool,
+
+		acquiredConns:     desc("acquired_conns", "Connections currently in use."),
+		idleConns:         desc("idle_conns", "Idle connections in the pool."),
+		constructingConns: desc("constructing_conns", "Connections being established."),
+		totalConns:        desc("total_conns", "Total connections in the pool."),
+		maxConns:          desc("max_conns", "Maximum size of the pool."),
+
+		acquires:         desc("acquires_total", "Successful connection acquires."),
+		acquireSeconds:   desc("acquire_duration_seconds_total", "Total time spent acquiring connections."),
+		emptyAcquires:    desc("empty_acquires_total", "Acquires that had to wait for a connection."),
+		canceledAcquires: desc("canceled_acquires_total", "Acquires canceled by context."),
+		newConns:         desc("new_conns_total", "Connections opened by the pool."),
+		lifetimeDestroys: desc("max_lifetime_destroys_total", "Connections closed because of max_conn_lifetime."),
+		idleDestroys:     desc("max_idle_destroys_total", "Connections closed because of max_conn_idle_time."),
+	}
+}
+
+// Describe реализует prometheus.Collector
+func (c *PoolCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.acquiredConns
+	ch <- c.idleConns
+	ch <- c.constructingConns
+	ch <- c.totalConns
+	ch <- c.maxConns
+	ch <- c.acquires
+	ch <- c.acquireSeconds
+	ch <- c.emptyAcquires
+	ch <- c.canceledAcquires
+	ch <- c.newConns
+	ch <- c.lifetimeDestroys
+	ch <- c.idleDestroys
+}
+
+// Collect реализует prometheus.Collector
+func (c *PoolCollector) Collect(ch chan<- prometheus.Metric) {
+	stat := c.pool.Stat()
+
+	gauge := func(desc *prometheus.Desc, value float64) {
+		ch <- prometheus.MustNewConstMetric(desc, prometheus.GaugeValue, value)
+	}
+	counter := func(desc *prometheus.Desc, value float64) {
+		ch <- prometheus.MustNewConstMetric(desc, prometheus.CounterValue, value)
+	}
+
+	gauge(c.acquiredConns, float64(stat.AcquiredConns()))
+	gauge(c.idleConns, float64(stat.IdleConns()))
+	gauge(c.constructingConns, float64(stat.ConstructingConns()))
+	gauge(c.totalConns, float64(stat.TotalConns()))
+	gauge(c.maxConns, float64(stat.MaxConns()))
+
+	counter(c.acquires, float64(stat.AcquireCount()))
+	counter(c.acquireSeconds, stat.AcquireDuration().Seconds())
+	counter(c.emptyAcquires, float64(stat.EmptyAcquireCount()))
+	counter(c.canceledAcquires, float64(stat.CanceledAcquireCount()))
+	counter(c.newConns, float64(stat.NewConnsCount()))
+	counter(c.lifetimeDestroys, float64(stat.MaxLifetimeDestroyCount()))
+	counter(c.idleDestroys, float64(stat.MaxIdleDestroyCount()))
+}
+
+// LogStats пишет статистику пула в лог каждые interval, пока не отменен ctx
+func LogStats(ctx context.Context, pool *pgxpool.Pool, interval time.Duration, log *logger.Logger) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			stat := pool.Stat()
+			log.Info("db pool stats",
+				"acquired_conns", stat.AcquiredConns(),
+				"idle_conns", stat.IdleConns(),
+				"total_conns", stat.TotalConns(),
+				"max_conns", stat.MaxConns(),
+				"acquire_count", stat.AcquireCount(),
+				"empty_acquire_count", stat.EmptyAcquireCount(),
+				"acquire_duration_ms", stat.AcquireDuration().Milliseconds(),
+			)
+		}
+	}
+}
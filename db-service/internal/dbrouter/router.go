@@ -0,0 +1,242 @@
+// Package dbrouter выбирает реплику PostgreSQL для чтения.
+//
+// Чтение уходит на primary, если этот же клиент недавно писал (read-your-writes): задачу,
+// которую он менял, и список задач в течение окна после его записи. Клиент определяется
+// по identity сертификата и пользователю, от имени которого она действует (x-actor),
+// см. server.EventMetaInterceptor: x-actor не проверяется, поэтому различает клиентов
+// только внутри одной identity. Клиенты без identity и x-actor считаются одним клиентом. Записи помнит только этот процесс:
+// за несколькими репликами db-service клиенту нужна привязка к инстансу.
+package dbrouter
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/N0F1X3d/todo/pkg/kafka"
+	"github.com/N0F1X3d/todo/pkg/logger"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// pruneThreshold - после скольких записей чистить устаревшие отметки из recentWrites
+const pruneThreshold = 1024
+
+type replica struct {
+	name    string
+	pool    *pgxpool.Pool
+	healthy atomic.Bool
+}
+
+// writes - недавние записи одного клиента
+type writes struct {
+	// last - последняя запись клиента, включая MarkWriteAll
+	last  time.Time
+	tasks map[int]time.Time
+}
+
+// Router распределяет чтения по здоровым репликам round robin
+type Router struct {
+	replicas []*replica
+	next     atomic.Uint64
+	window   time.Duration
+	log      *logger.Logger
+
+	mu           sync.Mutex
+	recentWrites map[string]*writes
+	// lastWrite - последняя запись любого клиента
+	lastWrite time.Time
+	marks     int
+}
+
+// New создает роутер поверх пулов реплик. Реплики считаются здоровыми,
+// пока CheckReplicas или неудачное чтение не покажут обратное.
+// window - сколько после записи читать с primary.
+func New(pools []*pgxpool.Pool, window time.Duration, log *logger.Logger) *Router {
+	r := &Router{
+		window:       window,
+		log:          log.WithComponent("dbrouter").WithFunction("Router"),
+		recentWrites: make(map[string]*writes),
+	}
+
+	for _, pool := range pools {
+		connCfg := pool.Config().ConnConfig
+		rep := &replica{
+			name: fmt.Sprintf("%s:%d", connCfg.Host, connCfg.Port),
+			pool: pool,
+		}
+		rep.healthy.Store(true)
+		r.replicas = append(r.replicas, rep)
+	}
+
+	return r
+}
+
+// caller возвращает клиента запроса для read-your-writes
+func caller(ctx context.Context) string {
+	meta := kafka.EventMetaFromContext(ctx)
+	return meta.Actor + "\x00" + meta.ForwardedActor
+}
+
+// Reader возвращает реплику для чтения задачи id или nil, если читать нужно с primary
+func (r *Router) Reader(ctx context.Context, id int) *pgxpool.Pool {
+	r.mu.Lock()
+	var written time.Time
+	if w, ok := r.recentWrites[caller(ctx)]; ok {
+		written = w.tasks[id]
+	}
+	r.mu.Unlock()
+
+	if time.Since(written) < r.window {
+		return nil
+	}
+	return r.pick()
+}
+
+// ListReader возвращает реплику для чтения списка задач или nil, если читать нужно с primary
+func (r *Router) ListReader(ctx context.Context) *pgxpool.Pool {
+	r.mu.Lock()
+	var lastWrite time.Time
+	if w, ok := r.recentWrites[caller(ctx)]; ok {
+		lastWrite = w.last
+	}
+	r.mu.Unlock()
+
+	if time.Since(lastWrite) < r.window {
+		return nil
+	}
+	return r.pick()
+}
+
+// MarkWrite отмечает запись задачи id клиентом из ctx: ближайшие window его чтения
+// этой задачи и списка пойдут на primary
+func (r *Router) MarkWrite(ctx context.Context, id int) {
+	now := time.Now()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	w := r.writesOf(caller(ctx))
+	w.last = now
+	w.tasks[id] = now
+	r.lastWrite = now
+	r.prune(now)
+}
+
+// MarkWriteAll отмечает запись клиентом из ctx, id которой неизвестны (например, COPY)
+func (r *Router) MarkWriteAll(ctx context.Context) {
+	now := time.Now()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.writesOf(caller(ctx)).last = now
+	r.lastWrite = now
+	r.prune(now)
+}
+
+// Settled сообщает, что за window никто не писал: прочитанное с реплики можно
+// отдавать другим клиентам (например, класть в общий кеш)
+func (r *Router) Settled() bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return time.Since(r.lastWrite) >= r.window
+}
+
+// writesOf возвращает записи клиента, создавая их при первой записи. Вызывается под r.mu.
+func (r *Router) writesOf(name string) *writes {
+	w, ok := r.recentWrites[name]
+	if !ok {
+		w = &writes{tasks: make(map[int]time.Time)}
+		r.recentWrites[name] = w
+	}
+	return w
+}
+
+// prune раз в pruneThreshold записей удаляет отметки старше window. Вызывается под r.mu.
+func (r *Router) prune(now time.Time) {
+	r.marks++
+	if r.marks < pruneThreshold {
+		return
+	}
+	r.marks = 0
+
+	for name, w := range r.recentWrites {
+		if now.Sub(w.last) >= r.window {
+			delete(r.recentWrites, name)
+			continue
+		}
+		for id, written := range w.tasks {
+			if now.Sub(written) >= r.window {
+				delete(w.tasks, id)
+			}
+		}
+	}
+}
+
+// MarkUnhealthy исключает реплику из чтения до следующей успешной проверки
+func (r *Router) MarkUnhealthy(pool *pgxpool.Pool) {
+	for _, rep := range r.replicas {
+		if rep.pool == pool && rep.healthy.Swap(false) {
+			r.log.Warn("replica marked unhealthy", "replica", rep.name)
+		}
+	}
+}
+
+// CheckReplicas пингует реплики и обновляет их состояние
+func (r *Router) CheckReplicas(ctx context.Context, timeout time.Duration) {
+	for _, rep := range r.replicas {
+		checkCtx, cancel := context.WithTimeout(ctx, timeout)
+		err := rep.pool.Ping(checkCtx)
+		cancel()
+
+		healthy := err == nil
+		if rep.healthy.Swap(healthy) != healthy {
+			if healthy {
+				r.log.Info("replica is healthy again", "replica", rep.name)
+			} else {
+				r.log.Warn("replica is unhealthy, reading from primary", "replica", rep.name, "error", err)
+			}
+		}
+	}
+}
+
+// Run проверяет реплики каждые interval, пока не отменен ctx
+func (r *Router) Run(ctx context.Context, interval, timeout time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			r.CheckReplicas(ctx, timeout)
+		}
+	}
+}
+
+// Close закрывает пулы реплик
+func (r *Router) Close() {
+	for _, rep := range r.replicas {
+		rep.pool.Close()
+	}
+}
+
+// pick возвращает следующую здоровую реплику или nil, если здоровых нет
+func (r *Router) pick() *pgxpool.Pool {
+	n := uint64(len(r.replicas))
+	if n == 0 {
+		return nil
+	}
+
+	start := r.next.Add(1)
+	for i := uint64(0); i < n; i++ {
+		rep := r.replicas[(start+i)%n]
+		if rep.healthy.Load() {
+			return rep.pool
+		}
+	}
+	return nil
+}
@@ -0,0 +1,111 @@
+package health
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/N0F1X3d/todo/pkg/logger"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+// CheckFunc проверяет доступность одной зависимости (Postgres, Redis и т.д.)
+type CheckFunc func(ctx context.Context) error
+
+type namedCheck struct {
+	name  string
+	check CheckFunc
+}
+
+// Checker периодически проверяет зависимости сервиса и выставляет
+// статус в стандартном grpc.health.v1.Health сервере
+type Checker struct {
+	server   *health.Server
+	services []string
+	interval time.Duration
+	timeout  time.Duration
+	log      *logger.Logger
+
+	mu     sync.Mutex
+	checks []namedCheck
+}
+
+// NewChecker создает Checker для переданного health-сервера.
+// services - имена gRPC сервисов, статус которых нужно обновлять
+// (пустое имя "" означает общий статус сервера).
+func NewChecker(server *health.Server, services []string, interval, timeout time.Duration, log *logger.Logger) *Checker {
+	return &Checker{
+		server:   server,
+		services: services,
+		interval: interval,
+		timeout:  timeout,
+		log:      log.WithComponent("health").WithFunction("Checker"),
+	}
+}
+
+// AddCheck регистрирует проверку зависимости
+func (c *Checker) AddCheck(name string, check CheckFunc) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.checks = append(c.checks, namedCheck{name: name, check: check})
+}
+
+// Check выполняет все проверки один раз и обновляет статус сервисов.
+// Возвращает true, если все зависимости доступны.
+func (c *Checker) Check(ctx context.Context) bool {
+	const op = "Check"
+
+	c.mu.Lock()
+	checks := make([]namedCheck, len(c.checks))
+	copy(checks, c.checks)
+	c.mu.Unlock()
+
+	serving := true
+	for _, nc := range checks {
+		checkCtx, cancel := context.WithTimeout(ctx, c.timeout)
+		err := nc.check(checkCtx)
+		cancel()
+
+		if err != nil {
+			c.log.ErrorWithContext("dependency check failed", err, op, "dependency", nc.name)
+			serving = false
+		}
+	}
+
+	status := healthpb.HealthCheckResponse_SERVING
+	if !serving {
+		status = healthpb.HealthCheckResponse_NOT_SERVING
+	}
+
+	for _, service := range c.services {
+		c.server.SetServingStatus(service, status)
+	}
+
+	return serving
+}
+
+// Run выполняет проверки сразу и затем с интервалом interval до отмены ctx
+func (c *Checker) Run(ctx context.Context) {
+	c.Check(ctx)
+
+	ticker := time.NewTicker(c.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			c.Check(ctx)
+		}
+	}
+}
+
+// Shutdown переводит все сервисы в NOT_SERVING.
+// После вызова дальнейшие обновления статуса игнорируются.
+func (c *Checker) Shutdown() {
+	c.server.Shutdown()
+	c.log.Info("health status set to NOT_SERVING")
+}
@@ -0,0 +1,102 @@
+package health_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/N0F1X3d/todo/db-service/internal/health"
+	"github.com/N0F1X3d/todo/pkg/logger"
+	"github.com/stretchr/testify/assert"
+	grpchealth "google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+const testService = "proto.TaskService"
+
+func newTestChecker() (*health.Checker, *grpchealth.Server) {
+	testLogger := logger.New("db-service", "test-logs")
+	server := grpchealth.NewServer()
+	checker := health.NewChecker(server, []string{"", testService}, time.Second, time.Second, testLogger)
+	return checker, server
+}
+
+func servingStatus(t *testing.T, server *grpchealth.Server, service string) healthpb.HealthCheckResponse_ServingStatus {
+	resp, err := server.Check(context.Background(), &healthpb.HealthCheckRequest{Service: service})
+	assert.NoError(t, err)
+	return resp.GetStatus()
+}
+
+func TestChecker_Check_AllHealthy(t *testing.T) {
+	checker, server := newTestChecker()
+	checker.AddCheck("postgres", func(ctx context.Context) error { return nil })
+	checker.AddCheck("redis", func(ctx context.Context) error { return nil })
+
+	ok := checker.Check(context.Background())
+
+	assert.True(t, ok)
+	assert.Equal(t, healthpb.HealthCheckResponse_SERVING, servingStatus(t, server, ""))
+	assert.Equal(t, healthpb.HealthCheckResponse_SERVING, servingStatus(t, server, testService))
+}
+
+func TestChecker_Check_DependencyDown(t *testing.T) {
+	checker, server := newTestChecker()
+	checker.AddCheck("postgres", func(ctx context.Context) error { return errors.New("connection refused") })
+	checker.AddCheck("redis", func(ctx context.Context) error { return nil })
+
+	ok := checker.Check(context.Background())
+
+	assert.False(t, ok)
+	assert.Equal(t, healthpb.HealthCheckResponse_NOT_SERVING, servingStatus(t, server, ""))
+	assert.Equal(t, healthpb.HealthCheckResponse_NOT_SERVING, servingStatus(t, server, testService))
+}
+
+func TestChecker_Check_Recovers(t *testing.T) {
+	checker, server := newTestChecker()
+	healthy := false
+	checker.AddCheck("postgres", func(ctx context.Context) error {
+		if !healthy {
+			return errors.New("connection refused")
+		}
+		return nil
+	})
+
+	checker.Check(context.Background())
+	assert.Equal(t, healthpb.HealthCheckResponse_NOT_SERVING, servingStatus(t, server, testService))
+
+	healthy = true
+	checker.Check(context.Background())
+	assert.Equal(t, healthpb.HealthCheckResponse_SERVING, servingStatus(t, server, testService))
+}
+
+func TestChecker_Check_Timeout(t *testing.T) {
+	testLogger := logger.New("db-service", "test-logs")
+	server := grpchealth.NewServer()
+	checker := health.NewChecker(server, []string{""}, time.Second, 10*time.Millisecond, testLogger)
+	checker.AddCheck("postgres", func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	})
+
+	ok := checker.Check(context.Background())
+
+	assert.False(t, ok)
+	assert.Equal(t, healthpb.HealthCheckResponse_NOT_SERVING, servingStatus(t, server, ""))
+}
+
+func TestChecker_Shutdown(t *testing.T) {
+	checker, server := newTestChecker()
+	checker.AddCheck("postgres", func(ctx context.Context) error { return nil })
+
+	checker.Check(context.Background())
+	assert.Equal(t, healthpb.HealthCheckResponse_SERVING, servingStatus(t, server, ""))
+
+	checker.Shutdown()
+	assert.Equal(t, healthpb.HealthCheckResponse_NOT_SERVING, servingStatus(t, server, ""))
+	assert.Equal(t, healthpb.HealthCheckResponse_NOT_SERVING, servingStatus(t, server, testService))
+
+	// После Shutdown статус больше не возвращается в SERVING
+	checker.Check(context.Background())
+	assert.Equal(t, healthpb.HealthCheckResponse_NOT_SERVING, servingStatus(t, server, ""))
+}
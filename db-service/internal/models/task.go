@@ -4,6 +4,7 @@ import "time"
 
 type Task struct {
 	ID          int       `json:"id"`
+	UUID        string    `json:"uuid,omitempty"`
 	Title       string    `json:"title"`
 	Description string    `json:"description"`
 	Completed   bool      `json:"completed"`
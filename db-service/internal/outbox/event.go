@@ -0,0 +1,47 @@
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+	"time"
+
+	"github.com/N0F1X3d/todo/db-service/internal/models"
+	"github.com/N0F1X3d/todo/pkg/kafka"
+)
+
+// NewEvent собирает событие об изменении задачи для записи в outbox: ключ - id задачи,
+// payload - событие в JSON. Метаданные запроса (actor, request/trace id) берутся из ctx.
+func NewEvent(ctx context.Context, action string, before, after *models.Task, requestTime time.Time) (string, []byte, error) {
+	task := after
+	if task == nil {
+		task = before
+	}
+
+	event := kafka.NewTaskEvent(ctx, action, requestTime)
+	event.TaskID = task.ID
+	event.Before = taskSnapshot(before)
+	event.After = taskSnapshot(after)
+
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return "", nil, err
+	}
+	return strconv.Itoa(task.ID), payload, nil
+}
+
+// taskSnapshot конвертирует задачу в снимок для события
+func taskSnapshot(task *models.Task) *kafka.TaskSnapshot {
+	if task == nil {
+		return nil
+	}
+	return &kafka.TaskSnapshot{
+		ID:          task.ID,
+		UUID:        task.UUID,
+		Title:       task.Title,
+		Description: task.Description,
+		Completed:   task.Completed,
+		CreatedAt:   task.CreatedAt,
+		UpdatedAt:   task.UpdatedAt,
+	}
+}
@@ -0,0 +1,74 @@
+package outbox
+
+import (
+	"context"
+	"sync"
+)
+
+// MemoryStore - outbox в памяти процесса для хранилища задач memory.
+// События теряются при перезапуске вместе с задачами.
+type MemoryStore struct {
+	mu     sync.Mutex
+	msgs   []Message
+	dead   []Message
+	nextID int64
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{nextID: 1}
+}
+
+// Add ставит событие в конец outbox
+func (s *MemoryStore) Add(key string, payload []byte) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.msgs = append(s.msgs, Message{ID: s.nextID, Key: key, Payload: payload})
+	s.nextID++
+}
+
+// Pending возвращает неотправленные сообщения по порядку
+func (s *MemoryStore) Pending() []Message {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]Message(nil), s.msgs...)
+}
+
+// DeadLetters возвращает сообщения, перенесенные через DeadLetter
+func (s *MemoryStore) DeadLetters() []Message {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]Message(nil), s.dead...)
+}
+
+func (s *MemoryStore) Process(_ context.Context, limit int, fn func([]Message) error) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	batch := s.msgs[:min(limit, len(s.msgs))]
+	if len(batch) == 0 {
+		return 0, nil
+	}
+	if err := fn(append([]Message(nil), batch...)); err != nil {
+		for i := range batch {
+			s.msgs[i].Attempts++
+		}
+		return len(batch), err
+	}
+	s.msgs = s.msgs[len(batch):]
+	return len(batch), nil
+}
+
+func (s *MemoryStore) DeadLetter(_ context.Context, id int64, _ string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i, msg := range s.msgs {
+		if msg.ID == id {
+			s.dead = append(s.dead, msg)
+			s.msgs = append(s.msgs[:i:i], s.msgs[i+1:]...)
+			return nil
+		}
+	}
+	return nil
+}
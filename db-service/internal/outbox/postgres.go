@@ -0,0 +1,113 @@
+package outbox
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// relayLockID - ключ advisory lock. Outbox в каждый момент разбирает одна реплика
+// db-service: иначе события одной задачи могли бы уйти в брокер не по порядку.
+const relayLockID int64 = 0x6f7574626f78
+
+// PostgresStore - outbox в таблице PostgreSQL
+type PostgresStore struct {
+	pool *pgxpool.Pool
+}
+
+// NewPostgresStore создает хранилище outbox поверх пула
+func NewPostgresStore(pool *pgxpool.Pool) *PostgresStore {
+	return &PostgresStore{pool: pool}
+}
+
+// Process выполняет fn в транзакции, держащей advisory lock. Если lock занят
+// другой репликой, возвращает 0 без ошибки.
+func (s *PostgresStore) Process(ctx context.Context, limit int, fn func([]Message) error) (int, error) {
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return 0, err
+	}
+	// После commit откат ничего не делает
+	defer func() { _ = tx.Rollback(context.Background()) }()
+
+	var locked bool
+	if err := tx.QueryRow(ctx, `SELECT pg_try_advisory_xact_lock($1)`, relayLockID).Scan(&locked); err != nil {
+		return 0, err
+	}
+	if !locked {
+		return 0, nil
+	}
+
+	rows, err := tx.Query(ctx, `SELECT id, aggregate_key, payload, attempts FROM outbox ORDER BY id LIMIT $1`, limit)
+	if err != nil {
+		return 0, err
+	}
+	msgs, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (Message, error) {
+		var msg Message
+		err := row.Scan(&msg.ID, &msg.Key, &msg.Payload, &msg.Attempts)
+		return msg, err
+	})
+	if err != nil {
+		return 0, err
+	}
+	if len(msgs) == 0 {
+		return 0, nil
+	}
+
+	ids := make([]int64, 0, len(msgs))
+	for _, msg := range msgs {
+		ids = append(ids, msg.ID)
+	}
+
+	if fnErr := fn(msgs); fnErr != nil {
+		_, err := tx.Exec(context.Background(),
+			`UPDATE outbox SET attempts = attempts + 1, last_error = $2 WHERE id = ANY($1)`,
+			ids, fnErr.Error(),
+		)
+		if err == nil {
+			err = tx.Commit(context.Background())
+		}
+		if err != nil {
+			return len(msgs), err
+		}
+		return len(msgs), fnErr
+	}
+
+	// Если удаление не закоммитится, сообщения отправятся повторно
+	if _, err := tx.Exec(ctx, `DELETE FROM outbox WHERE id = ANY($1)`, ids); err != nil {
+		return len(msgs), err
+	}
+	return len(msgs), tx.Commit(ctx)
+}
+
+// DeadLetter переносит сообщение в outbox_dead_letters под тем же advisory lock,
+// что и Process. Если lock занят, ничего не делает: relay повторит позже.
+func (s *PostgresStore) DeadLetter(ctx context.Context, id int64, reason string) error {
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback(context.Background()) }()
+
+	var locked bool
+	if err := tx.QueryRow(ctx, `SELECT pg_try_advisory_xact_lock($1)`, relayLockID).Scan(&locked); err != nil {
+		return err
+	}
+	if !locked {
+		return nil
+	}
+
+	if _, err := tx.Exec(ctx,
+		`INSERT INTO outbox_dead_letters (id, aggregate_key, payload, attempts, last_error, created_at)
+		 SELECT id, aggregate_key, payload, attempts, $2, created_at FROM outbox WHERE id = $1
+		 ON CONFLICT (id) DO NOTHING`,
+		id, reason,
+	); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(ctx, `DELETE FROM outbox WHERE id = $1`, id); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
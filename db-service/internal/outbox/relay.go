@@ -0,0 +1,179 @@
+// Package outbox доставляет события задач в Kafka через transactional outbox.
+//
+// Репозиторий пишет событие в таблицу outbox той же транзакцией, что и изменение
+// задачи, поэтому событие не теряется при падении процесса после commit. Relay
+// отправляет накопленные строки по порядку id и удаляет их только после
+// подтверждения брокера: доставка at-least-once, при повторе возможны дубли.
+// Ключ сообщения - id задачи, так что события одной задачи попадают в одну
+// партицию в порядке записи.
+package outbox
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/N0F1X3d/todo/pkg/kafka"
+	"github.com/N0F1X3d/todo/pkg/logger"
+	kafkago "github.com/segmentio/kafka-go"
+)
+
+// Message - событие, ожидающее отправки
+type Message struct {
+	ID       int64
+	Key      string
+	Payload  []byte
+	Attempts int
+}
+
+// Store - хранилище outbox
+type Store interface {
+	// Process передает fn до limit самых старых сообщений. Если fn вернула nil,
+	// сообщения удаляются, иначе у них увеличивается счетчик попыток и Process
+	// возвращает ошибку fn. Возвращает количество переданных сообщений.
+	Process(ctx context.Context, limit int, fn func([]Message) error) (int, error)
+	// DeadLetter переносит сообщение id из outbox в outbox_dead_letters с причиной reason
+	DeadLetter(ctx context.Context, id int64, reason string) error
+}
+
+// Publisher отправляет сообщения в брокер (*kafka.Producer)
+type Publisher interface {
+	SendMessages(ctx context.Context, msgs []kafka.Message) error
+}
+
+// Relay периодически отправляет сообщения из outbox в брокер
+type Relay struct {
+	store        Store
+	publisher    Publisher
+	batchSize    int
+	pollInterval time.Duration
+	maxBackoff   time.Duration
+	maxAttempts  int
+	log          *logger.Logger
+}
+
+// NewRelay создает relay. pollInterval - пауза, когда outbox пуст; после ошибки
+// пауза удваивается до maxBackoff. Сообщение, которое maxAttempts раз подряд
+// не удалось отправить из-за постоянной ошибки, переносится в outbox_dead_letters,
+// чтобы не задерживать следующие; 0 - не переносить.
+func NewRelay(store Store, publisher Publisher, batchSize int, pollInterval, maxBackoff time.Duration, maxAttempts int, log *logger.Logger) *Relay {
+	return &Relay{
+		store:        store,
+		publisher:    publisher,
+		batchSize:    batchSize,
+		pollInterval: pollInterval,
+		maxBackoff:   maxBackoff,
+		maxAttempts:  maxAttempts,
+		log:          log.WithComponent("outbox").WithFunction("Relay"),
+	}
+}
+
+// Run отправляет сообщения, пока не отменен ctx. После ошибки сообщения
+// отправляются по одному, пока очередное не уйдет: так ошибка одного сообщения
+// отличается от недоступности брокера.
+func (r *Relay) Run(ctx context.Context) {
+	const op = "Run"
+
+	backoff := r.pollInterval
+	single := false
+	for {
+		limit := r.batchSize
+		if single {
+			limit = 1
+		}
+		var failed []Message
+		sent, err := r.store.Process(ctx, limit, func(msgs []Message) error {
+			err := r.publish(ctx, msgs)
+			if err != nil {
+				failed = msgs
+			}
+			return err
+		})
+		if ctx.Err() != nil {
+			return
+		}
+
+		wait := r.pollInterval
+		switch {
+		case err != nil && r.deadLetter(ctx, failed, err):
+			// Сообщение больше не блокирует outbox - продолжаем без паузы
+			continue
+		case err != nil:
+			r.log.Warn("failed to relay outbox", "function", op, "messages", sent, "retry_in", backoff.String(), "error", err)
+			single = true
+			wait = backoff
+			backoff = min(backoff*2, r.maxBackoff)
+		case sent == limit:
+			// В outbox могут остаться сообщения - продолжаем без паузы
+			single = false
+			backoff = r.pollInterval
+			continue
+		default:
+			single = false
+			backoff = r.pollInterval
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(wait):
+		}
+	}
+}
+
+// deadLetter переносит единственное сообщение неудачной отправки в outbox_dead_letters,
+// если ошибка постоянная и попытки исчерпаны. true - сообщение перенесено.
+func (r *Relay) deadLetter(ctx context.Context, failed []Message, err error) bool {
+	const op = "deadLetter"
+
+	if r.maxAttempts <= 0 || len(failed) != 1 || !permanent(err) {
+		return false
+	}
+	msg := failed[0]
+	// Attempts прочитан до этой неудачи
+	if msg.Attempts+1 < r.maxAttempts {
+		return false
+	}
+	if dlErr := r.store.DeadLetter(ctx, msg.ID, err.Error()); dlErr != nil {
+		r.log.Error("failed to move outbox message to dead letters", "function", op, "id", msg.ID, "error", dlErr)
+		return false
+	}
+	r.log.Error("outbox message moved to dead letters", "function", op,
+		"id", msg.ID, "key", msg.Key, "attempts", msg.Attempts+1, "error", err)
+	return true
+}
+
+// permanent - ошибка, которую повтор не исправит: событие не перекодируется
+// или брокер отклонил сообщение без признака временной ошибки (например, слишком большое)
+func permanent(err error) bool {
+	if errors.Is(err, kafka.ErrEncode) {
+		return true
+	}
+	var writeErrs kafkago.WriteErrors
+	if errors.As(err, &writeErrs) {
+		for _, e := range writeErrs {
+			if e != nil && !permanent(e) {
+				return false
+			}
+		}
+		return writeErrs.Count() > 0
+	}
+	var kafkaErr kafkago.Error
+	return errors.As(err, &kafkaErr) && !kafkaErr.Temporary()
+}
+
+func (r *Relay) publish(ctx context.Context, msgs []Message) error {
+	const op = "publish"
+
+	kafkaMsgs := make([]kafka.Message, 0, len(msgs))
+	for _, msg := range msgs {
+		kafkaMsgs = append(kafkaMsgs, kafka.Message{Key: msg.Key, Value: msg.Payload})
+	}
+
+	if err := r.publisher.SendMessages(ctx, kafkaMsgs); err != nil {
+		return err
+	}
+
+	r.log.Debug("outbox messages published", "function", op, "messages", len(msgs), "last_id", msgs[len(msgs)-1].ID)
+	return nil
+}
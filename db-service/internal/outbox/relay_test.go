@@ -0,0 +1,167 @@
+package outbox_test
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/N0F1X3d/todo/db-service/internal/outbox"
+	"github.com/N0F1X3d/todo/pkg/kafka"
+	"github.com/N0F1X3d/todo/pkg/logger"
+	kafkago "github.com/segmentio/kafka-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// flakyPublisher отклоняет первые failures отправок
+type flakyPublisher struct {
+	mu        sync.Mutex
+	failures  int
+	attempts  int
+	published []kafka.Message
+}
+
+func (p *flakyPublisher) SendMessages(_ context.Context, msgs []kafka.Message) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.attempts++
+	if p.attempts <= p.failures {
+		return errors.New("kafka unavailable")
+	}
+	p.published = append(p.published, msgs...)
+	return nil
+}
+
+func (p *flakyPublisher) sent() []kafka.Message {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return append([]kafka.Message(nil), p.published...)
+}
+
+func (p *flakyPublisher) sendAttempts() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.attempts
+}
+
+func runRelay(t *testing.T, store outbox.Store, publisher outbox.Publisher, batchSize int) {
+	t.Helper()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	t.Cleanup(func() {
+		cancel()
+		<-done
+	})
+
+	relay := outbox.NewRelay(store, publisher, batchSize, 5*time.Millisecond, 20*time.Millisecond, 3, logger.New("db-service", "test-logs"))
+	go func() {
+		defer close(done)
+		relay.Run(ctx)
+	}()
+}
+
+func TestRelay_PublishesInOrder(t *testing.T) {
+	store := outbox.NewMemoryStore()
+	for i := range 10 {
+		store.Add(strconv.Itoa(i%3), []byte(strconv.Itoa(i)))
+	}
+	publisher := &flakyPublisher{}
+
+	// Пачка меньше outbox: relay должен разобрать его за несколько проходов
+	runRelay(t, store, publisher, 4)
+
+	require.Eventually(t, func() bool {
+		return len(store.Pending()) == 0
+	}, time.Second, 5*time.Millisecond)
+
+	sent := publisher.sent()
+	require.Len(t, sent, 10)
+	for i, msg := range sent {
+		assert.Equal(t, strconv.Itoa(i%3), msg.Key)
+		assert.Equal(t, strconv.Itoa(i), string(msg.Value))
+	}
+}
+
+func TestRelay_RetriesUntilPublished(t *testing.T) {
+	store := outbox.NewMemoryStore()
+	store.Add("1", []byte("create-task"))
+	store.Add("1", []byte("complete-task"))
+	publisher := &flakyPublisher{failures: 3}
+
+	runRelay(t, store, publisher, 10)
+
+	require.Eventually(t, func() bool {
+		return len(store.Pending()) == 0
+	}, time.Second, 5*time.Millisecond)
+
+	// Пока брокер недоступен, сообщения остаются в outbox и не теряют порядок
+	sent := publisher.sent()
+	require.Len(t, sent, 2)
+	assert.Equal(t, "create-task", string(sent[0].Value))
+	assert.Equal(t, "complete-task", string(sent[1].Value))
+	// После ошибки relay отправляет по одному сообщению: 3 неудачи и 2 отправки
+	assert.Equal(t, 5, publisher.sendAttempts())
+}
+
+func TestRelay_KeepsMessagesWhilePublisherFails(t *testing.T) {
+	store := outbox.NewMemoryStore()
+	store.Add("1", []byte("create-task"))
+	publisher := &flakyPublisher{failures: 1 << 30}
+
+	runRelay(t, store, publisher, 10)
+
+	require.Eventually(t, func() bool {
+		pending := store.Pending()
+		return len(pending) == 1 && pending[0].Attempts >= 2
+	}, time.Second, 5*time.Millisecond)
+	assert.Empty(t, publisher.sent())
+	// Недоступность брокера - не повод переносить событие в dead letters
+	assert.Empty(t, store.DeadLetters())
+}
+
+// rejectingPublisher отклоняет пачки с сообщением poison, как брокер - слишком большое сообщение
+type rejectingPublisher struct {
+	flakyPublisher
+	poison string
+}
+
+func (p *rejectingPublisher) SendMessages(ctx context.Context, msgs []kafka.Message) error {
+	for _, msg := range msgs {
+		if string(msg.Value) == p.poison {
+			return kafkago.WriteErrors{kafkago.MessageSizeTooLarge}
+		}
+	}
+	return p.flakyPublisher.SendMessages(ctx, msgs)
+}
+
+func TestRelay_MovesPermanentlyFailingMessageToDeadLetters(t *testing.T) {
+	store := outbox.NewMemoryStore()
+	store.Add("1", []byte("create-task"))
+	store.Add("2", []byte("poison"))
+	store.Add("3", []byte("create-task"))
+	store.Add("1", []byte("complete-task"))
+	publisher := &rejectingPublisher{poison: "poison"}
+
+	runRelay(t, store, publisher, 10)
+
+	require.Eventually(t, func() bool {
+		return len(store.Pending()) == 0
+	}, 2*time.Second, 5*time.Millisecond)
+
+	// Сообщение, которое брокер не примет, не задерживает события других задач
+	dead := store.DeadLetters()
+	require.Len(t, dead, 1)
+	assert.Equal(t, "poison", string(dead[0].Payload))
+	assert.GreaterOrEqual(t, dead[0].Attempts, 2)
+
+	var values []string
+	for _, msg := range publisher.sent() {
+		values = append(values, msg.Key+":"+string(msg.Value))
+	}
+	assert.Equal(t, []string{"1:create-task", "3:create-task", "1:complete-task"}, values)
+}
@@ -0,0 +1,116 @@
+package outbox
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+)
+
+// SQLiteSchema - таблицы outbox в SQLite; создаются вместе со схемой задач
+const SQLiteSchema = `
+CREATE TABLE IF NOT EXISTS outbox (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    aggregate_key TEXT NOT NULL,
+    payload TEXT NOT NULL,
+    attempts INTEGER NOT NULL DEFAULT 0,
+    last_error TEXT,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+CREATE TABLE IF NOT EXISTS outbox_dead_letters (
+    id INTEGER PRIMARY KEY,
+    aggregate_key TEXT NOT NULL,
+    payload TEXT NOT NULL,
+    attempts INTEGER NOT NULL,
+    last_error TEXT,
+    created_at TIMESTAMP,
+    failed_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);`
+
+// SQLiteStore - outbox в таблице SQLite. База открывается с _txlock=immediate
+// (sqlite.Open), поэтому транзакция Process сериализована с записью задач.
+type SQLiteStore struct {
+	db *sql.DB
+}
+
+// NewSQLiteStore создает хранилище outbox поверх базы, открытой через sqlite.Open
+func NewSQLiteStore(db *sql.DB) *SQLiteStore {
+	return &SQLiteStore{db: db}
+}
+
+func (s *SQLiteStore) Process(ctx context.Context, limit int, fn func([]Message) error) (int, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	// После commit откат ничего не делает
+	defer func() { _ = tx.Rollback() }()
+
+	rows, err := tx.QueryContext(ctx, `SELECT id, aggregate_key, payload, attempts FROM outbox ORDER BY id LIMIT ?`, limit)
+	if err != nil {
+		return 0, err
+	}
+	var msgs []Message
+	for rows.Next() {
+		var msg Message
+		var payload string
+		if err := rows.Scan(&msg.ID, &msg.Key, &payload, &msg.Attempts); err != nil {
+			_ = rows.Close()
+			return 0, err
+		}
+		msg.Payload = []byte(payload)
+		msgs = append(msgs, msg)
+	}
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+	if len(msgs) == 0 {
+		return 0, nil
+	}
+
+	ids := make([]any, 0, len(msgs))
+	for _, msg := range msgs {
+		ids = append(ids, msg.ID)
+	}
+	in := "(" + strings.TrimSuffix(strings.Repeat("?,", len(ids)), ",") + ")"
+
+	if fnErr := fn(msgs); fnErr != nil {
+		_, err := tx.ExecContext(context.Background(),
+			`UPDATE outbox SET attempts = attempts + 1, last_error = ? WHERE id IN `+in,
+			append([]any{fnErr.Error()}, ids...)...,
+		)
+		if err == nil {
+			err = tx.Commit()
+		}
+		if err != nil {
+			return len(msgs), err
+		}
+		return len(msgs), fnErr
+	}
+
+	// Если удаление не закоммитится, сообщения отправятся повторно
+	if _, err := tx.ExecContext(ctx, `DELETE FROM outbox WHERE id IN `+in, ids...); err != nil {
+		return len(msgs), err
+	}
+	return len(msgs), tx.Commit()
+}
+
+func (s *SQLiteStore) DeadLetter(ctx context.Context, id int64, reason string) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if _, err := tx.ExecContext(ctx,
+		`INSERT OR IGNORE INTO outbox_dead_letters (id, aggregate_key, payload, attempts, last_error, created_at)
+		 SELECT id, aggregate_key, payload, attempts, ?, created_at FROM outbox WHERE id = ?`,
+		reason, id,
+	); err != nil {
+		return fmt.Errorf("move outbox message %d: %w", id, err)
+	}
+	if _, err := tx.ExecContext(ctx, `DELETE FROM outbox WHERE id = ?`, id); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
@@ -0,0 +1,215 @@
+// Package memory - хранилище задач в памяти процесса (для локального запуска и тестов)
+package memory
+
+import (
+	"context"
+	"database/sql"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/N0F1X3d/todo/db-service/internal/models"
+	"github.com/N0F1X3d/todo/db-service/internal/outbox"
+	"github.com/N0F1X3d/todo/db-service/internal/repository"
+	"github.com/N0F1X3d/todo/pkg/kafka"
+	"github.com/N0F1X3d/todo/pkg/logger"
+)
+
+type store struct {
+	mu     sync.Mutex
+	tasks  map[int]models.Task
+	nextID int
+	// outbox - куда писать события задач (nil - не писать)
+	outbox *outbox.MemoryStore
+}
+
+// TaskRepository хранит задачи в памяти. Транзакция держит блокировку хранилища
+// до commit или rollback, поэтому транзакции выполняются последовательно.
+type TaskRepository struct {
+	store *store
+	inTx  bool
+	// ctx - контекст WithTx: из него берутся метаданные событий задач
+	ctx context.Context
+	// pending - события транзакции, попадают в outbox после commit
+	pending *[]outbox.Message
+	log     *logger.Logger
+}
+
+// NewTaskRepository создает пустое хранилище
+func NewTaskRepository(log *logger.Logger) *TaskRepository {
+	return &TaskRepository{
+		store: &store{tasks: make(map[int]models.Task), nextID: 1},
+		ctx:   context.Background(),
+		log:   log.WithComponent("repository").WithFunction("MemoryTaskRepository"),
+	}
+}
+
+// WithOutbox включает запись событий задач в outbox (отправляет их outbox.Relay)
+func (r *TaskRepository) WithOutbox(o *outbox.MemoryStore) *TaskRepository {
+	r.store.outbox = o
+	return r
+}
+
+// enqueueEvent ставит событие об изменении задачи в outbox, в транзакции - после commit.
+// Вызывается под блокировкой хранилища.
+func (r *TaskRepository) enqueueEvent(action string, before, after *models.Task, requestTime time.Time) error {
+	if r.store.outbox == nil {
+		return nil
+	}
+	key, payload, err := outbox.NewEvent(r.ctx, action, before, after, requestTime)
+	if err != nil {
+		return err
+	}
+	if r.inTx {
+		*r.pending = append(*r.pending, outbox.Message{Key: key, Payload: payload})
+		return nil
+	}
+	r.store.outbox.Add(key, payload)
+	return nil
+}
+
+// lock захватывает хранилище; внутри транзакции блокировка уже захвачена
+func (r *TaskRepository) lock() func() {
+	if r.inTx {
+		return func() {}
+	}
+	r.store.mu.Lock()
+	return r.store.mu.Unlock
+}
+
+// WithTx выполняет fn под блокировкой хранилища и восстанавливает снимок при ошибке
+func (r *TaskRepository) WithTx(ctx context.Context, fn func(repo repository.TaskRepositoryInterface) error) error {
+	if r.inTx {
+		return fn(r)
+	}
+
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	snapshot := make(map[int]models.Task, len(r.store.tasks))
+	for id, task := range r.store.tasks {
+		snapshot[id] = task
+	}
+
+	txRepo := &TaskRepository{store: r.store, inTx: true, ctx: ctx, pending: &[]outbox.Message{}, log: r.log}
+
+	committed := false
+	defer func() {
+		if !committed {
+			r.store.tasks = snapshot
+		}
+	}()
+
+	if err := fn(txRepo); err != nil {
+		r.log.Debug("transaction rolled back", "function", "WithTx", "error", err)
+		return err
+	}
+	committed = true
+	for _, msg := range *txRepo.pending {
+		r.store.outbox.Add(msg.Key, msg.Payload)
+	}
+	return nil
+}
+
+// CreateTask создает задачу
+func (r *TaskRepository) CreateTask(req models.CreateTaskRequest) (*models.Task, error) {
+	defer r.lock()()
+
+	now := time.Now()
+	task := models.Task{
+		ID:          r.store.nextID,
+		UUID:        repository.NewUUID(),
+		Title:       req.Title,
+		Description: req.Description,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+	if err := r.enqueueEvent(kafka.ActionCreateTask, nil, &task, now); err != nil {
+		return nil, err
+	}
+	r.store.nextID++
+	r.store.tasks[task.ID] = task
+
+	return &task, nil
+}
+
+// CreateTasks создает задачи одной транзакцией
+func (r *TaskRepository) CreateTasks(reqs []models.CreateTaskRequest) (int64, error) {
+	var created int64
+	err := r.WithTx(context.Background(), func(repo repository.TaskRepositoryInterface) error {
+		for _, req := range reqs {
+			if _, err := repo.CreateTask(req); err != nil {
+				return err
+			}
+			created++
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return created, nil
+}
+
+// GetTaskByID возвращает задачу по id или sql.ErrNoRows
+func (r *TaskRepository) GetTaskByID(_ context.Context, id int) (*models.Task, error) {
+	defer r.lock()()
+
+	task, ok := r.store.tasks[id]
+	if !ok {
+		return nil, sql.ErrNoRows
+	}
+	return &task, nil
+}
+
+// GetTaskByIDForUpdate возвращает задачу по id; блокировку обеспечивает WithTx
+func (r *TaskRepository) GetTaskByIDForUpdate(id int) (*models.Task, error) {
+	return r.GetTaskByID(r.ctx, id)
+}
+
+// GetAllTasks возвращает все задачи в порядке id
+func (r *TaskRepository) GetAllTasks(_ context.Context) ([]models.Task, error) {
+	defer r.lock()()
+
+	tasks := make([]models.Task, 0, len(r.store.tasks))
+	for _, task := range r.store.tasks {
+		tasks = append(tasks, task)
+	}
+	sort.Slice(tasks, func(i, j int) bool { return tasks[i].ID < tasks[j].ID })
+
+	return tasks, nil
+}
+
+// CompleteTask помечает задачу выполненной
+func (r *TaskRepository) CompleteTask(id int) (*models.Task, error) {
+	defer r.lock()()
+
+	before, ok := r.store.tasks[id]
+	if !ok {
+		return nil, sql.ErrNoRows
+	}
+	task := before
+	task.Completed = true
+	task.UpdatedAt = time.Now()
+	if err := r.enqueueEvent(kafka.ActionCompleteTask, &before, &task, task.UpdatedAt); err != nil {
+		return nil, err
+	}
+	r.store.tasks[id] = task
+
+	return &task, nil
+}
+
+// DeleteTask удаляет задачу
+func (r *TaskRepository) DeleteTask(id int) error {
+	defer r.lock()()
+
+	deleted, ok := r.store.tasks[id]
+	if !ok {
+		return sql.ErrNoRows
+	}
+	if err := r.enqueueEvent(kafka.ActionDeleteTask, &deleted, nil, time.Now()); err != nil {
+		return err
+	}
+	delete(r.store.tasks, id)
+	return nil
+}
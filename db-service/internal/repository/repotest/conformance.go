@@ -0,0 +1,243 @@
+// Package repotest содержит общий набор тестов, который должна проходить
+// каждая реализация repository.TaskRepositoryInterface (PostgreSQL, SQLite, in-memory).
+package repotest
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+
+	"github.com/N0F1X3d/todo/db-service/internal/models"
+	"github.com/N0F1X3d/todo/db-service/internal/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Factory возвращает пустое хранилище для очередного теста
+type Factory func(t *testing.T) repository.TaskRepositoryInterface
+
+// Run запускает набор тестов поведения хранилища задач
+func Run(t *testing.T, newRepo Factory) {
+	t.Run("CreateTask", func(t *testing.T) { testCreateTask(t, newRepo(t)) })
+	t.Run("CreateTasks", func(t *testing.T) { testCreateTasks(t, newRepo(t)) })
+	t.Run("GetTaskByID", func(t *testing.T) { testGetTaskByID(t, newRepo(t)) })
+	t.Run("GetTaskByIDNotFound", func(t *testing.T) { testGetTaskByIDNotFound(t, newRepo(t)) })
+	t.Run("GetAllTasks", func(t *testing.T) { testGetAllTasks(t, newRepo(t)) })
+	t.Run("GetAllTasksEmpty", func(t *testing.T) { testGetAllTasksEmpty(t, newRepo(t)) })
+	t.Run("CompleteTask", func(t *testing.T) { testCompleteTask(t, newRepo(t)) })
+	t.Run("CompleteTaskNotFound", func(t *testing.T) { testCompleteTaskNotFound(t, newRepo(t)) })
+	t.Run("DeleteTask", func(t *testing.T) { testDeleteTask(t, newRepo(t)) })
+	t.Run("DeleteTaskNotFound", func(t *testing.T) { testDeleteTaskNotFound(t, newRepo(t)) })
+	t.Run("WithTxCommit", func(t *testing.T) { testWithTxCommit(t, newRepo(t)) })
+	t.Run("WithTxRollback", func(t *testing.T) { testWithTxRollback(t, newRepo(t)) })
+	t.Run("WithTxNested", func(t *testing.T) { testWithTxNested(t, newRepo(t)) })
+	t.Run("GetTaskByIDForUpdate", func(t *testing.T) { testGetTaskByIDForUpdate(t, newRepo(t)) })
+}
+
+func createTask(t *testing.T, repo repository.TaskRepositoryInterface, title string) *models.Task {
+	t.Helper()
+
+	task, err := repo.CreateTask(models.CreateTaskRequest{Title: title, Description: title + " description"})
+	require.NoError(t, err)
+	return task
+}
+
+func testCreateTask(t *testing.T, repo repository.TaskRepositoryInterface) {
+	task := createTask(t, repo, "task")
+
+	assert.Positive(t, task.ID)
+	assert.NotEmpty(t, task.UUID)
+	assert.Equal(t, "task", task.Title)
+	assert.Equal(t, "task description", task.Description)
+	assert.False(t, task.Completed)
+	assert.False(t, task.CreatedAt.IsZero())
+	assert.False(t, task.UpdatedAt.IsZero())
+
+	other := createTask(t, repo, "other")
+	assert.NotEqual(t, task.ID, other.ID)
+	assert.NotEqual(t, task.UUID, other.UUID)
+}
+
+func testCreateTasks(t *testing.T, repo repository.TaskRepositoryInterface) {
+	created, err := repo.CreateTasks([]models.CreateTaskRequest{
+		{Title: "first", Description: "first description"},
+		{Title: "second"},
+		{Title: "third"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), created)
+
+	tasks, err := repo.GetAllTasks(context.Background())
+	require.NoError(t, err)
+	require.Len(t, tasks, 3)
+
+	titles := make([]string, 0, len(tasks))
+	for _, task := range tasks {
+		assert.NotEmpty(t, task.UUID)
+		assert.False(t, task.Completed)
+		titles = append(titles, task.Title)
+	}
+	assert.ElementsMatch(t, []string{"first", "second", "third"}, titles)
+}
+
+func testGetTaskByID(t *testing.T, repo repository.TaskRepositoryInterface) {
+	created := createTask(t, repo, "task")
+
+	task, err := repo.GetTaskByID(context.Background(), created.ID)
+
+	require.NoError(t, err)
+	assert.Equal(t, created.ID, task.ID)
+	assert.Equal(t, created.UUID, task.UUID)
+	assert.Equal(t, created.Title, task.Title)
+	assert.Equal(t, created.Description, task.Description)
+	assert.True(t, created.CreatedAt.Equal(task.CreatedAt))
+}
+
+func testGetTaskByIDNotFound(t *testing.T, repo repository.TaskRepositoryInterface) {
+	_, err := repo.GetTaskByID(context.Background(), 999999)
+
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+}
+
+func testGetAllTasks(t *testing.T, repo repository.TaskRepositoryInterface) {
+	first := createTask(t, repo, "first")
+	second := createTask(t, repo, "second")
+
+	tasks, err := repo.GetAllTasks(context.Background())
+
+	require.NoError(t, err)
+	ids := make([]int, 0, len(tasks))
+	for _, task := range tasks {
+		ids = append(ids, task.ID)
+	}
+	assert.ElementsMatch(t, []int{first.ID, second.ID}, ids)
+}
+
+func testGetAllTasksEmpty(t *testing.T, repo repository.TaskRepositoryInterface) {
+	tasks, err := repo.GetAllTasks(context.Background())
+
+	require.NoError(t, err)
+	assert.Empty(t, tasks)
+}
+
+func testCompleteTask(t *testing.T, repo repository.TaskRepositoryInterface) {
+	created := createTask(t, repo, "task")
+
+	task, err := repo.CompleteTask(created.ID)
+
+	require.NoError(t, err)
+	assert.True(t, task.Completed)
+	assert.False(t, task.UpdatedAt.Before(created.UpdatedAt))
+
+	stored, err := repo.GetTaskByID(context.Background(), created.ID)
+	require.NoError(t, err)
+	assert.True(t, stored.Completed)
+}
+
+func testCompleteTaskNotFound(t *testing.T, repo repository.TaskRepositoryInterface) {
+	_, err := repo.CompleteTask(999999)
+
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+}
+
+func testDeleteTask(t *testing.T, repo repository.TaskRepositoryInterface) {
+	created := createTask(t, repo, "task")
+
+	require.NoError(t, repo.DeleteTask(created.ID))
+
+	_, err := repo.GetTaskByID(context.Background(), created.ID)
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+}
+
+func testDeleteTaskNotFound(t *testing.T, repo repository.TaskRepositoryInterface) {
+	err := repo.DeleteTask(999999)
+
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+}
+
+func testWithTxCommit(t *testing.T, repo repository.TaskRepositoryInterface) {
+	var created *models.Task
+	err := repo.WithTx(context.Background(), func(tx repository.TaskRepositoryInterface) error {
+		var err error
+		created, err = tx.CreateTask(models.CreateTaskRequest{Title: "tx task"})
+		if err != nil {
+			return err
+		}
+		_, err = tx.CompleteTask(created.ID)
+		return err
+	})
+	require.NoError(t, err)
+
+	task, err := repo.GetTaskByID(context.Background(), created.ID)
+	require.NoError(t, err)
+	assert.True(t, task.Completed)
+}
+
+func testWithTxRollback(t *testing.T, repo repository.TaskRepositoryInterface) {
+	existing := createTask(t, repo, "existing")
+	errAbort := errors.New("abort")
+
+	var created *models.Task
+	err := repo.WithTx(context.Background(), func(tx repository.TaskRepositoryInterface) error {
+		var err error
+		created, err = tx.CreateTask(models.CreateTaskRequest{Title: "rolled back"})
+		if err != nil {
+			return err
+		}
+		if _, err := tx.CompleteTask(existing.ID); err != nil {
+			return err
+		}
+		if err := tx.DeleteTask(existing.ID); err != nil {
+			return err
+		}
+		return errAbort
+	})
+	require.ErrorIs(t, err, errAbort)
+
+	_, err = repo.GetTaskByID(context.Background(), created.ID)
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+
+	task, err := repo.GetTaskByID(context.Background(), existing.ID)
+	require.NoError(t, err)
+	assert.False(t, task.Completed)
+}
+
+func testWithTxNested(t *testing.T, repo repository.TaskRepositoryInterface) {
+	errAbort := errors.New("abort")
+
+	var created *models.Task
+	err := repo.WithTx(context.Background(), func(tx repository.TaskRepositoryInterface) error {
+		err := tx.WithTx(context.Background(), func(inner repository.TaskRepositoryInterface) error {
+			var err error
+			created, err = inner.CreateTask(models.CreateTaskRequest{Title: "nested"})
+			return err
+		})
+		if err != nil {
+			return err
+		}
+		return errAbort
+	})
+	require.ErrorIs(t, err, errAbort)
+
+	// Вложенная транзакция присоединяется к внешней и откатывается вместе с ней
+	_, err = repo.GetTaskByID(context.Background(), created.ID)
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+}
+
+func testGetTaskByIDForUpdate(t *testing.T, repo repository.TaskRepositoryInterface) {
+	created := createTask(t, repo, "task")
+
+	err := repo.WithTx(context.Background(), func(tx repository.TaskRepositoryInterface) error {
+		task, err := tx.GetTaskByIDForUpdate(created.ID)
+		if err != nil {
+			return err
+		}
+		assert.Equal(t, created.ID, task.ID)
+
+		_, err = tx.GetTaskByIDForUpdate(999999)
+		assert.ErrorIs(t, err, sql.ErrNoRows)
+		return nil
+	})
+	require.NoError(t, err)
+}
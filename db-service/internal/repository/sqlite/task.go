@@ -0,0 +1,309 @@
+// Package sqlite - хранилище задач в SQLite для запуска db-service без PostgreSQL.
+// Драйвер github.com/mattn/go-sqlite3 требует сборки с CGO_ENABLED=1.
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/N0F1X3d/todo/db-service/internal/models"
+	"github.com/N0F1X3d/todo/db-service/internal/outbox"
+	"github.com/N0F1X3d/todo/db-service/internal/repository"
+	"github.com/N0F1X3d/todo/pkg/kafka"
+	"github.com/N0F1X3d/todo/pkg/logger"
+	_ "github.com/mattn/go-sqlite3"
+)
+
+const schema = `
+CREATE TABLE IF NOT EXISTS tasks (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    uuid TEXT NOT NULL UNIQUE,
+    title TEXT NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    completed BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL
+);`
+
+// Open открывает базу SQLite по пути path и создает схему задач и outbox.
+// Транзакции начинаются с BEGIN IMMEDIATE: SQLite не поддерживает SELECT ... FOR UPDATE,
+// поэтому пишущие транзакции сериализуются блокировкой базы.
+func Open(path string) (*sql.DB, error) {
+	dsn := fmt.Sprintf("file:%s?_busy_timeout=5000&_txlock=immediate&_foreign_keys=on", path)
+
+	db, err := sql.Open("sqlite3", dsn)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := db.Exec(schema + outbox.SQLiteSchema); err != nil {
+		_ = db.Close()
+		return nil, fmt.Errorf("failed to create sqlite schema: %w", err)
+	}
+
+	return db, nil
+}
+
+// querier - общие методы *sql.DB и *sql.Tx
+type querier interface {
+	QueryRow(query string, args ...any) *sql.Row
+	Query(query string, args ...any) (*sql.Rows, error)
+	Exec(query string, args ...any) (sql.Result, error)
+}
+
+// TaskRepository хранит задачи в SQLite. События об изменениях задач пишутся
+// в таблицу outbox той же транзакцией (отправляет их outbox.Relay с outbox.SQLiteStore).
+type TaskRepository struct {
+	db   *sql.DB
+	q    querier
+	inTx bool
+	// ctx - контекст WithTx: из него берутся метаданные событий задач
+	ctx context.Context
+	log *logger.Logger
+}
+
+// NewTaskRepository создает репозиторий поверх базы, открытой через Open
+func NewTaskRepository(db *sql.DB, log *logger.Logger) *TaskRepository {
+	return &TaskRepository{
+		db:  db,
+		q:   db,
+		ctx: context.Background(),
+		log: log.WithComponent("repository").WithFunction("SQLiteTaskRepository"),
+	}
+}
+
+// WithTx выполняет fn в транзакции SQLite
+func (r *TaskRepository) WithTx(ctx context.Context, fn func(repo repository.TaskRepositoryInterface) error) error {
+	const op = "WithTx"
+
+	if r.inTx {
+		return fn(r)
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		r.log.ErrorWithContext("failed to begin transaction", err, op)
+		return err
+	}
+
+	defer func() {
+		if p := recover(); p != nil {
+			_ = tx.Rollback()
+			panic(p)
+		}
+	}()
+
+	if err := fn(&TaskRepository{db: r.db, q: tx, inTx: true, ctx: ctx, log: r.log}); err != nil {
+		if rbErr := tx.Rollback(); rbErr != nil {
+			r.log.ErrorWithContext("failed to rollback transaction", rbErr, op)
+		}
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		r.log.ErrorWithContext("failed to commit transaction", err, op)
+		return err
+	}
+	return nil
+}
+
+// inTransaction выполняет fn в текущей транзакции или открывает новую: изменение
+// задачи и его событие в outbox записываются вместе
+func (r *TaskRepository) inTransaction(fn func(tx *TaskRepository) error) error {
+	if r.inTx {
+		return fn(r)
+	}
+	return r.WithTx(context.Background(), func(repo repository.TaskRepositoryInterface) error {
+		return fn(repo.(*TaskRepository))
+	})
+}
+
+// enqueueEvent пишет событие об изменении задачи в outbox. Вызывается только внутри inTransaction.
+func (r *TaskRepository) enqueueEvent(action string, before, after *models.Task, requestTime time.Time) error {
+	key, payload, err := outbox.NewEvent(r.ctx, action, before, after, requestTime)
+	if err != nil {
+		return err
+	}
+	_, err = r.q.Exec(`INSERT INTO outbox (aggregate_key, payload) VALUES (?, ?)`, key, string(payload))
+	return err
+}
+
+// CreateTask создает новую задачу
+func (r *TaskRepository) CreateTask(req models.CreateTaskRequest) (*models.Task, error) {
+	const op = "CreateTask"
+	r.log.LogRequest(op, req)
+
+	now := time.Now().UTC()
+	query := `INSERT INTO tasks (uuid, title, description, completed, created_at, updated_at)
+			  VALUES (?, ?, ?, FALSE, ?, ?)
+			  RETURNING id, uuid, title, description, completed, created_at, updated_at`
+
+	var task *models.Task
+	err := r.inTransaction(func(tx *TaskRepository) error {
+		var err error
+		task, err = scanTask(tx.q.QueryRow(query, repository.NewUUID(), req.Title, req.Description, now, now))
+		if err != nil {
+			return err
+		}
+		return tx.enqueueEvent(kafka.ActionCreateTask, nil, task, now)
+	})
+	if err != nil {
+		r.log.ErrorWithContext("failed to create task", err, op, "title", req.Title)
+		return nil, err
+	}
+
+	r.log.LogResponse(op, task)
+	return task, nil
+}
+
+// CreateTasks создает задачи одной транзакцией
+func (r *TaskRepository) CreateTasks(reqs []models.CreateTaskRequest) (int64, error) {
+	var created int64
+	err := r.WithTx(context.Background(), func(repo repository.TaskRepositoryInterface) error {
+		for _, req := range reqs {
+			if _, err := repo.CreateTask(req); err != nil {
+				return err
+			}
+			created++
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return created, nil
+}
+
+// GetTaskByID возвращает задачу по id или sql.ErrNoRows
+func (r *TaskRepository) GetTaskByID(_ context.Context, id int) (*models.Task, error) {
+	const op = "GetTaskByID"
+	r.log.LogRequest(op, map[string]interface{}{"id": id})
+
+	query := `SELECT id, uuid, title, description, completed, created_at, updated_at
+			  FROM tasks WHERE id = ?`
+
+	task, err := scanTask(r.q.QueryRow(query, id))
+	if err != nil {
+		if err != sql.ErrNoRows {
+			r.log.ErrorWithContext("failed to get task", err, op, "id", id)
+		}
+		return nil, err
+	}
+
+	r.log.LogResponse(op, task)
+	return task, nil
+}
+
+// GetTaskByIDForUpdate возвращает задачу по id. Блокировку обеспечивает
+// транзакция BEGIN IMMEDIATE, открытая в WithTx.
+func (r *TaskRepository) GetTaskByIDForUpdate(id int) (*models.Task, error) {
+	return r.GetTaskByID(r.ctx, id)
+}
+
+// GetAllTasks возвращает все задачи
+func (r *TaskRepository) GetAllTasks(_ context.Context) ([]models.Task, error) {
+	const op = "GetAllTasks"
+	r.log.LogRequest(op, nil)
+
+	rows, err := r.q.Query(`SELECT id, uuid, title, description, completed, created_at, updated_at
+			  FROM tasks ORDER BY id`)
+	if err != nil {
+		r.log.ErrorWithContext("failed to get all tasks", err, op)
+		return nil, err
+	}
+	defer rows.Close()
+
+	var tasks []models.Task
+	for rows.Next() {
+		task, err := scanTask(rows)
+		if err != nil {
+			r.log.ErrorWithContext("failed to scan task", err, op)
+			return nil, err
+		}
+		tasks = append(tasks, *task)
+	}
+	if err := rows.Err(); err != nil {
+		r.log.ErrorWithContext("failed to iterate tasks", err, op)
+		return nil, err
+	}
+
+	r.log.LogResponse(op, map[string]interface{}{"tasks_count": len(tasks)})
+	return tasks, nil
+}
+
+// CompleteTask помечает задачу выполненной
+func (r *TaskRepository) CompleteTask(id int) (*models.Task, error) {
+	const op = "CompleteTask"
+	r.log.LogRequest(op, map[string]interface{}{"id": id})
+
+	now := time.Now().UTC()
+	query := `UPDATE tasks SET completed = TRUE, updated_at = ?
+			  WHERE id = ?
+			  RETURNING id, uuid, title, description, completed, created_at, updated_at`
+
+	var task *models.Task
+	err := r.inTransaction(func(tx *TaskRepository) error {
+		// Состояние до изменения нужно для снимка before в событии
+		before, err := scanTask(tx.q.QueryRow(`SELECT id, uuid, title, description, completed, created_at, updated_at
+			  FROM tasks WHERE id = ?`, id))
+		if err != nil {
+			return err
+		}
+		task, err = scanTask(tx.q.QueryRow(query, now, id))
+		if err != nil {
+			return err
+		}
+		return tx.enqueueEvent(kafka.ActionCompleteTask, before, task, now)
+	})
+	if err != nil {
+		if err != sql.ErrNoRows {
+			r.log.ErrorWithContext("failed to complete task", err, op, "id", id)
+		}
+		return nil, err
+	}
+
+	r.log.LogResponse(op, task)
+	return task, nil
+}
+
+// DeleteTask удаляет задачу по id
+func (r *TaskRepository) DeleteTask(id int) error {
+	const op = "DeleteTask"
+	r.log.LogRequest(op, map[string]interface{}{"id": id})
+
+	// Удаленная строка нужна для снимка before в событии
+	query := `DELETE FROM tasks WHERE id = ?
+			  RETURNING id, uuid, title, description, completed, created_at, updated_at`
+
+	err := r.inTransaction(func(tx *TaskRepository) error {
+		deleted, err := scanTask(tx.q.QueryRow(query, id))
+		if err != nil {
+			return err
+		}
+		return tx.enqueueEvent(kafka.ActionDeleteTask, deleted, nil, time.Now().UTC())
+	})
+	if err != nil {
+		if err != sql.ErrNoRows {
+			r.log.ErrorWithContext("failed to delete task", err, op, "id", id)
+		}
+		return err
+	}
+
+	r.log.LogResponse(op, map[string]interface{}{"deleted": true, "id": id})
+	return nil
+}
+
+type scanner interface {
+	Scan(dest ...any) error
+}
+
+func scanTask(row scanner) (*models.Task, error) {
+	var task models.Task
+	err := row.Scan(&task.ID, &task.UUID, &task.Title, &task.Description, &task.Completed, &task.CreatedAt, &task.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &task, nil
+}
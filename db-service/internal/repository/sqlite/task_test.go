@@ -0,0 +1,67 @@
+package sqlite_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"github.com/N0F1X3d/todo/db-service/internal/models"
+	"github.com/N0F1X3d/todo/db-service/internal/outbox"
+	"github.com/N0F1X3d/todo/db-service/internal/repository"
+	"github.com/N0F1X3d/todo/db-service/internal/repository/repotest"
+	"github.com/N0F1X3d/todo/db-service/internal/repository/sqlite"
+	"github.com/N0F1X3d/todo/pkg/kafka"
+	"github.com/N0F1X3d/todo/pkg/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConformance(t *testing.T) {
+	testLogger := logger.New("db-service", "test-logs")
+
+	repotest.Run(t, func(t *testing.T) repository.TaskRepositoryInterface {
+		db, err := sqlite.Open(filepath.Join(t.TempDir(), "tasks.db"))
+		require.NoError(t, err)
+		t.Cleanup(func() { _ = db.Close() })
+
+		return sqlite.NewTaskRepository(db, testLogger)
+	})
+}
+
+func TestTaskRepository_WritesEventsToOutbox(t *testing.T) {
+	db, err := sqlite.Open(filepath.Join(t.TempDir(), "tasks.db"))
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = db.Close() })
+	repo := sqlite.NewTaskRepository(db, logger.New("db-service", "test-logs"))
+
+	task, err := repo.CreateTask(models.CreateTaskRequest{Title: "outbox"})
+	require.NoError(t, err)
+	_, err = repo.CompleteTask(task.ID)
+	require.NoError(t, err)
+	require.NoError(t, repo.DeleteTask(task.ID))
+
+	// Откат транзакции не оставляет событий
+	err = repo.WithTx(context.Background(), func(tx repository.TaskRepositoryInterface) error {
+		if _, err := tx.CreateTask(models.CreateTaskRequest{Title: "rolled back"}); err != nil {
+			return err
+		}
+		return errors.New("rollback")
+	})
+	require.Error(t, err)
+
+	var actions []string
+	_, err = outbox.NewSQLiteStore(db).Process(context.Background(), 10, func(msgs []outbox.Message) error {
+		for _, msg := range msgs {
+			assert.Equal(t, strconv.Itoa(task.ID), msg.Key)
+			var event kafka.TaskEvent
+			require.NoError(t, json.Unmarshal(msg.Payload, &event))
+			actions = append(actions, event.Action)
+		}
+		return nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{kafka.ActionCreateTask, kafka.ActionCompleteTask, kafka.ActionDeleteTask}, actions)
+}
@@ -4,95 +4,244 @@ import (
 	"context"
 	"database/sql"
 	"encoding/json"
-	"fmt"
+	"errors"
 	"time"
 
+	"github.com/N0F1X3d/todo/db-service/internal/cache"
+	"github.com/N0F1X3d/todo/db-service/internal/dbrouter"
 	"github.com/N0F1X3d/todo/db-service/internal/models"
+	"github.com/N0F1X3d/todo/db-service/internal/outbox"
+	"github.com/N0F1X3d/todo/pkg/kafka"
 	"github.com/N0F1X3d/todo/pkg/logger"
-	"github.com/redis/go-redis/v9"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
 )
 
 //go:generate mockery --name=TaskRepositoryInterface --filename=task_repository_interface.go --output=../../mocks --case=underscore
 type TaskRepositoryInterface interface {
 	CreateTask(req models.CreateTaskRequest) (*models.Task, error)
-	GetTaskByID(id int) (*models.Task, error)
-	GetAllTasks() ([]models.Task, error)
+	// CreateTasks создает задачи пачкой и возвращает количество вставленных строк
+	CreateTasks(reqs []models.CreateTaskRequest) (int64, error)
+	// GetTaskByID и GetAllTasks берут из ctx клиента запроса для read-your-writes
+	GetTaskByID(ctx context.Context, id int) (*models.Task, error)
+	// GetTaskByIDForUpdate читает задачу в обход кеша и блокирует ее до конца транзакции
+	GetTaskByIDForUpdate(id int) (*models.Task, error)
+	GetAllTasks(ctx context.Context) ([]models.Task, error)
 	CompleteTask(id int) (*models.Task, error)
 	DeleteTask(id int) error
+	// WithTx выполняет fn в транзакции: repo внутри fn работает в ней же.
+	// Если fn вернула ошибку, транзакция откатывается. Вложенный WithTx
+	// присоединяется к внешней транзакции.
+	WithTx(ctx context.Context, fn func(repo TaskRepositoryInterface) error) error
 }
 
-// TaskRepository предоставляет методы для работы с PostgreSQL
+// querier - общие методы *pgxpool.Pool и pgx.Tx
+type querier interface {
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+	Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error)
+	CopyFrom(ctx context.Context, tableName pgx.Identifier, columnNames []string, rowSrc pgx.CopyFromSource) (int64, error)
+}
+
+// txState - состояние открытой транзакции
+type txState struct {
+	// ctx - контекст WithTx: из него берутся метаданные событий задач
+	ctx context.Context
+	// afterCommit - изменения кеша, которые применяются только после commit
+	afterCommit []func(ctx context.Context)
+}
+
+// TaskRepository предоставляет методы для работы с PostgreSQL через пул pgx
 // Реализует паттерн Repository для абстракции доступа к данным
 type TaskRepository struct {
-	db          *sql.DB
-	log         *logger.Logger
-	redisClient *redis.Client
-	cacheTTL    time.Duration
+	pool *pgxpool.Pool
+	q    querier
+	tx   *txState
+	// router выбирает реплику для чтения (nil - все запросы идут на primary)
+	router *dbrouter.Router
+	log    *logger.Logger
+	cache  *cache.TaskCache
 }
 
-func NewTaskRepository(db *sql.DB, log *logger.Logger, redisClient *redis.Client, cacheTTL time.Duration) *TaskRepository {
+// NewTaskRepository создает репозиторий. taskCache может быть выключенным кешем
+// (cache.NewTaskCache(nil, 0, log)), router - nil, если реплик нет.
+func NewTaskRepository(pool *pgxpool.Pool, router *dbrouter.Router, taskCache *cache.TaskCache, log *logger.Logger) *TaskRepository {
 	return &TaskRepository{
-		db:          db,
-		log:         log.WithComponent("repository").WithFunction("TaskRepository"),
-		redisClient: redisClient,
-		cacheTTL:    cacheTTL,
+		pool:   pool,
+		q:      pool,
+		router: router,
+		log:    log.WithComponent("repository").WithFunction("TaskRepository"),
+		cache:  taskCache,
 	}
 }
 
-func (r *TaskRepository) cacheKey(id int) string {
-	return fmt.Sprintf("task:%d", id)
+// WithTx выполняет fn в транзакции PostgreSQL. Запись в Redis откладывается до commit,
+// чтобы кеш не увидел данные откатанной транзакции.
+func (r *TaskRepository) WithTx(ctx context.Context, fn func(repo TaskRepositoryInterface) error) (err error) {
+	const op = "WithTx"
+
+	if r.tx != nil {
+		return fn(r)
+	}
+
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		r.log.ErrorWithContext("failed to begin transaction", err, op)
+		return err
+	}
+
+	txRepo := &TaskRepository{
+		pool:   r.pool,
+		q:      tx,
+		tx:     &txState{ctx: ctx},
+		router: r.router,
+		log:    r.log,
+		cache:  r.cache,
+	}
+
+	defer func() {
+		if p := recover(); p != nil {
+			_ = tx.Rollback(context.Background())
+			panic(p)
+		}
+	}()
+
+	if err := fn(txRepo); err != nil {
+		if rbErr := tx.Rollback(ctx); rbErr != nil {
+			r.log.ErrorWithContext("failed to rollback transaction", rbErr, op)
+		}
+		return err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		r.log.ErrorWithContext("failed to commit transaction", err, op)
+		return err
+	}
+
+	for _, apply := range txRepo.tx.afterCommit {
+		apply(ctx)
+	}
+	return nil
 }
 
-func (r *TaskRepository) cacheEnabled() bool {
-	return r != nil && r.redisClient != nil && r.cacheTTL > 0
+// replicaFor возвращает реплику для чтения задачи id или nil, если читать нужно с primary.
+// Транзакция всегда читает с primary.
+func (r *TaskRepository) replicaFor(ctx context.Context, id int) *pgxpool.Pool {
+	if r.router == nil || r.tx != nil {
+		return nil
+	}
+	return r.router.Reader(ctx, id)
 }
 
-func (r *TaskRepository) setTaskCache(ctx context.Context, task *models.Task) {
-	if !r.cacheEnabled() || task == nil {
-		return
+// replicaForList возвращает реплику для чтения списка задач или nil
+func (r *TaskRepository) replicaForList(ctx context.Context) *pgxpool.Pool {
+	if r.router == nil || r.tx != nil {
+		return nil
 	}
+	return r.router.ListReader(ctx)
+}
 
-	data, err := json.Marshal(task)
-	if err != nil {
-		r.log.Warn("failed to marshal task for cache", "function", "setTaskCache", "task_id", task.ID, "error", err)
-		return
+// writeCtx - контекст записи для read-your-writes: ctx транзакции, если она открыта через WithTx
+func (r *TaskRepository) writeCtx() context.Context {
+	if r.tx != nil {
+		return r.tx.ctx
 	}
+	return context.Background()
+}
 
-	if err := r.redisClient.Set(ctx, r.cacheKey(task.ID), data, r.cacheTTL).Err(); err != nil {
-		r.log.Warn("failed to set task cache", "function", "setTaskCache", "task_id", task.ID, "error", err)
+// markWrite включает read-your-writes задачи id для клиента, который ее записал
+func (r *TaskRepository) markWrite(id int) {
+	if r.router != nil {
+		r.router.MarkWrite(r.writeCtx(), id)
 	}
 }
 
-func (r *TaskRepository) getTaskFromCache(ctx context.Context, id int) (*models.Task, bool) {
-	if !r.cacheEnabled() {
-		return nil, false
+// fallbackToPrimary решает, повторять ли чтение с primary после ошибки реплики.
+// Отсутствие строки на реплике может быть отставанием репликации, поэтому его
+// перепроверяет primary (иначе оно попадет в negative cache), но сбоем реплики оно не считается.
+func (r *TaskRepository) fallbackToPrimary(op string, replica *pgxpool.Pool, err error) bool {
+	if replica == nil || err == nil {
+		return false
 	}
+	if errors.Is(err, pgx.ErrNoRows) {
+		return true
+	}
+	r.log.Warn("replica read failed, falling back to primary", "function", op, "error", err)
+	r.router.MarkUnhealthy(replica)
+	return true
+}
 
-	res, err := r.redisClient.Get(ctx, r.cacheKey(id)).Result()
-	if err != nil {
-		if err != redis.Nil {
-			r.log.Warn("failed to get task from cache", "function", "getTaskFromCache", "task_id", id, "error", err)
-		}
-		return nil, false
+// cacheGetOrLoad читает задачу из кеша, а при промахе - через load, схлопывая
+// параллельные промахи. Чтение с primary не присоединяется к загрузке с реплики:
+// иначе автор записи получил бы задачу до своей записи. В транзакции кеш
+// не используется: чтение должно видеть состояние БД внутри транзакции.
+func (r *TaskRepository) cacheGetOrLoad(id int, primary bool, load cache.LoadFunc) (*models.Task, error) {
+	if r.tx != nil {
+		return load()
+	}
+	if primary {
+		return r.cache.GetOrLoadPrimary(context.Background(), id, load)
 	}
+	return r.cache.GetOrLoad(context.Background(), id, load)
+}
 
-	var task models.Task
-	if err := json.Unmarshal([]byte(res), &task); err != nil {
-		r.log.Warn("failed to unmarshal task from cache", "function", "getTaskFromCache", "task_id", id, "error", err)
-		return nil, false
+// cacheSet обновляет кеш сразу или после commit транзакции
+func (r *TaskRepository) cacheSet(task *models.Task) {
+	if r.tx != nil {
+		r.tx.afterCommit = append(r.tx.afterCommit, func(ctx context.Context) {
+			r.cache.Set(ctx, task)
+		})
+		return
 	}
+	r.cache.Set(context.Background(), task)
+}
 
-	return &task, true
+// cacheDelete удаляет задачу из кеша сразу или после commit транзакции
+func (r *TaskRepository) cacheDelete(id int) {
+	if r.tx != nil {
+		r.tx.afterCommit = append(r.tx.afterCommit, func(ctx context.Context) {
+			r.cache.Delete(ctx, id)
+		})
+		return
+	}
+	r.cache.Delete(context.Background(), id)
 }
 
-func (r *TaskRepository) deleteTaskCache(ctx context.Context, id int) {
-	if !r.cacheEnabled() {
+// invalidateLists начинает новое поколение закешированных списков сразу или после commit
+func (r *TaskRepository) invalidateLists() {
+	if r.tx != nil {
+		r.tx.afterCommit = append(r.tx.afterCommit, r.cache.InvalidateLists)
 		return
 	}
+	r.cache.InvalidateLists(context.Background())
+}
+
+// inTx выполняет fn в текущей транзакции или открывает новую: изменение задачи
+// и его событие в outbox записываются вместе
+func (r *TaskRepository) inTx(fn func(tx *TaskRepository) error) error {
+	if r.tx != nil {
+		return fn(r)
+	}
+	return r.WithTx(context.Background(), func(repo TaskRepositoryInterface) error {
+		return fn(repo.(*TaskRepository))
+	})
+}
 
-	if err := r.redisClient.Del(ctx, r.cacheKey(id)).Err(); err != nil {
-		r.log.Warn("failed to delete task cache", "function", "deleteTaskCache", "task_id", id, "error", err)
+// enqueueEvent пишет событие об изменении задачи в outbox. Ключ события - id задачи,
+// метаданные запроса (actor, request/trace id) берутся из ctx транзакции.
+// Вызывается только внутри inTx.
+func (r *TaskRepository) enqueueEvent(action string, before, after *models.Task, requestTime time.Time) error {
+	key, payload, err := outbox.NewEvent(r.tx.ctx, action, before, after, requestTime)
+	if err != nil {
+		return err
 	}
+
+	_, err = r.q.Exec(context.Background(),
+		`INSERT INTO outbox (aggregate_key, payload) VALUES ($1, $2)`,
+		key, payload,
+	)
+	return err
 }
 
 // CreateTask создает новую задачу в базе данных
@@ -104,13 +253,19 @@ func (r *TaskRepository) CreateTask(req models.CreateTaskRequest) (*models.Task,
 	var task models.Task
 
 	query := `INSERT INTO tasks (title, description) VALUES ($1, $2)
-			  RETURNING id, title, description, completed, created_at, updated_at`
+			  RETURNING id, uuid, title, description, completed, created_at, updated_at`
 
 	logQuery(r.log, op, query, req.Title, req.Description)
 
-	err := r.db.QueryRow(query, req.Title, req.Description).Scan(
-		&task.ID, &task.Title, &task.Description, &task.Completed, &task.CreatedAt, &task.UpdatedAt,
-	)
+	err := r.inTx(func(tx *TaskRepository) error {
+		err := tx.q.QueryRow(context.Background(), query, req.Title, req.Description).Scan(
+			&task.ID, &task.UUID, &task.Title, &task.Description, &task.Completed, &task.CreatedAt, &task.UpdatedAt,
+		)
+		if err != nil {
+			return err
+		}
+		return tx.enqueueEvent(kafka.ActionCreateTask, nil, &task, start)
+	})
 	duration := time.Since(start).Milliseconds()
 
 	if err != nil {
@@ -119,88 +274,247 @@ func (r *TaskRepository) CreateTask(req models.CreateTaskRequest) (*models.Task,
 	}
 
 	// Кэшируем только что созданную задачу
-	r.setTaskCache(context.Background(), &task)
+	r.cacheSet(&task)
+	r.markWrite(task.ID)
+	r.invalidateLists()
 
 	r.log.LogResponse(op, task)
 	logQueryResult(r.log, op, duration, 1)
 	return &task, nil
 }
 
+// CreateTasks загружает задачи одним COPY во временную таблицу и переносит их в tasks
+// одним INSERT, который заодно пишет события в outbox. Задачи не кешируются.
+func (r *TaskRepository) CreateTasks(reqs []models.CreateTaskRequest) (int64, error) {
+	const op = "CreateTasks"
+	r.log.LogRequest(op, map[string]interface{}{"tasks_count": len(reqs)})
+	start := time.Now()
+
+	rows := make([][]any, 0, len(reqs))
+	for _, req := range reqs {
+		rows = append(rows, []any{req.Title, req.Description})
+	}
+
+	var copied int64
+	err := r.inTx(func(tx *TaskRepository) error {
+		ctx := context.Background()
+
+		// Общие поля событий; event_id, task_id и снимок задачи добавляются в SQL для каждой строки
+		payload, err := json.Marshal(kafka.NewTaskEvent(tx.tx.ctx, kafka.ActionCreateTask, start))
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.q.Exec(ctx, `CREATE TEMP TABLE tasks_import (title TEXT, description TEXT)`)
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.q.CopyFrom(ctx, pgx.Identifier{"tasks_import"}, []string{"title", "description"}, pgx.CopyFromRows(rows))
+		if err != nil {
+			return err
+		}
+
+		res, err := tx.q.Exec(ctx, `WITH created AS (
+				INSERT INTO tasks (title, description) SELECT title, description FROM tasks_import
+				RETURNING id, uuid, title, description, completed, created_at, updated_at
+			)
+			INSERT INTO outbox (aggregate_key, payload)
+			SELECT id::text, $1::jsonb || jsonb_build_object(
+				'event_id', gen_random_uuid(),
+				'task_id', id,
+				'after', jsonb_build_object(
+					'id', id, 'uuid', uuid, 'title', title, 'description', description,
+					'completed', completed, 'created_at', created_at, 'updated_at', updated_at
+				)
+			)
+			FROM created`, payload)
+		if err != nil {
+			return err
+		}
+		copied = res.RowsAffected()
+
+		// Удаляем сразу: CreateTasks может вызываться несколько раз в одной транзакции
+		_, err = tx.q.Exec(ctx, `DROP TABLE tasks_import`)
+		return err
+	})
+	duration := time.Since(start).Milliseconds()
+
+	if err != nil {
+		r.log.ErrorWithContext("failed to copy tasks", err, op, "tasks_count", len(reqs), "duration", duration)
+		return 0, err
+	}
+
+	if r.router != nil {
+		r.router.MarkWriteAll(r.writeCtx())
+	}
+	r.invalidateLists()
+
+	r.log.LogResponse(op, map[string]interface{}{"created": copied})
+	logQueryResult(r.log, op, duration, copied)
+	return copied, nil
+}
+
 // GetTaskById возвращает задачу по ее id
-func (r *TaskRepository) GetTaskByID(id int) (*models.Task, error) {
+func (r *TaskRepository) GetTaskByID(ctx context.Context, id int) (*models.Task, error) {
 	const op = "GetTaskByID"
 	r.log.LogRequest(op, map[string]interface{}{"id": id})
 	start := time.Now()
 
-	// Сначала пробуем получить задачу из кеша
-	if taskFromCache, ok := r.getTaskFromCache(context.Background(), id); ok {
-		duration := time.Since(start).Milliseconds()
-		r.log.LogResponse(op, taskFromCache)
-		logQueryResult(r.log, op, duration, 1)
-		return taskFromCache, nil
+	// Кеш отдает задачу или ее отсутствие; при промахе читаем из БД
+	replica := r.replicaFor(ctx, id)
+	task, err := r.cacheGetOrLoad(id, replica == nil, func() (*models.Task, error) {
+		return r.queryTask(op, id, replica)
+	})
+	duration := time.Since(start).Milliseconds()
+
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			r.log.Warn("task not found", "function", op, "id", id, "duration", duration)
+		}
+		return nil, err
 	}
 
+	r.log.LogResponse(op, task)
+	logQueryResult(r.log, op, duration, 1)
+	return task, nil
+}
+
+// queryTask читает задачу из БД: с replica (nil - с primary), а при сбое реплики
+// или отсутствии на ней задачи - с primary
+func (r *TaskRepository) queryTask(op string, id int, replica *pgxpool.Pool) (*models.Task, error) {
 	var task models.Task
 
-	query := `SELECT id, title, description, completed, created_at, updated_at
+	query := `SELECT id, uuid, title, description, completed, created_at, updated_at
 			  FROM tasks WHERE id = $1`
 	logQuery(r.log, op, query, id)
 
-	err := r.db.QueryRow(query, id).Scan(
-		&task.ID, &task.Title, &task.Description, &task.Completed, &task.CreatedAt, &task.UpdatedAt,
+	scan := func(q querier) error {
+		return q.QueryRow(context.Background(), query, id).Scan(
+			&task.ID, &task.UUID, &task.Title, &task.Description, &task.Completed, &task.CreatedAt, &task.UpdatedAt,
+		)
+	}
+
+	q := r.q
+	if replica != nil {
+		q = replica
+	}
+	err := scan(q)
+	if r.fallbackToPrimary(op, replica, err) {
+		err = scan(r.q)
+	}
+
+	if err != nil {
+		if !errors.Is(err, pgx.ErrNoRows) {
+			r.log.ErrorWithContext("failed to get task", err, op, "id", id)
+		}
+		return nil, noRows(err)
+	}
+	return &task, nil
+}
+
+// GetTaskByIDForUpdate возвращает задачу по id, блокируя строку (SELECT ... FOR UPDATE)
+// до конца транзакции. Вне WithTx блокировка снимается сразу после запроса.
+func (r *TaskRepository) GetTaskByIDForUpdate(id int) (*models.Task, error) {
+	const op = "GetTaskByIDForUpdate"
+	r.log.LogRequest(op, map[string]interface{}{"id": id})
+	start := time.Now()
+
+	var task models.Task
+
+	query := `SELECT id, uuid, title, description, completed, created_at, updated_at
+			  FROM tasks WHERE id = $1 FOR UPDATE`
+	logQuery(r.log, op, query, id)
+
+	err := r.q.QueryRow(context.Background(), query, id).Scan(
+		&task.ID, &task.UUID, &task.Title, &task.Description, &task.Completed, &task.CreatedAt, &task.UpdatedAt,
 	)
 	duration := time.Since(start).Milliseconds()
 
 	if err != nil {
-		if err == sql.ErrNoRows {
+		if errors.Is(err, pgx.ErrNoRows) {
 			r.log.Warn("task not found", "function", op, "id", id, "duration", duration)
 		} else {
-			r.log.ErrorWithContext("failed to get task", err, op, "id", id, "duration", duration)
+			r.log.ErrorWithContext("failed to get task for update", err, op, "id", id, "duration", duration)
 		}
-		return nil, err
+		return nil, noRows(err)
 	}
 
-	// Обновляем кеш после успешного чтения из БД
-	r.setTaskCache(context.Background(), &task)
-
 	r.log.LogResponse(op, task)
 	logQueryResult(r.log, op, duration, 1)
 	return &task, nil
 }
 
 // GetAllTasks возвращает список всех задач
-func (r *TaskRepository) GetAllTasks() ([]models.Task, error) {
+func (r *TaskRepository) GetAllTasks(ctx context.Context) ([]models.Task, error) {
 	const op = "GetAllTasks"
 	r.log.LogRequest(op, nil)
 
 	start := time.Now()
 
-	query := `SELECT id, title, description, completed, created_at, updated_at FROM tasks`
+	query := `SELECT id, uuid, title, description, completed, created_at, updated_at FROM tasks`
+
+	// Поколение списков берется до запроса к БД, чтобы список, прочитанный
+	// во время записи, лег под устаревшее поколение. В транзакции кеш не используется.
+	gen, cacheable := int64(0), false
+	if r.tx == nil {
+		gen, cacheable = r.cache.ListGeneration(context.Background())
+	}
+	if cacheable {
+		if tasks, ok := r.cache.GetList(context.Background(), gen, cache.ListFilterAll); ok {
+			duration := time.Since(start).Milliseconds()
+			r.log.LogResponse(op, tasks)
+			logQueryResult(r.log, op, duration, int64(len(tasks)))
+			return tasks, nil
+		}
+	}
 
 	logQuery(r.log, op, query)
 
-	rows, err := r.db.Query(query)
+	// Читаем с реплики, если клиент из ctx недавно не писал; при сбое реплики - с primary
+	replica := r.replicaForList(ctx)
+	q := r.q
+	if replica != nil {
+		q = replica
+	}
+	tasks, err := r.queryTasks(q, query)
+	if r.fallbackToPrimary(op, replica, err) {
+		tasks, err = r.queryTasks(r.q, query)
+	}
 	if err != nil {
 		r.log.ErrorWithContext("failed to get all tasks", err, op)
 		return nil, err
 	}
+
+	// Список с реплики после чужой записи может быть устаревшим для ее автора - в кеш не кладем
+	if cacheable && (replica == nil || r.router.Settled()) {
+		r.cache.SetList(context.Background(), gen, cache.ListFilterAll, tasks)
+	}
+
+	duration := time.Since(start).Milliseconds()
+	r.log.LogResponse(op, tasks)
+	logQueryResult(r.log, op, duration, int64(len(tasks)))
+	return tasks, nil
+}
+
+// queryTasks выполняет запрос списка задач через q
+func (r *TaskRepository) queryTasks(q querier, query string) ([]models.Task, error) {
+	rows, err := q.Query(context.Background(), query)
+	if err != nil {
+		return nil, err
+	}
 	defer rows.Close()
 
 	var tasks []models.Task
 	for rows.Next() {
 		var task models.Task
-		err := rows.Scan(&task.ID, &task.Title, &task.Description, &task.Completed, &task.CreatedAt, &task.UpdatedAt)
+		err := rows.Scan(&task.ID, &task.UUID, &task.Title, &task.Description, &task.Completed, &task.CreatedAt, &task.UpdatedAt)
 		if err != nil {
-			r.log.ErrorWithContext("failed to scan task", err, op)
 			return nil, err
 		}
 		tasks = append(tasks, task)
 	}
-
-	duration := time.Since(start).Milliseconds()
-	r.log.LogResponse(op, tasks)
-	logQueryResult(r.log, op, duration, int64(len(tasks)))
-	return tasks, nil
+	return tasks, rows.Err()
 }
 
 // UpdateTask обновляет задачу в базе данных по переданному id задачи completed -> true
@@ -209,28 +523,43 @@ func (r *TaskRepository) CompleteTask(id int) (*models.Task, error) {
 	r.log.LogRequest(op, map[string]interface{}{"id": id})
 	start := time.Now()
 
-	var task models.Task
-	query := `UPDATE tasks
+	// Состояние до изменения нужно для снимка before в событии
+	var task, before models.Task
+	query := `UPDATE tasks t
 			  SET completed = true, updated_at = CURRENT_TIMESTAMP
-			  WHERE id = $1
-			  RETURNING id, title, description, completed, created_at, updated_at`
+			  FROM (SELECT id, completed, updated_at FROM tasks WHERE id = $1 FOR UPDATE) old
+			  WHERE t.id = old.id
+			  RETURNING t.id, t.uuid, t.title, t.description, t.completed, t.created_at, t.updated_at,
+			            old.completed, old.updated_at`
 	logQuery(r.log, op, query, id)
 
-	err := r.db.QueryRow(query, id).Scan(
-		&task.ID, &task.Title, &task.Description, &task.Completed, &task.CreatedAt, &task.UpdatedAt,
-	)
+	err := r.inTx(func(tx *TaskRepository) error {
+		err := tx.q.QueryRow(context.Background(), query, id).Scan(
+			&task.ID, &task.UUID, &task.Title, &task.Description, &task.Completed, &task.CreatedAt, &task.UpdatedAt,
+			&before.Completed, &before.UpdatedAt,
+		)
+		if err != nil {
+			return err
+		}
+
+		before.ID, before.UUID, before.Title, before.Description, before.CreatedAt =
+			task.ID, task.UUID, task.Title, task.Description, task.CreatedAt
+		return tx.enqueueEvent(kafka.ActionCompleteTask, &before, &task, start)
+	})
 	duration := time.Since(start).Milliseconds()
 	if err != nil {
-		if err == sql.ErrNoRows {
+		if errors.Is(err, pgx.ErrNoRows) {
 			r.log.Warn("task not found", "function", op, "id", id, "duration", duration)
 		} else {
 			r.log.ErrorWithContext("failed to complete task", err, op, "id", id, "duration", duration)
 		}
-		return nil, err
+		return nil, noRows(err)
 	}
 
 	// Обновляем кеш завершенной задачи (или добавляем, если ее не было)
-	r.setTaskCache(context.Background(), &task)
+	r.cacheSet(&task)
+	r.markWrite(task.ID)
+	r.invalidateLists()
 
 	r.log.LogResponse(op, task)
 	logQueryResult(r.log, op, duration, 1)
@@ -243,30 +572,48 @@ func (r *TaskRepository) DeleteTask(id int) error {
 	r.log.LogRequest(op, map[string]interface{}{"id": id})
 	start := time.Now()
 
-	query := `DELETE FROM tasks WHERE id = $1`
+	// Удаленная строка нужна для снимка before в событии
+	query := `DELETE FROM tasks WHERE id = $1
+			  RETURNING id, uuid, title, description, completed, created_at, updated_at`
 
 	logQuery(r.log, op, query, id)
-	res, err := r.db.Exec(query, id)
+
+	var deleted models.Task
+	err := r.inTx(func(tx *TaskRepository) error {
+		err := tx.q.QueryRow(context.Background(), query, id).Scan(
+			&deleted.ID, &deleted.UUID, &deleted.Title, &deleted.Description, &deleted.Completed, &deleted.CreatedAt, &deleted.UpdatedAt,
+		)
+		if err != nil {
+			return noRows(err)
+		}
+		return tx.enqueueEvent(kafka.ActionDeleteTask, &deleted, nil, start)
+	})
 	duration := time.Since(start).Milliseconds()
 
-	if err != nil {
-		r.log.ErrorWithContext("failed to delete task", err, op, "id", id, "duration", duration)
+	if errors.Is(err, sql.ErrNoRows) {
+		r.log.Warn("task not found for delete", "function", op, "id", id, "duration", duration)
 		return err
 	}
-	rowsAffected, err := res.RowsAffected()
 	if err != nil {
-		r.log.ErrorWithContext("failed to get rows affected", err, op, "id", id, "duration", duration)
+		r.log.ErrorWithContext("failed to delete task", err, op, "id", id, "duration", duration)
 		return err
 	}
-	if rowsAffected == 0 {
-		r.log.Warn("task not found for delete", "function", op, "id", id, "duration", duration)
-		return sql.ErrNoRows
-	}
 
-	// Удаляем задачу из кеша
-	r.deleteTaskCache(context.Background(), id)
+	// Заменяем задачу в кеше на tombstone
+	r.cacheDelete(id)
+	r.markWrite(id)
+	r.invalidateLists()
 
 	r.log.LogResponse(op, map[string]interface{}{"deleted": true, "id": id})
-	logQueryResult(r.log, op, duration, rowsAffected)
+	logQueryResult(r.log, op, duration, 1)
 	return nil
 }
+
+// noRows заменяет pgx.ErrNoRows на sql.ErrNoRows: сервисный слой и остальные
+// хранилища сообщают об отсутствии задачи через sql.ErrNoRows
+func noRows(err error) error {
+	if errors.Is(err, pgx.ErrNoRows) {
+		return sql.ErrNoRows
+	}
+	return err
+}
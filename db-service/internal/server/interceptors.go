@@ -0,0 +1,56 @@
+package server
+
+import (
+	"context"
+
+	"github.com/N0F1X3d/todo/pkg/kafka"
+	"github.com/N0F1X3d/todo/pkg/logger"
+	"github.com/N0F1X3d/todo/pkg/tlsconfig"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// PeerIdentityInterceptor логирует identity клиента (CN/SAN из сертификата при mTLS)
+// для каждого gRPC вызова. Сами обработчики получают identity через
+// tlsconfig.PeerIdentityFromContext(ctx).
+func PeerIdentityInterceptor(log *logger.Logger) grpc.UnaryServerInterceptor {
+	log = log.WithComponent("Server").WithFunction("PeerIdentityInterceptor")
+
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		if identity, ok := tlsconfig.PeerIdentityFromContext(ctx); ok {
+			log.Debug("grpc call",
+				"method", info.FullMethod,
+				"peer_cn", identity.CommonName,
+				"peer_dns_names", identity.DNSNames,
+				"peer_uris", identity.URIs,
+			)
+		}
+		return handler(ctx, req)
+	}
+}
+
+// EventMetaInterceptor переносит метаданные запроса из gRPC metadata (x-request-id,
+// x-trace-id, x-actor) в ctx, откуда их берут события задач и read-your-writes.
+// Actor - только identity клиента из сертификата; x-actor передает сам клиент,
+// поэтому он попадает в ForwardedActor и не подменяет проверенную identity.
+func EventMetaInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		md, _ := metadata.FromIncomingContext(ctx)
+		meta := kafka.EventMeta{
+			ForwardedActor: firstValue(md, kafka.MetadataActor),
+			RequestID:      firstValue(md, kafka.MetadataRequestID),
+			TraceID:        firstValue(md, kafka.MetadataTraceID),
+		}
+		if identity, ok := tlsconfig.PeerIdentityFromContext(ctx); ok {
+			meta.Actor = identity.CommonName
+		}
+		return handler(kafka.ContextWithEventMeta(ctx, meta), req)
+	}
+}
+
+func firstValue(md metadata.MD, key string) string {
+	if values := md.Get(key); len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
@@ -0,0 +1,60 @@
+package server_test
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"testing"
+
+	"github.com/N0F1X3d/todo/db-service/internal/server"
+	"github.com/N0F1X3d/todo/pkg/kafka"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+)
+
+func withPeerCN(ctx context.Context, cn string) context.Context {
+	cert := &x509.Certificate{Subject: pkix.Name{CommonName: cn}}
+	return peer.NewContext(ctx, &peer.Peer{AuthInfo: credentials.TLSInfo{
+		State: tls.ConnectionState{VerifiedChains: [][]*x509.Certificate{{cert}}},
+	}})
+}
+
+func runEventMetaInterceptor(t *testing.T, ctx context.Context) kafka.EventMeta {
+	t.Helper()
+
+	var meta kafka.EventMeta
+	_, err := server.EventMetaInterceptor()(ctx, nil, &grpc.UnaryServerInfo{}, func(ctx context.Context, req any) (any, error) {
+		meta = kafka.EventMetaFromContext(ctx)
+		return nil, nil
+	})
+	require.NoError(t, err)
+	return meta
+}
+
+func TestEventMetaInterceptor_ActorIsVerifiedPeer(t *testing.T) {
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(
+		kafka.MetadataActor, "db-admin",
+		kafka.MetadataRequestID, "req-1",
+	))
+	ctx = withPeerCN(ctx, "api-service")
+
+	meta := runEventMetaInterceptor(t, ctx)
+
+	assert.Equal(t, "api-service", meta.Actor)
+	assert.Equal(t, "db-admin", meta.ForwardedActor)
+	assert.Equal(t, "req-1", meta.RequestID)
+}
+
+func TestEventMetaInterceptor_XActorWithoutPeerIsNotActor(t *testing.T) {
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(kafka.MetadataActor, "alice"))
+
+	meta := runEventMetaInterceptor(t, ctx)
+
+	assert.Empty(t, meta.Actor)
+	assert.Equal(t, "alice", meta.ForwardedActor)
+}
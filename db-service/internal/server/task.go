@@ -2,12 +2,12 @@ package server
 
 import (
 	"context"
-	"time"
 
-	"github.com/N0F1X3d/todo/db-service/internal/models"
 	"github.com/N0F1X3d/todo/db-service/internal/service"
 	"github.com/N0F1X3d/todo/pkg/logger"
 	"github.com/N0F1X3d/todo/pkg/proto"
+	"github.com/N0F1X3d/todo/pkg/proto/convert"
+	pbv2 "github.com/N0F1X3d/todo/pkg/proto/v2"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 )
@@ -25,181 +25,86 @@ type TaskServerInterface interface {
 	proto.TaskServiceServer
 }
 
-// TaskServer реализует gRPC сервер для работы с базой данных
+// TaskServer реализует gRPC сервер контракта v1 (proto.TaskService) для совместимости
+// со старыми клиентами. Запросы выполняет TaskServerV2, ответы конвертируются в формат v1:
+// int32 id и время строкой RFC3339.
 type TaskServer struct {
 	proto.UnimplementedTaskServiceServer
-	service service.TaskServiceInterface
-	log     *logger.Logger
+	v2  *TaskServerV2
+	log *logger.Logger
 }
 
 // NewTaskServer
 func NewTaskServer(service service.TaskServiceInterface, log *logger.Logger) *TaskServer {
 	return &TaskServer{
-		service: service,
-		log:     log.WithComponent("Server").WithFunction("NewTaskServer"),
+		v2:  NewTaskServerV2(service, log),
+		log: log.WithComponent("Server").WithFunction("NewTaskServer"),
 	}
 }
 
 // CreateTask обрабатывает gRPC запрос на создание задачи
 func (s *TaskServer) CreateTask(ctx context.Context, req *proto.CreateTaskRequest) (*proto.TaskResponse, error) {
-	const op = "CreateTask"
-
-	s.log.LogRequest(op, map[string]interface{}{
-		"title":       req.GetTitle(),
-		"description": req.GetDescription(),
-	})
-
-	createReq := models.CreateTaskRequest{
+	task, err := s.v2.CreateTask(ctx, &pbv2.CreateTaskRequest{
 		Title:       req.GetTitle(),
 		Description: req.GetDescription(),
-	}
-
-	task, err := s.service.CreateTask(createReq)
-
+	})
 	if err != nil {
-		s.log.ErrorWithContext("failed to create task", err, op, "title", req.GetTitle(), "description", req.GetDescription())
-		// Конвертируем ошибки в статусы
-		switch err.Error() {
-		case "title can not be empty":
-			return nil, status.Error(codes.InvalidArgument, "title can not be empty")
-		case "title too long, maximum 255 characters":
-			return nil, status.Error(codes.InvalidArgument, "title too long, maximum 255 characters")
-		default:
-			return nil, status.Error(codes.Internal, "internal server error")
-		}
-	}
-
-	response := &proto.TaskResponse{
-		Id:          int32(task.ID),
-		Title:       task.Title,
-		Description: task.Description,
-		Completed:   task.Completed,
-		CreatedAt:   task.CreatedAt.Format(time.RFC3339),
-		UpdatedAt:   task.UpdatedAt.Format(time.RFC3339),
+		return nil, err
 	}
-
-	s.log.LogResponse(op, response)
-	return response, nil
+	return s.toV1("CreateTask", task)
 }
 
 // GetTaskByID обрабатывает gRPC запрос на поиск задачи по ID
 func (s *TaskServer) GetTaskByID(ctx context.Context, req *proto.GetTaskByIDRequest) (*proto.TaskResponse, error) {
-	const op = "GetTaskByID"
-
-	s.log.LogRequest(op, map[string]interface{}{"id": req.GetId()})
-
-	task, err := s.service.GetTaskByID(int(req.GetId()))
+	task, err := s.v2.GetTaskByID(ctx, &pbv2.GetTaskByIDRequest{Id: int64(req.GetId())})
 	if err != nil {
-		s.log.ErrorWithContext("failed to get task", err, op, "id", req.GetId())
-		switch err.Error() {
-		case "invalid task id":
-			return nil, status.Error(codes.InvalidArgument, "invalid task id")
-		case "task not found":
-			return nil, status.Error(codes.NotFound, "task not found")
-		default:
-			return nil, status.Error(codes.Internal, "internal server error")
-		}
+		return nil, err
 	}
-
-	response := &proto.TaskResponse{
-		Id:          int32(task.ID),
-		Title:       task.Title,
-		Description: task.Description,
-		Completed:   task.Completed,
-		CreatedAt:   task.CreatedAt.Format(time.RFC3339),
-		UpdatedAt:   task.UpdatedAt.Format(time.RFC3339),
-	}
-
-	s.log.LogResponse(op, response)
-
-	return response, nil
+	return s.toV1("GetTaskByID", task)
 }
 
 // GetAllTasks обрабатывает gRPC запрос на поиск всех задач
 func (s *TaskServer) GetAllTasks(ctx context.Context, req *proto.GetAllTasksRequest) (*proto.GetAllTasksResponse, error) {
 	const op = "GetAllTasks"
 
-	s.log.LogRequest(op, nil)
-
-	tasks, err := s.service.GetAllTasks()
+	resp, err := s.v2.GetAllTasks(ctx, &pbv2.GetAllTasksRequest{})
 	if err != nil {
-		s.log.ErrorWithContext("failed to get all tasks", err, op)
-		return nil, status.Error(codes.Internal, "internal server error")
-	}
-
-	response := &proto.GetAllTasksResponse{
-		Tasks: make([]*proto.TaskResponse, 0, len(tasks)),
+		return nil, err
 	}
 
-	for _, task := range tasks {
-		response.Tasks = append(response.Tasks, &proto.TaskResponse{
-			Id:          int32(task.ID),
-			Title:       task.Title,
-			Description: task.Description,
-			Completed:   task.Completed,
-			CreatedAt:   task.CreatedAt.Format(time.RFC3339),
-			UpdatedAt:   task.UpdatedAt.Format(time.RFC3339),
-		})
+	tasks, err := convert.TasksToV1(resp.GetTasks())
+	if err != nil {
+		s.log.ErrorWithContext("failed to convert tasks to v1", err, op)
+		return nil, status.Error(codes.OutOfRange, "task id out of range for v1 api, use v2")
 	}
 
-	s.log.LogResponse(op, map[string]interface{}{"tasks_count": len(tasks)})
-
-	return response, nil
+	return &proto.GetAllTasksResponse{Tasks: tasks}, nil
 }
 
 // CompleteTask обрабатывает gRPC запрос на завершение задачи по ID
 func (s *TaskServer) CompleteTask(ctx context.Context, req *proto.CompleteTaskRequest) (*proto.TaskResponse, error) {
-	const op = "CompleteTask"
-
-	s.log.LogRequest(op, map[string]interface{}{"id": req.GetId()})
-
-	task, err := s.service.CompleteTask(int(req.GetId()))
+	task, err := s.v2.CompleteTask(ctx, &pbv2.CompleteTaskRequest{Id: int64(req.GetId())})
 	if err != nil {
-		s.log.ErrorWithContext("failed to complete task", err, op, "task_id", req.GetId())
-		switch err.Error() {
-		case "invalid task id":
-			return nil, status.Error(codes.InvalidArgument, "invalid task id")
-		case "task not found":
-			return nil, status.Error(codes.NotFound, "task not found")
-		case "task already completed":
-			return nil, status.Error(codes.FailedPrecondition, "task already completed")
-		default:
-			return nil, status.Error(codes.Internal, "internal server error")
-		}
-	}
-
-	response := &proto.TaskResponse{
-		Id:          int32(task.ID),
-		Title:       task.Title,
-		Description: task.Description,
-		Completed:   task.Completed,
-		CreatedAt:   task.CreatedAt.Format(time.RFC3339),
-		UpdatedAt:   task.UpdatedAt.Format(time.RFC3339),
+		return nil, err
 	}
-
-	s.log.LogResponse(op, response)
-	return response, nil
+	return s.toV1("CompleteTask", task)
 }
 
 // DeleteTask обрабатывает gRPC запрос на удаление задачи по ID
 func (s *TaskServer) DeleteTask(ctx context.Context, req *proto.DeleteTaskRequest) (*proto.DeleteTaskResponse, error) {
-	const op = "DeleteTask"
-
-	s.log.LogRequest(op, map[string]interface{}{"id": req.GetId()})
-
-	err := s.service.DeleteTask(int(req.GetId()))
+	resp, err := s.v2.DeleteTask(ctx, &pbv2.DeleteTaskRequest{Id: int64(req.GetId())})
 	if err != nil {
-		s.log.ErrorWithContext("failed to delete task", err, op, "task_id", req.GetId())
-		switch err.Error() {
-		case "invalid id":
-			return nil, status.Error(codes.InvalidArgument, "invalid id")
-		case "failed to find task":
-			return nil, status.Error(codes.NotFound, "task not found")
-		default:
-			return nil, status.Error(codes.Internal, "internal server error")
-		}
+		return nil, err
 	}
+	return &proto.DeleteTaskResponse{Success: resp.GetSuccess()}, nil
+}
 
-	s.log.LogResponse(op, map[string]interface{}{"deleted": true, "task_id": req.GetId()})
-	return &proto.DeleteTaskResponse{Success: true}, nil
+// toV1 конвертирует задачу v2 в ответ v1. Задачи с id вне int32 в v1 недоступны.
+func (s *TaskServer) toV1(op string, task *pbv2.Task) (*proto.TaskResponse, error) {
+	response, err := convert.TaskToV1(task)
+	if err != nil {
+		s.log.ErrorWithContext("failed to convert task to v1", err, op, "task_id", task.GetId())
+		return nil, status.Error(codes.OutOfRange, "task id out of range for v1 api, use v2")
+	}
+	return response, nil
 }
@@ -22,7 +22,7 @@ func TestTaskServer_CreateTask_Success(t *testing.T) {
 	testLogger := logger.New("db-service", "test-logs")
 
 	createdTime := time.Now()
-	mockService.On("CreateTask", models.CreateTaskRequest{
+	mockService.On("CreateTask", mock.Anything, models.CreateTaskRequest{
 		Title:       "test task",
 		Description: "test desc",
 	}).Return(&models.Task{
@@ -55,7 +55,7 @@ func TestTaskServer_CreateTask_EmptyTitle(t *testing.T) {
 	mockService := mocks.NewTaskServiceInterface(t)
 	testLogger := logger.New("db-service", "test-logs")
 
-	mockService.On("CreateTask", models.CreateTaskRequest{
+	mockService.On("CreateTask", mock.Anything, models.CreateTaskRequest{
 		Title:       "",
 		Description: "test",
 	}).Return(nil, errors.New("title can not be empty"))
@@ -82,7 +82,7 @@ func TestTaskServer_CreateTask_TitleTooLong(t *testing.T) {
 	mockService := mocks.NewTaskServiceInterface(t)
 	testLogger := logger.New("db-service", "test-logs")
 
-	mockService.On("CreateTask", models.CreateTaskRequest{
+	mockService.On("CreateTask", mock.Anything, models.CreateTaskRequest{
 		Title:       string(make([]byte, 256)),
 		Description: "test",
 	}).Return(nil, errors.New("title too long, maximum 255 characters"))
@@ -108,7 +108,7 @@ func TestTaskServer_CreateTask_TitleTooLong(t *testing.T) {
 func TestTaskServer_CreateTask_InternalError(t *testing.T) {
 	mockService := mocks.NewTaskServiceInterface(t)
 	testLogger := logger.New("db-service", "test-logs")
-	mockService.On("CreateTask", mock.Anything).Return(nil, errors.New("error"))
+	mockService.On("CreateTask", mock.Anything, mock.Anything).Return(nil, errors.New("error"))
 
 	server := server.NewTaskServer(mockService, testLogger)
 
@@ -136,7 +136,7 @@ func TestTaskServer_GetTaskByID_Success(t *testing.T) {
 	createdTime := time.Now()
 	updatedTime := createdTime.Add(time.Hour)
 
-	mockService.On("GetTaskByID", 1).Return(&models.Task{
+	mockService.On("GetTaskByID", mock.Anything, 1).Return(&models.Task{
 		ID:          1,
 		Title:       "Test Task",
 		Description: "Test Description",
@@ -169,7 +169,7 @@ func TestTaskServer_GetTaskByID_InvalidID(t *testing.T) {
 	mockService := mocks.NewTaskServiceInterface(t)
 	testLogger := logger.New("db-service", "test-logs")
 
-	mockService.On("GetTaskByID", 0).Return(nil, errors.New("invalid task id"))
+	mockService.On("GetTaskByID", mock.Anything, 0).Return(nil, errors.New("invalid task id"))
 
 	server := server.NewTaskServer(mockService, testLogger)
 
@@ -195,7 +195,7 @@ func TestTaskServer_GetTaskByID_NotFound(t *testing.T) {
 	mockService := mocks.NewTaskServiceInterface(t)
 	testLogger := logger.New("db-service", "test-logs")
 
-	mockService.On("GetTaskByID", 999).Return(nil, errors.New("task not found"))
+	mockService.On("GetTaskByID", mock.Anything, 999).Return(nil, errors.New("task not found"))
 
 	server := server.NewTaskServer(mockService, testLogger)
 
@@ -221,7 +221,7 @@ func TestTaskServer_GetTaskByID_InternalError(t *testing.T) {
 	mockService := mocks.NewTaskServiceInterface(t)
 	testLogger := logger.New("db-service", "test-logs")
 
-	mockService.On("GetTaskByID", mock.Anything).Return(nil, errors.New("database error"))
+	mockService.On("GetTaskByID", mock.Anything, mock.Anything).Return(nil, errors.New("database error"))
 
 	server := server.NewTaskServer(mockService, testLogger)
 
@@ -266,7 +266,7 @@ func TestTaskServer_GetAllTasks_Success(t *testing.T) {
 		},
 	}
 
-	mockService.On("GetAllTasks").Return(tasks, nil)
+	mockService.On("GetAllTasks", mock.Anything).Return(tasks, nil)
 
 	server := server.NewTaskServer(mockService, testLogger)
 
@@ -291,7 +291,7 @@ func TestTaskServer_GetAllTasks_Empty(t *testing.T) {
 	mockService := mocks.NewTaskServiceInterface(t)
 	testLogger := logger.New("db-service", "test-logs")
 
-	mockService.On("GetAllTasks").Return([]models.Task{}, nil)
+	mockService.On("GetAllTasks", mock.Anything).Return([]models.Task{}, nil)
 
 	server := server.NewTaskServer(mockService, testLogger)
 
@@ -311,7 +311,7 @@ func TestTaskServer_GetAllTasks_InternalError(t *testing.T) {
 	mockService := mocks.NewTaskServiceInterface(t)
 	testLogger := logger.New("db-service", "test-logs")
 
-	mockService.On("GetAllTasks").Return(nil, errors.New("database error"))
+	mockService.On("GetAllTasks", mock.Anything).Return(nil, errors.New("database error"))
 
 	server := server.NewTaskServer(mockService, testLogger)
 
@@ -338,7 +338,7 @@ func TestTaskServer_CompleteTask_Success(t *testing.T) {
 	createdTime := time.Now()
 	completedTime := createdTime.Add(time.Hour)
 
-	mockService.On("CompleteTask", 1).Return(&models.Task{
+	mockService.On("CompleteTask", mock.Anything, 1).Return(&models.Task{
 		ID:          1,
 		Title:       "Test Task",
 		Description: "Test Description",
@@ -369,7 +369,7 @@ func TestTaskServer_CompleteTask_InvalidID(t *testing.T) {
 	mockService := mocks.NewTaskServiceInterface(t)
 	testLogger := logger.New("db-service", "test-logs")
 
-	mockService.On("CompleteTask", 0).Return(nil, errors.New("invalid task id"))
+	mockService.On("CompleteTask", mock.Anything, 0).Return(nil, errors.New("invalid task id"))
 
 	server := server.NewTaskServer(mockService, testLogger)
 
@@ -395,7 +395,7 @@ func TestTaskServer_CompleteTask_NotFound(t *testing.T) {
 	mockService := mocks.NewTaskServiceInterface(t)
 	testLogger := logger.New("db-service", "test-logs")
 
-	mockService.On("CompleteTask", 999).Return(nil, errors.New("task not found"))
+	mockService.On("CompleteTask", mock.Anything, 999).Return(nil, errors.New("task not found"))
 
 	server := server.NewTaskServer(mockService, testLogger)
 
@@ -421,7 +421,7 @@ func TestTaskServer_CompleteTask_AlreadyCompleted(t *testing.T) {
 	mockService := mocks.NewTaskServiceInterface(t)
 	testLogger := logger.New("db-service", "test-logs")
 
-	mockService.On("CompleteTask", 1).Return(nil, errors.New("task already completed"))
+	mockService.On("CompleteTask", mock.Anything, 1).Return(nil, errors.New("task already completed"))
 
 	server := server.NewTaskServer(mockService, testLogger)
 
@@ -447,7 +447,7 @@ func TestTaskServer_CompleteTask_InternalError(t *testing.T) {
 	mockService := mocks.NewTaskServiceInterface(t)
 	testLogger := logger.New("db-service", "test-logs")
 
-	mockService.On("CompleteTask", mock.Anything).Return(nil, errors.New("database error"))
+	mockService.On("CompleteTask", mock.Anything, mock.Anything).Return(nil, errors.New("database error"))
 
 	server := server.NewTaskServer(mockService, testLogger)
 
@@ -473,7 +473,7 @@ func TestTaskServer_DeleteTask_Success(t *testing.T) {
 	mockService := mocks.NewTaskServiceInterface(t)
 	testLogger := logger.New("db-service", "test-logs")
 
-	mockService.On("DeleteTask", 1).Return(nil)
+	mockService.On("DeleteTask", mock.Anything, 1).Return(nil)
 
 	server := server.NewTaskServer(mockService, testLogger)
 
@@ -495,7 +495,7 @@ func TestTaskServer_DeleteTask_InvalidID(t *testing.T) {
 	mockService := mocks.NewTaskServiceInterface(t)
 	testLogger := logger.New("db-service", "test-logs")
 
-	mockService.On("DeleteTask", 0).Return(errors.New("invalid id"))
+	mockService.On("DeleteTask", mock.Anything, 0).Return(errors.New("invalid id"))
 
 	server := server.NewTaskServer(mockService, testLogger)
 
@@ -521,7 +521,7 @@ func TestTaskServer_DeleteTask_NotFound(t *testing.T) {
 	mockService := mocks.NewTaskServiceInterface(t)
 	testLogger := logger.New("db-service", "test-logs")
 
-	mockService.On("DeleteTask", 999).Return(errors.New("failed to find task"))
+	mockService.On("DeleteTask", mock.Anything, 999).Return(errors.New("failed to find task"))
 
 	server := server.NewTaskServer(mockService, testLogger)
 
@@ -547,7 +547,7 @@ func TestTaskServer_DeleteTask_InternalError(t *testing.T) {
 	mockService := mocks.NewTaskServiceInterface(t)
 	testLogger := logger.New("db-service", "test-logs")
 
-	mockService.On("DeleteTask", mock.Anything).Return(errors.New("database error"))
+	mockService.On("DeleteTask", mock.Anything, mock.Anything).Return(errors.New("database error"))
 
 	server := server.NewTaskServer(mockService, testLogger)
 
@@ -0,0 +1,178 @@
+package server
+
+import (
+	"context"
+
+	"github.com/N0F1X3d/todo/db-service/internal/models"
+	"github.com/N0F1X3d/todo/db-service/internal/service"
+	"github.com/N0F1X3d/todo/pkg/logger"
+	"github.com/N0F1X3d/todo/pkg/proto/convert"
+	pbv2 "github.com/N0F1X3d/todo/pkg/proto/v2"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// TaskServerV2 реализует gRPC сервер задач по контракту proto/v2
+type TaskServerV2 struct {
+	pbv2.UnimplementedTaskServiceServer
+	service service.TaskServiceInterface
+	log     *logger.Logger
+}
+
+// NewTaskServerV2 создает сервер контракта v2
+func NewTaskServerV2(service service.TaskServiceInterface, log *logger.Logger) *TaskServerV2 {
+	return &TaskServerV2{
+		service: service,
+		log:     log.WithComponent("Server").WithFunction("NewTaskServerV2"),
+	}
+}
+
+// TaskToProto конвертирует задачу в сообщение proto/v2
+func TaskToProto(task *models.Task) *pbv2.Task {
+	if task == nil {
+		return nil
+	}
+
+	response := &pbv2.Task{
+		Id:          int64(task.ID),
+		Title:       task.Title,
+		Description: task.Description,
+		Completed:   task.Completed,
+		CreatedAt:   convert.Timestamp(task.CreatedAt),
+		UpdatedAt:   convert.Timestamp(task.UpdatedAt),
+	}
+	if task.UUID != "" {
+		uuid := task.UUID
+		response.Uuid = &uuid
+	}
+	return response
+}
+
+// CreateTask обрабатывает gRPC запрос на создание задачи
+func (s *TaskServerV2) CreateTask(ctx context.Context, req *pbv2.CreateTaskRequest) (*pbv2.Task, error) {
+	const op = "CreateTask"
+
+	s.log.LogRequest(op, map[string]interface{}{
+		"title":       req.GetTitle(),
+		"description": req.GetDescription(),
+	})
+
+	task, err := s.service.CreateTask(ctx, models.CreateTaskRequest{
+		Title:       req.GetTitle(),
+		Description: req.GetDescription(),
+	})
+	if err != nil {
+		s.log.ErrorWithContext("failed to create task", err, op, "title", req.GetTitle(), "description", req.GetDescription())
+		switch err.Error() {
+		case "title can not be empty":
+			return nil, status.Error(codes.InvalidArgument, "title can not be empty")
+		case "title too long, maximum 255 characters":
+			return nil, status.Error(codes.InvalidArgument, "title too long, maximum 255 characters")
+		default:
+			return nil, status.Error(codes.Internal, "internal server error")
+		}
+	}
+
+	response := TaskToProto(task)
+
+	s.log.LogResponse(op, response)
+	return response, nil
+}
+
+// GetTaskByID обрабатывает gRPC запрос на поиск задачи по ID
+func (s *TaskServerV2) GetTaskByID(ctx context.Context, req *pbv2.GetTaskByIDRequest) (*pbv2.Task, error) {
+	const op = "GetTaskByID"
+
+	s.log.LogRequest(op, map[string]interface{}{"id": req.GetId()})
+
+	task, err := s.service.GetTaskByID(ctx, int(req.GetId()))
+	if err != nil {
+		s.log.ErrorWithContext("failed to get task", err, op, "id", req.GetId())
+		switch err.Error() {
+		case "invalid task id":
+			return nil, status.Error(codes.InvalidArgument, "invalid task id")
+		case "task not found":
+			return nil, status.Error(codes.NotFound, "task not found")
+		default:
+			return nil, status.Error(codes.Internal, "internal server error")
+		}
+	}
+
+	response := TaskToProto(task)
+
+	s.log.LogResponse(op, response)
+	return response, nil
+}
+
+// GetAllTasks обрабатывает gRPC запрос на поиск всех задач
+func (s *TaskServerV2) GetAllTasks(ctx context.Context, req *pbv2.GetAllTasksRequest) (*pbv2.GetAllTasksResponse, error) {
+	const op = "GetAllTasks"
+
+	s.log.LogRequest(op, nil)
+
+	tasks, err := s.service.GetAllTasks(ctx)
+	if err != nil {
+		s.log.ErrorWithContext("failed to get all tasks", err, op)
+		return nil, status.Error(codes.Internal, "internal server error")
+	}
+
+	response := &pbv2.GetAllTasksResponse{
+		Tasks: make([]*pbv2.Task, 0, len(tasks)),
+	}
+	for i := range tasks {
+		response.Tasks = append(response.Tasks, TaskToProto(&tasks[i]))
+	}
+
+	s.log.LogResponse(op, map[string]interface{}{"tasks_count": len(tasks)})
+	return response, nil
+}
+
+// CompleteTask обрабатывает gRPC запрос на завершение задачи по ID
+func (s *TaskServerV2) CompleteTask(ctx context.Context, req *pbv2.CompleteTaskRequest) (*pbv2.Task, error) {
+	const op = "CompleteTask"
+
+	s.log.LogRequest(op, map[string]interface{}{"id": req.GetId()})
+
+	task, err := s.service.CompleteTask(ctx, int(req.GetId()))
+	if err != nil {
+		s.log.ErrorWithContext("failed to complete task", err, op, "task_id", req.GetId())
+		switch err.Error() {
+		case "invalid task id":
+			return nil, status.Error(codes.InvalidArgument, "invalid task id")
+		case "task not found":
+			return nil, status.Error(codes.NotFound, "task not found")
+		case "task already completed":
+			return nil, status.Error(codes.FailedPrecondition, "task already completed")
+		default:
+			return nil, status.Error(codes.Internal, "internal server error")
+		}
+	}
+
+	response := TaskToProto(task)
+
+	s.log.LogResponse(op, response)
+	return response, nil
+}
+
+// DeleteTask обрабатывает gRPC запрос на удаление задачи по ID
+func (s *TaskServerV2) DeleteTask(ctx context.Context, req *pbv2.DeleteTaskRequest) (*pbv2.DeleteTaskResponse, error) {
+	const op = "DeleteTask"
+
+	s.log.LogRequest(op, map[string]interface{}{"id": req.GetId()})
+
+	err := s.service.DeleteTask(ctx, int(req.GetId()))
+	if err != nil {
+		s.log.ErrorWithContext("failed to delete task", err, op, "task_id", req.GetId())
+		switch err.Error() {
+		case "invalid id":
+			return nil, status.Error(codes.InvalidArgument, "invalid id")
+		case "failed to find task":
+			return nil, status.Error(codes.NotFound, "task not found")
+		default:
+			return nil, status.Error(codes.Internal, "internal server error")
+		}
+	}
+
+	s.log.LogResponse(op, map[string]interface{}{"deleted": true, "task_id": req.GetId()})
+	return &pbv2.DeleteTaskResponse{Success: true}, nil
+}
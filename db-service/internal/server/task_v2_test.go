@@ -0,0 +1,92 @@
+package server_test
+
+import (
+	"context"
+	"errors"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/N0F1X3d/todo/db-service/internal/models"
+	"github.com/N0F1X3d/todo/db-service/internal/server"
+	"github.com/N0F1X3d/todo/db-service/mocks"
+	"github.com/N0F1X3d/todo/pkg/logger"
+	"github.com/N0F1X3d/todo/pkg/proto"
+	pbv2 "github.com/N0F1X3d/todo/pkg/proto/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestTaskServerV2_CreateTask_Success(t *testing.T) {
+	mockService := mocks.NewTaskServiceInterface(t)
+	testLogger := logger.New("db-service", "test-logs")
+
+	createdTime := time.Now()
+	mockService.On("CreateTask", mock.Anything, models.CreateTaskRequest{
+		Title:       "test task",
+		Description: "test desc",
+	}).Return(&models.Task{
+		ID:          1,
+		UUID:        "0b7e4c8e-6a0b-4b8f-9a77-5a1f0b7d2c11",
+		Title:       "test task",
+		Description: "test desc",
+		CreatedAt:   createdTime,
+		UpdatedAt:   createdTime,
+	}, nil)
+
+	server := server.NewTaskServerV2(mockService, testLogger)
+
+	resp, err := server.CreateTask(context.Background(), &pbv2.CreateTaskRequest{
+		Title:       "test task",
+		Description: "test desc",
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), resp.GetId())
+	assert.Equal(t, "0b7e4c8e-6a0b-4b8f-9a77-5a1f0b7d2c11", resp.GetUuid())
+	assert.True(t, createdTime.Equal(resp.GetCreatedAt().AsTime()))
+}
+
+func TestTaskServerV2_GetTaskByID_WithoutUUID(t *testing.T) {
+	mockService := mocks.NewTaskServiceInterface(t)
+	testLogger := logger.New("db-service", "test-logs")
+
+	mockService.On("GetTaskByID", mock.Anything, 1).Return(&models.Task{ID: 1, Title: "task"}, nil)
+
+	server := server.NewTaskServerV2(mockService, testLogger)
+
+	resp, err := server.GetTaskByID(context.Background(), &pbv2.GetTaskByIDRequest{Id: 1})
+
+	require.NoError(t, err)
+	assert.Nil(t, resp.Uuid)
+	assert.Nil(t, resp.GetCreatedAt())
+}
+
+func TestTaskServerV2_GetTaskByID_NotFound(t *testing.T) {
+	mockService := mocks.NewTaskServiceInterface(t)
+	testLogger := logger.New("db-service", "test-logs")
+
+	mockService.On("GetTaskByID", mock.Anything, 999).Return(nil, errors.New("task not found"))
+
+	server := server.NewTaskServerV2(mockService, testLogger)
+
+	_, err := server.GetTaskByID(context.Background(), &pbv2.GetTaskByIDRequest{Id: 999})
+
+	assert.Equal(t, codes.NotFound, status.Code(err))
+}
+
+func TestTaskServer_GetAllTasks_IDOutOfV1Range(t *testing.T) {
+	mockService := mocks.NewTaskServiceInterface(t)
+	testLogger := logger.New("db-service", "test-logs")
+
+	mockService.On("GetAllTasks", mock.Anything).Return([]models.Task{{ID: math.MaxInt32 + 1, Title: "task"}}, nil)
+
+	server := server.NewTaskServer(mockService, testLogger)
+
+	_, err := server.GetAllTasks(context.Background(), &proto.GetAllTasksRequest{})
+
+	assert.Equal(t, codes.OutOfRange, status.Code(err))
+}
@@ -1,6 +1,7 @@
 package service
 
 import (
+	"context"
 	"database/sql"
 	"errors"
 	"strings"
@@ -12,11 +13,11 @@ import (
 
 //go:generate mockery --name=TaskServiceInterface --filename=task_service_interface.go --output=../../mocks --case=underscore
 type TaskServiceInterface interface {
-	CreateTask(req models.CreateTaskRequest) (*models.Task, error)
-	GetTaskByID(id int) (*models.Task, error)
-	GetAllTasks() ([]models.Task, error)
-	CompleteTask(id int) (*models.Task, error)
-	DeleteTask(id int) error
+	CreateTask(ctx context.Context, req models.CreateTaskRequest) (*models.Task, error)
+	GetTaskByID(ctx context.Context, id int) (*models.Task, error)
+	GetAllTasks(ctx context.Context) ([]models.Task, error)
+	CompleteTask(ctx context.Context, id int) (*models.Task, error)
+	DeleteTask(ctx context.Context, id int) error
 }
 
 // TaskService предоставляет бизнес-логику для работы с задачами.
@@ -33,8 +34,9 @@ func NewTaskService(repo repository.TaskRepositoryInterface, log *logger.Logger)
 	}
 }
 
-// CreateTask создает новую задачу с применением бизнес-логики и валидации
-func (t *TaskService) CreateTask(req models.CreateTaskRequest) (*models.Task, error) {
+// CreateTask создает новую задачу с применением бизнес-логики и валидации.
+// Изменения выполняются в транзакции с ctx запроса: из него берутся метаданные события задачи.
+func (t *TaskService) CreateTask(ctx context.Context, req models.CreateTaskRequest) (*models.Task, error) {
 	const op = "CreateTask"
 
 	t.log.LogRequest(op, req)
@@ -49,7 +51,12 @@ func (t *TaskService) CreateTask(req models.CreateTaskRequest) (*models.Task, er
 		t.log.ErrorWithContext("validation failed", err, op, "request", req)
 		return nil, err
 	}
-	task, err := t.repo.CreateTask(req)
+	var task *models.Task
+	err := t.repo.WithTx(ctx, func(repo repository.TaskRepositoryInterface) error {
+		var err error
+		task, err = repo.CreateTask(req)
+		return err
+	})
 	if err != nil {
 		t.log.ErrorWithContext("failed to create task in repository", err, op, "request", req)
 		return nil, err
@@ -60,7 +67,7 @@ func (t *TaskService) CreateTask(req models.CreateTaskRequest) (*models.Task, er
 }
 
 // GetTaskByID возвращает задачу по ее ID
-func (t *TaskService) GetTaskByID(id int) (*models.Task, error) {
+func (t *TaskService) GetTaskByID(ctx context.Context, id int) (*models.Task, error) {
 	const op = "GetTaskByID"
 
 	t.log.LogRequest(op, map[string]interface{}{"id": id})
@@ -70,7 +77,7 @@ func (t *TaskService) GetTaskByID(id int) (*models.Task, error) {
 		t.log.ErrorWithContext("validation failed", err, op, "task_id", id)
 		return nil, err
 	}
-	task, err := t.repo.GetTaskByID(id)
+	task, err := t.repo.GetTaskByID(ctx, id)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			t.log.Warn("task not found", "function", op, "task_id", id)
@@ -86,11 +93,11 @@ func (t *TaskService) GetTaskByID(id int) (*models.Task, error) {
 }
 
 // GetAllTasks возвращает слайс всех задач
-func (t *TaskService) GetAllTasks() ([]models.Task, error) {
+func (t *TaskService) GetAllTasks(ctx context.Context) ([]models.Task, error) {
 	const op = "GetAllTasks"
 	t.log.LogRequest(op, nil)
 
-	tasks, err := t.repo.GetAllTasks()
+	tasks, err := t.repo.GetAllTasks(ctx)
 	if err != nil {
 		t.log.ErrorWithContext("database error", err, op)
 		return nil, errors.New("internal server error")
@@ -102,7 +109,7 @@ func (t *TaskService) GetAllTasks() ([]models.Task, error) {
 }
 
 // CompleteTask помечает задачу как выполненную
-func (t *TaskService) CompleteTask(id int) (*models.Task, error) {
+func (t *TaskService) CompleteTask(ctx context.Context, id int) (*models.Task, error) {
 	const op = "CompleteTask"
 
 	t.log.LogRequest(op, map[string]interface{}{"id": id})
@@ -113,21 +120,30 @@ func (t *TaskService) CompleteTask(id int) (*models.Task, error) {
 		return nil, err
 	}
 
-	task, err := t.repo.GetTaskByID(id)
-	if err != nil {
-		t.log.ErrorWithContext("task not found", err, op, "task_id", id)
-		return nil, err
-	}
+	// Проверка статуса и обновление выполняются в одной транзакции под блокировкой строки,
+	// чтобы параллельный запрос не завершил ту же задачу между ними
+	var taskCompleted *models.Task
+	err := t.repo.WithTx(ctx, func(repo repository.TaskRepositoryInterface) error {
+		task, err := repo.GetTaskByIDForUpdate(id)
+		if err != nil {
+			t.log.ErrorWithContext("task not found", err, op, "task_id", id)
+			return err
+		}
 
-	if task.Completed {
-		err := errors.New("task already completed")
-		t.log.ErrorWithContext("failed to complete task", err, op, "task_id", id, "current_status", task.Completed)
-		return nil, err
-	}
+		if task.Completed {
+			err := errors.New("task already completed")
+			t.log.ErrorWithContext("failed to complete task", err, op, "task_id", id, "current_status", task.Completed)
+			return err
+		}
 
-	taskCompleted, err := t.repo.CompleteTask(id)
+		taskCompleted, err = repo.CompleteTask(id)
+		if err != nil {
+			t.log.ErrorWithContext("failed to complete task", err, op, "task_id", id)
+			return err
+		}
+		return nil
+	})
 	if err != nil {
-		t.log.ErrorWithContext("failed to complete task", err, op, "task_id", id)
 		return nil, err
 	}
 
@@ -137,7 +153,7 @@ func (t *TaskService) CompleteTask(id int) (*models.Task, error) {
 }
 
 // DeleteTask удаляет задачу по id
-func (t *TaskService) DeleteTask(id int) error {
+func (t *TaskService) DeleteTask(ctx context.Context, id int) error {
 	const op = "DeleteTask"
 
 	t.log.LogRequest(op, map[string]interface{}{"id": id})
@@ -147,16 +163,23 @@ func (t *TaskService) DeleteTask(id int) error {
 		t.log.ErrorWithContext("validation error", err, op, "task_id", id)
 		return err
 	}
-	task, err := t.repo.GetTaskByID(id)
-	if err != nil {
-		err := errors.New("failed to find task")
-		t.log.ErrorWithContext("task not found", err, op, "task_id", id)
-		return err
-	}
+	var task *models.Task
+	err := t.repo.WithTx(ctx, func(repo repository.TaskRepositoryInterface) error {
+		var err error
+		task, err = repo.GetTaskByIDForUpdate(id)
+		if err != nil {
+			err := errors.New("failed to find task")
+			t.log.ErrorWithContext("task not found", err, op, "task_id", id)
+			return err
+		}
 
-	err = t.repo.DeleteTask(id)
+		if err := repo.DeleteTask(id); err != nil {
+			t.log.ErrorWithContext("failed to delete task", err, op, "task_id", id)
+			return err
+		}
+		return nil
+	})
 	if err != nil {
-		t.log.ErrorWithContext("failed to delete task", err, op, "task_id", id)
 		return err
 	}
 
@@ -0,0 +1,73 @@
+package service_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/N0F1X3d/todo/db-service/internal/models"
+	"github.com/N0F1X3d/todo/db-service/internal/repository/memory"
+	"github.com/N0F1X3d/todo/db-service/internal/service"
+	"github.com/N0F1X3d/todo/pkg/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newMemoryTaskService(t *testing.T) *service.TaskService {
+	t.Helper()
+
+	testLogger := logger.New("db-service", "test-logs")
+	return service.NewTaskService(memory.NewTaskRepository(testLogger), testLogger)
+}
+
+func TestTaskService_Memory_Lifecycle(t *testing.T) {
+	taskService := newMemoryTaskService(t)
+	ctx := context.Background()
+
+	created, err := taskService.CreateTask(ctx, models.CreateTaskRequest{Title: "task"})
+	require.NoError(t, err)
+
+	completed, err := taskService.CompleteTask(ctx, created.ID)
+	require.NoError(t, err)
+	assert.True(t, completed.Completed)
+
+	_, err = taskService.CompleteTask(ctx, created.ID)
+	assert.EqualError(t, err, "task already completed")
+
+	require.NoError(t, taskService.DeleteTask(ctx, created.ID))
+
+	_, err = taskService.GetTaskByID(ctx, created.ID)
+	assert.EqualError(t, err, "task not found")
+
+	err = taskService.DeleteTask(ctx, created.ID)
+	assert.EqualError(t, err, "failed to find task")
+}
+
+func TestTaskService_Memory_ConcurrentCompleteOnce(t *testing.T) {
+	taskService := newMemoryTaskService(t)
+	ctx := context.Background()
+
+	created, err := taskService.CreateTask(ctx, models.CreateTaskRequest{Title: "task"})
+	require.NoError(t, err)
+
+	const workers = 10
+	var (
+		wg        sync.WaitGroup
+		mu        sync.Mutex
+		successes int
+	)
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := taskService.CompleteTask(ctx, created.ID); err == nil {
+				mu.Lock()
+				successes++
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	assert.Equal(t, 1, successes)
+}
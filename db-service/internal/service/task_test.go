@@ -1,12 +1,14 @@
 package service_test
 
 import (
+	"context"
 	"database/sql"
 	"errors"
 	"testing"
 	"time"
 
 	"github.com/N0F1X3d/todo/db-service/internal/models"
+	"github.com/N0F1X3d/todo/db-service/internal/repository"
 	"github.com/N0F1X3d/todo/db-service/internal/service"
 	"github.com/N0F1X3d/todo/db-service/mocks"
 	"github.com/N0F1X3d/todo/pkg/logger"
@@ -14,8 +16,18 @@ import (
 	"github.com/stretchr/testify/mock"
 )
 
+// expectTx выполняет функцию транзакции на том же моке репозитория
+func expectTx(mockRepo *mocks.TaskRepositoryInterface) {
+	mockRepo.On("WithTx", mock.Anything, mock.Anything).Return(
+		func(ctx context.Context, fn func(repository.TaskRepositoryInterface) error) error {
+			return fn(mockRepo)
+		},
+	)
+}
+
 func TestTaskService_CreateTask_Success(t *testing.T) {
 	mockRepo := mocks.NewTaskRepositoryInterface(t)
+	expectTx(mockRepo)
 	mockRepo.On("CreateTask", mock.AnythingOfType("models.CreateTaskRequest")).Return(&models.Task{
 		ID:          1,
 		Title:       "test task",
@@ -32,7 +44,7 @@ func TestTaskService_CreateTask_Success(t *testing.T) {
 		Title:       "test task",
 		Description: "test description",
 	}
-	task, err := taskService.CreateTask(req)
+	task, err := taskService.CreateTask(context.Background(), req)
 
 	assert.NoError(t, err)
 	assert.NotNil(t, task)
@@ -47,7 +59,7 @@ func TestTaskService_CreateTask_EmptyTitle(t *testing.T) {
 	taskService := service.NewTaskService(mockRepo, testLogger)
 
 	req := models.CreateTaskRequest{Title: " "}
-	task, err := taskService.CreateTask(req)
+	task, err := taskService.CreateTask(context.Background(), req)
 
 	assert.Error(t, err)
 	assert.Nil(t, task)
@@ -61,7 +73,7 @@ func TestTaskService_CreateTask_TitleTooLong(t *testing.T) {
 
 	longTitle := string(make([]byte, 256))
 	req := models.CreateTaskRequest{Title: longTitle}
-	task, err := taskService.CreateTask(req)
+	task, err := taskService.CreateTask(context.Background(), req)
 
 	assert.Error(t, err)
 	assert.Nil(t, task)
@@ -70,7 +82,7 @@ func TestTaskService_CreateTask_TitleTooLong(t *testing.T) {
 
 func TestTaskService_GetTaskByID_Success(t *testing.T) {
 	mockRepo := mocks.NewTaskRepositoryInterface(t)
-	mockRepo.On("GetTaskByID", 1).Return(&models.Task{
+	mockRepo.On("GetTaskByID", mock.Anything, 1).Return(&models.Task{
 		ID:          1,
 		Title:       "test task",
 		Description: "test description",
@@ -80,7 +92,7 @@ func TestTaskService_GetTaskByID_Success(t *testing.T) {
 	testLogger := logger.New("db-service", "test-logs")
 	taskService := service.NewTaskService(mockRepo, testLogger)
 
-	task, err := taskService.GetTaskByID(1)
+	task, err := taskService.GetTaskByID(context.Background(), 1)
 
 	assert.NoError(t, err)
 	assert.NotNil(t, task)
@@ -93,7 +105,7 @@ func TestTaskService_GetTaskByID_InvalidTaskID(t *testing.T) {
 	testLogger := logger.New("db-service", "test-logs")
 	taskService := service.NewTaskService(mockRepo, testLogger)
 
-	task, err := taskService.GetTaskByID(0)
+	task, err := taskService.GetTaskByID(context.Background(), 0)
 
 	assert.Error(t, err)
 	assert.Nil(t, task)
@@ -102,11 +114,11 @@ func TestTaskService_GetTaskByID_InvalidTaskID(t *testing.T) {
 
 func TestTaskService_GetTaskByID_TaskNotFound(t *testing.T) {
 	mockRepo := mocks.NewTaskRepositoryInterface(t)
-	mockRepo.On("GetTaskByID", 999).Return(nil, sql.ErrNoRows)
+	mockRepo.On("GetTaskByID", mock.Anything, 999).Return(nil, sql.ErrNoRows)
 	testLogger := logger.New("db-service", "test-logs")
 	taskService := service.NewTaskService(mockRepo, testLogger)
 
-	task, err := taskService.GetTaskByID(999)
+	task, err := taskService.GetTaskByID(context.Background(), 999)
 
 	assert.Error(t, err)
 	assert.Nil(t, task)
@@ -119,11 +131,11 @@ func TestTaskService_GetAllTasks_Success(t *testing.T) {
 		{ID: 1, Title: "test task 1", Completed: false, CreatedAt: time.Now(), UpdatedAt: time.Now()},
 		{ID: 2, Title: "test task 2", Completed: true, CreatedAt: time.Now(), UpdatedAt: time.Now()},
 	}
-	mockRepo.On("GetAllTasks").Return(tasks, nil)
+	mockRepo.On("GetAllTasks", mock.Anything).Return(tasks, nil)
 	testLogger := logger.New("db-service", "test-logs")
 	taskService := service.NewTaskService(mockRepo, testLogger)
 
-	tasks, err := taskService.GetAllTasks()
+	tasks, err := taskService.GetAllTasks(context.Background())
 
 	assert.NoError(t, err)
 	assert.NotNil(t, tasks)
@@ -136,12 +148,12 @@ func TestTaskService_GetAllTasks_Success(t *testing.T) {
 
 func TestTaskService_GetAllTasks_DatabaseError(t *testing.T) {
 	mockRepo := mocks.NewTaskRepositoryInterface(t)
-	mockRepo.On("GetAllTasks").Return(nil, errors.New("any error"))
+	mockRepo.On("GetAllTasks", mock.Anything).Return(nil, errors.New("any error"))
 
 	testLogger := logger.New("db-service", "test-logs")
 	taskService := service.NewTaskService(mockRepo, testLogger)
 
-	tasks, err := taskService.GetAllTasks()
+	tasks, err := taskService.GetAllTasks(context.Background())
 
 	assert.Error(t, err)
 	assert.Nil(t, tasks)
@@ -152,13 +164,13 @@ func TestTaskService_GetAllTasks_EmptyList(t *testing.T) {
 	// Arrange
 	mockRepo := mocks.NewTaskRepositoryInterface(t)
 
-	mockRepo.On("GetAllTasks").Return([]models.Task{}, nil)
+	mockRepo.On("GetAllTasks", mock.Anything).Return([]models.Task{}, nil)
 
 	testLogger := logger.New("db-service", "test-logs")
 	taskService := service.NewTaskService(mockRepo, testLogger)
 
 	// Act
-	tasks, err := taskService.GetAllTasks()
+	tasks, err := taskService.GetAllTasks(context.Background())
 
 	// Assert
 	assert.NoError(t, err)
@@ -168,7 +180,8 @@ func TestTaskService_GetAllTasks_EmptyList(t *testing.T) {
 
 func TestTaskService_CompleteTask_Success(t *testing.T) {
 	mockRepo := mocks.NewTaskRepositoryInterface(t)
-	mockRepo.On("GetTaskByID", 1).Return(&models.Task{
+	expectTx(mockRepo)
+	mockRepo.On("GetTaskByIDForUpdate", 1).Return(&models.Task{
 		ID:        1,
 		Title:     "test task",
 		Completed: false,
@@ -184,7 +197,7 @@ func TestTaskService_CompleteTask_Success(t *testing.T) {
 	testLogger := logger.New("db-service", "test-logs")
 	taskService := service.NewTaskService(mockRepo, testLogger)
 
-	task, err := taskService.CompleteTask(1)
+	task, err := taskService.CompleteTask(context.Background(), 1)
 
 	assert.NoError(t, err)
 	assert.NotNil(t, task)
@@ -198,7 +211,7 @@ func TestTaskService_CompleteTask_InvalidID(t *testing.T) {
 	testLogger := logger.New("db-service", "test-logs")
 	taskService := service.NewTaskService(mockRepo, testLogger)
 
-	_, err := taskService.CompleteTask(0)
+	_, err := taskService.CompleteTask(context.Background(), 0)
 
 	assert.Error(t, err)
 	assert.Equal(t, "invalid task id", err.Error())
@@ -206,18 +219,19 @@ func TestTaskService_CompleteTask_InvalidID(t *testing.T) {
 
 func TestTaskService_CompleteTask_TaskNotFound(t *testing.T) {
 	mockRepo := mocks.NewTaskRepositoryInterface(t)
-	mockRepo.On("GetTaskByID", 99).Return(nil, sql.ErrNoRows)
+	mockRepo.On("GetTaskByID", mock.Anything, 99).Return(nil, sql.ErrNoRows)
 	testLogger := logger.New("db-service", "test-logs")
 	taskService := service.NewTaskService(mockRepo, testLogger)
 
-	_, err := taskService.GetTaskByID(99)
+	_, err := taskService.GetTaskByID(context.Background(), 99)
 
 	assert.Error(t, err)
 }
 
 func TestTaskService_CompleteTask_AlreadyCompleted(t *testing.T) {
 	mockRepo := mocks.NewTaskRepositoryInterface(t)
-	mockRepo.On("GetTaskByID", 1).Return(&models.Task{
+	expectTx(mockRepo)
+	mockRepo.On("GetTaskByIDForUpdate", 1).Return(&models.Task{
 		ID:        1,
 		Title:     "test",
 		Completed: true,
@@ -225,7 +239,7 @@ func TestTaskService_CompleteTask_AlreadyCompleted(t *testing.T) {
 	testLogger := logger.New("db-service", "test-logs")
 	taskService := service.NewTaskService(mockRepo, testLogger)
 
-	_, err := taskService.CompleteTask(1)
+	_, err := taskService.CompleteTask(context.Background(), 1)
 
 	assert.Error(t, err)
 	assert.Equal(t, "task already completed", err.Error())
@@ -234,8 +248,9 @@ func TestTaskService_CompleteTask_AlreadyCompleted(t *testing.T) {
 func TestTaskService_CompleteTask_CompleteError(t *testing.T) {
 	// Arrange
 	mockRepo := mocks.NewTaskRepositoryInterface(t)
+	expectTx(mockRepo)
 
-	mockRepo.On("GetTaskByID", 1).
+	mockRepo.On("GetTaskByIDForUpdate", 1).
 		Return(&models.Task{
 			ID:        1,
 			Title:     "Test Task",
@@ -249,7 +264,7 @@ func TestTaskService_CompleteTask_CompleteError(t *testing.T) {
 	taskService := service.NewTaskService(mockRepo, testLogger)
 
 	// Act
-	task, err := taskService.CompleteTask(1)
+	task, err := taskService.CompleteTask(context.Background(), 1)
 
 	// Assert
 	assert.Error(t, err)
@@ -259,7 +274,8 @@ func TestTaskService_CompleteTask_CompleteError(t *testing.T) {
 
 func TestTaskService_DeleteTask_Success(t *testing.T) {
 	mockRepo := mocks.NewTaskRepositoryInterface(t)
-	mockRepo.On("GetTaskByID", 1).Return(&models.Task{
+	expectTx(mockRepo)
+	mockRepo.On("GetTaskByIDForUpdate", 1).Return(&models.Task{
 		ID:    1,
 		Title: "test",
 	}, nil)
@@ -267,7 +283,7 @@ func TestTaskService_DeleteTask_Success(t *testing.T) {
 	testLogger := logger.New("db-service", "test-logs")
 	taskService := service.NewTaskService(mockRepo, testLogger)
 
-	err := taskService.DeleteTask(1)
+	err := taskService.DeleteTask(context.Background(), 1)
 
 	assert.NoError(t, err)
 }
@@ -277,7 +293,7 @@ func TestTaskService_DeleteTask_InvalidID(t *testing.T) {
 	testLogger := logger.New("db-service", "test-logs")
 	taskService := service.NewTaskService(mockRepo, testLogger)
 
-	err := taskService.DeleteTask(0)
+	err := taskService.DeleteTask(context.Background(), 0)
 
 	assert.Error(t, err)
 	assert.Equal(t, "invalid id", err.Error())
@@ -285,11 +301,12 @@ func TestTaskService_DeleteTask_InvalidID(t *testing.T) {
 
 func TestTaskService_DeleteTask_TaskNotFound(t *testing.T) {
 	mockRepo := mocks.NewTaskRepositoryInterface(t)
-	mockRepo.On("GetTaskByID", 99).Return(nil, sql.ErrNoRows)
+	expectTx(mockRepo)
+	mockRepo.On("GetTaskByIDForUpdate", 99).Return(nil, sql.ErrNoRows)
 	testLogger := logger.New("db-service", "test-logs")
 	taskService := service.NewTaskService(mockRepo, testLogger)
 
-	err := taskService.DeleteTask(99)
+	err := taskService.DeleteTask(context.Background(), 99)
 
 	assert.Error(t, err)
 	assert.Equal(t, "failed to find task", err.Error())
@@ -297,7 +314,8 @@ func TestTaskService_DeleteTask_TaskNotFound(t *testing.T) {
 
 func TestTaskService_DeleteTask_DeleteError(t *testing.T) {
 	mockRepo := mocks.NewTaskRepositoryInterface(t)
-	mockRepo.On("GetTaskByID", 1).Return(&models.Task{
+	expectTx(mockRepo)
+	mockRepo.On("GetTaskByIDForUpdate", 1).Return(&models.Task{
 		ID:    1,
 		Title: "test",
 	}, nil)
@@ -305,7 +323,7 @@ func TestTaskService_DeleteTask_DeleteError(t *testing.T) {
 	testLogger := logger.New("db-service", "test-logs")
 	taskService := service.NewTaskService(mockRepo, testLogger)
 
-	err := taskService.DeleteTask(1)
+	err := taskService.DeleteTask(context.Background(), 1)
 
 	assert.Error(t, err)
 	assert.Equal(t, "failed to delete task", err.Error())
@@ -313,11 +331,12 @@ func TestTaskService_DeleteTask_DeleteError(t *testing.T) {
 
 func TestTaskService_DeleteTask_GetTaskError(t *testing.T) {
 	mockRepo := mocks.NewTaskRepositoryInterface(t)
-	mockRepo.On("GetTaskByID", 1).Return(nil, errors.New("connection error"))
+	expectTx(mockRepo)
+	mockRepo.On("GetTaskByIDForUpdate", 1).Return(nil, errors.New("connection error"))
 	testLogger := logger.New("db-service", "test-logs")
 	taskService := service.NewTaskService(mockRepo, testLogger)
 
-	err := taskService.DeleteTask(1)
+	err := taskService.DeleteTask(context.Background(), 1)
 
 	assert.Error(t, err)
 	assert.Equal(t, "failed to find task", err.Error())
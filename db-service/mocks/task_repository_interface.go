@@ -3,8 +3,12 @@
 package mocks
 
 import (
+	context "context"
+
 	models "github.com/N0F1X3d/todo/db-service/internal/models"
 	mock "github.com/stretchr/testify/mock"
+
+	repository "github.com/N0F1X3d/todo/db-service/internal/repository"
 )
 
 // TaskRepositoryInterface is an autogenerated mock type for the TaskRepositoryInterface type
@@ -72,6 +76,34 @@ func (_m *TaskRepositoryInterface) CreateTask(req models.CreateTaskRequest) (*mo
 	return r0, r1
 }
 
+// CreateTasks provides a mock function with given fields: reqs
+func (_m *TaskRepositoryInterface) CreateTasks(reqs []models.CreateTaskRequest) (int64, error) {
+	ret := _m.Called(reqs)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CreateTasks")
+	}
+
+	var r0 int64
+	var r1 error
+	if rf, ok := ret.Get(0).(func([]models.CreateTaskRequest) (int64, error)); ok {
+		return rf(reqs)
+	}
+	if rf, ok := ret.Get(0).(func([]models.CreateTaskRequest) int64); ok {
+		r0 = rf(reqs)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	if rf, ok := ret.Get(1).(func([]models.CreateTaskRequest) error); ok {
+		r1 = rf(reqs)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // DeleteTask provides a mock function with given fields: id
 func (_m *TaskRepositoryInterface) DeleteTask(id int) error {
 	ret := _m.Called(id)
@@ -90,9 +122,9 @@ func (_m *TaskRepositoryInterface) DeleteTask(id int) error {
 	return r0
 }
 
-// GetAllTasks provides a mock function with no fields
-func (_m *TaskRepositoryInterface) GetAllTasks() ([]models.Task, error) {
-	ret := _m.Called()
+// GetAllTasks provides a mock function with given fields: ctx
+func (_m *TaskRepositoryInterface) GetAllTasks(ctx context.Context) ([]models.Task, error) {
+	ret := _m.Called(ctx)
 
 	if len(ret) == 0 {
 		panic("no return value specified for GetAllTasks")
@@ -100,19 +132,19 @@ func (_m *TaskRepositoryInterface) GetAllTasks() ([]models.Task, error) {
 
 	var r0 []models.Task
 	var r1 error
-	if rf, ok := ret.Get(0).(func() ([]models.Task, error)); ok {
-		return rf()
+	if rf, ok := ret.Get(0).(func(context.Context) ([]models.Task, error)); ok {
+		return rf(ctx)
 	}
-	if rf, ok := ret.Get(0).(func() []models.Task); ok {
-		r0 = rf()
+	if rf, ok := ret.Get(0).(func(context.Context) []models.Task); ok {
+		r0 = rf(ctx)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).([]models.Task)
 		}
 	}
 
-	if rf, ok := ret.Get(1).(func() error); ok {
-		r1 = rf()
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -120,14 +152,44 @@ func (_m *TaskRepositoryInterface) GetAllTasks() ([]models.Task, error) {
 	return r0, r1
 }
 
-// GetTaskByID provides a mock function with given fields: id
-func (_m *TaskRepositoryInterface) GetTaskByID(id int) (*models.Task, error) {
-	ret := _m.Called(id)
+// GetTaskByID provides a mock function with given fields: ctx, id
+func (_m *TaskRepositoryInterface) GetTaskByID(ctx context.Context, id int) (*models.Task, error) {
+	ret := _m.Called(ctx, id)
 
 	if len(ret) == 0 {
 		panic("no return value specified for GetTaskByID")
 	}
 
+	var r0 *models.Task
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int) (*models.Task, error)); ok {
+		return rf(ctx, id)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int) *models.Task); ok {
+		r0 = rf(ctx, id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.Task)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int) error); ok {
+		r1 = rf(ctx, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetTaskByIDForUpdate provides a mock function with given fields: id
+func (_m *TaskRepositoryInterface) GetTaskByIDForUpdate(id int) (*models.Task, error) {
+	ret := _m.Called(id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetTaskByIDForUpdate")
+	}
+
 	var r0 *models.Task
 	var r1 error
 	if rf, ok := ret.Get(0).(func(int) (*models.Task, error)); ok {
@@ -150,6 +212,24 @@ func (_m *TaskRepositoryInterface) GetTaskByID(id int) (*models.Task, error) {
 	return r0, r1
 }
 
+// WithTx provides a mock function with given fields: ctx, fn
+func (_m *TaskRepositoryInterface) WithTx(ctx context.Context, fn func(repository.TaskRepositoryInterface) error) error {
+	ret := _m.Called(ctx, fn)
+
+	if len(ret) == 0 {
+		panic("no return value specified for WithTx")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, func(repository.TaskRepositoryInterface) error) error); ok {
+		r0 = rf(ctx, fn)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // NewTaskRepositoryInterface creates a new instance of TaskRepositoryInterface. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
 // The first argument is typically a *testing.T value.
 func NewTaskRepositoryInterface(t interface {
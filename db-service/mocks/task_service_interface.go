@@ -3,6 +3,8 @@
 package mocks
 
 import (
+	context "context"
+
 	models "github.com/N0F1X3d/todo/db-service/internal/models"
 	mock "github.com/stretchr/testify/mock"
 )
@@ -12,9 +14,9 @@ type TaskServiceInterface struct {
 	mock.Mock
 }
 
-// CompleteTask provides a mock function with given fields: id
-func (_m *TaskServiceInterface) CompleteTask(id int) (*models.Task, error) {
-	ret := _m.Called(id)
+// CompleteTask provides a mock function with given fields: ctx, id
+func (_m *TaskServiceInterface) CompleteTask(ctx context.Context, id int) (*models.Task, error) {
+	ret := _m.Called(ctx, id)
 
 	if len(ret) == 0 {
 		panic("no return value specified for CompleteTask")
@@ -22,19 +24,19 @@ func (_m *TaskServiceInterface) CompleteTask(id int) (*models.Task, error) {
 
 	var r0 *models.Task
 	var r1 error
-	if rf, ok := ret.Get(0).(func(int) (*models.Task, error)); ok {
-		return rf(id)
+	if rf, ok := ret.Get(0).(func(context.Context, int) (*models.Task, error)); ok {
+		return rf(ctx, id)
 	}
-	if rf, ok := ret.Get(0).(func(int) *models.Task); ok {
-		r0 = rf(id)
+	if rf, ok := ret.Get(0).(func(context.Context, int) *models.Task); ok {
+		r0 = rf(ctx, id)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).(*models.Task)
 		}
 	}
 
-	if rf, ok := ret.Get(1).(func(int) error); ok {
-		r1 = rf(id)
+	if rf, ok := ret.Get(1).(func(context.Context, int) error); ok {
+		r1 = rf(ctx, id)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -42,9 +44,9 @@ func (_m *TaskServiceInterface) CompleteTask(id int) (*models.Task, error) {
 	return r0, r1
 }
 
-// CreateTask provides a mock function with given fields: req
-func (_m *TaskServiceInterface) CreateTask(req models.CreateTaskRequest) (*models.Task, error) {
-	ret := _m.Called(req)
+// CreateTask provides a mock function with given fields: ctx, req
+func (_m *TaskServiceInterface) CreateTask(ctx context.Context, req models.CreateTaskRequest) (*models.Task, error) {
+	ret := _m.Called(ctx, req)
 
 	if len(ret) == 0 {
 		panic("no return value specified for CreateTask")
@@ -52,19 +54,19 @@ func (_m *TaskServiceInterface) CreateTask(req models.CreateTaskRequest) (*model
 
 	var r0 *models.Task
 	var r1 error
-	if rf, ok := ret.Get(0).(func(models.CreateTaskRequest) (*models.Task, error)); ok {
-		return rf(req)
+	if rf, ok := ret.Get(0).(func(context.Context, models.CreateTaskRequest) (*models.Task, error)); ok {
+		return rf(ctx, req)
 	}
-	if rf, ok := ret.Get(0).(func(models.CreateTaskRequest) *models.Task); ok {
-		r0 = rf(req)
+	if rf, ok := ret.Get(0).(func(context.Context, models.CreateTaskRequest) *models.Task); ok {
+		r0 = rf(ctx, req)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).(*models.Task)
 		}
 	}
 
-	if rf, ok := ret.Get(1).(func(models.CreateTaskRequest) error); ok {
-		r1 = rf(req)
+	if rf, ok := ret.Get(1).(func(context.Context, models.CreateTaskRequest) error); ok {
+		r1 = rf(ctx, req)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -72,17 +74,17 @@ func (_m *TaskServiceInterface) CreateTask(req models.CreateTaskRequest) (*model
 	return r0, r1
 }
 
-// DeleteTask provides a mock function with given fields: id
-func (_m *TaskServiceInterface) DeleteTask(id int) error {
-	ret := _m.Called(id)
+// DeleteTask provides a mock function with given fields: ctx, id
+func (_m *TaskServiceInterface) DeleteTask(ctx context.Context, id int) error {
+	ret := _m.Called(ctx, id)
 
 	if len(ret) == 0 {
 		panic("no return value specified for DeleteTask")
 	}
 
 	var r0 error
-	if rf, ok := ret.Get(0).(func(int) error); ok {
-		r0 = rf(id)
+	if rf, ok := ret.Get(0).(func(context.Context, int) error); ok {
+		r0 = rf(ctx, id)
 	} else {
 		r0 = ret.Error(0)
 	}
@@ -90,9 +92,9 @@ func (_m *TaskServiceInterface) DeleteTask(id int) error {
 	return r0
 }
 
-// GetAllTasks provides a mock function with no fields
-func (_m *TaskServiceInterface) GetAllTasks() ([]models.Task, error) {
-	ret := _m.Called()
+// GetAllTasks provides a mock function with given fields: ctx
+func (_m *TaskServiceInterface) GetAllTasks(ctx context.Context) ([]models.Task, error) {
+	ret := _m.Called(ctx)
 
 	if len(ret) == 0 {
 		panic("no return value specified for GetAllTasks")
@@ -100,19 +102,19 @@ func (_m *TaskServiceInterface) GetAllTasks() ([]models.Task, error) {
 
 	var r0 []models.Task
 	var r1 error
-	if rf, ok := ret.Get(0).(func() ([]models.Task, error)); ok {
-		return rf()
+	if rf, ok := ret.Get(0).(func(context.Context) ([]models.Task, error)); ok {
+		return rf(ctx)
 	}
-	if rf, ok := ret.Get(0).(func() []models.Task); ok {
-		r0 = rf()
+	if rf, ok := ret.Get(0).(func(context.Context) []models.Task); ok {
+		r0 = rf(ctx)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).([]models.Task)
 		}
 	}
 
-	if rf, ok := ret.Get(1).(func() error); ok {
-		r1 = rf()
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -120,9 +122,9 @@ func (_m *TaskServiceInterface) GetAllTasks() ([]models.Task, error) {
 	return r0, r1
 }
 
-// GetTaskByID provides a mock function with given fields: id
-func (_m *TaskServiceInterface) GetTaskByID(id int) (*models.Task, error) {
-	ret := _m.Called(id)
+// GetTaskByID provides a mock function with given fields: ctx, id
+func (_m *TaskServiceInterface) GetTaskByID(ctx context.Context, id int) (*models.Task, error) {
+	ret := _m.Called(ctx, id)
 
 	if len(ret) == 0 {
 		panic("no return value specified for GetTaskByID")
@@ -130,19 +132,19 @@ func (_m *TaskServiceInterface) GetTaskByID(id int) (*models.Task, error) {
 
 	var r0 *models.Task
 	var r1 error
-	if rf, ok := ret.Get(0).(func(int) (*models.Task, error)); ok {
-		return rf(id)
+	if rf, ok := ret.Get(0).(func(context.Context, int) (*models.Task, error)); ok {
+		return rf(ctx, id)
 	}
-	if rf, ok := ret.Get(0).(func(int) *models.Task); ok {
-		r0 = rf(id)
+	if rf, ok := ret.Get(0).(func(context.Context, int) *models.Task); ok {
+		r0 = rf(ctx, id)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).(*models.Task)
 		}
 	}
 
-	if rf, ok := ret.Get(1).(func(int) error); ok {
-		r1 = rf(id)
+	if rf, ok := ret.Get(1).(func(context.Context, int) error); ok {
+		r1 = rf(ctx, id)
 	} else {
 		r1 = ret.Error(1)
 	}
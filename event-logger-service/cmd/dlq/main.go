@@ -0,0 +1,281 @@
+// Команда dlq показывает сообщения dead-letter топика событий задач
+// и возвращает их в основной топик.
+//
+//	dlq list   [-brokers ...] [-topic task-events] [-limit 100] [-values]
+//	dlq replay [-brokers ...] [-topic task-events] [-group ...] [-limit 0] [-wait 5s]
+//	dlq replay -partition 0 -offset 42
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"sort"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+
+	pkgKafka "github.com/N0F1X3d/todo/pkg/kafka"
+)
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+		os.Exit(2)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	var err error
+	switch os.Args[1] {
+	case "list":
+		err = runList(ctx, os.Args[2:])
+	case "replay":
+		err = runReplay(ctx, os.Args[2:])
+	default:
+		usage()
+		os.Exit(2)
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "dlq:", err)
+		os.Exit(1)
+	}
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "usage: dlq list|replay [flags]")
+}
+
+// commonFlags - брокеры и основной топик; по умолчанию из KAFKA_BROKERS и KAFKA_TOPIC
+type commonFlags struct {
+	brokers string
+	topic   string
+}
+
+func (f *commonFlags) register(fs *flag.FlagSet) {
+	fs.StringVar(&f.brokers, "brokers", envOr("KAFKA_BROKERS", "localhost:9092"), "брокеры Kafka через запятую")
+	fs.StringVar(&f.topic, "topic", envOr("KAFKA_TOPIC", "task-events"), "основной топик событий")
+}
+
+func (f *commonFlags) brokerList() []string {
+	return strings.Split(f.brokers, ",")
+}
+
+func envOr(key, fallback string) string {
+	if v := os.Getenv(key); v != "" {
+		return v
+	}
+	return fallback
+}
+
+func runList(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("list", flag.ExitOnError)
+	var common commonFlags
+	common.register(fs)
+	limit := fs.Int("limit", 100, "сколько сообщений показать (0 - все)")
+	values := fs.Bool("values", false, "печатать события")
+	_ = fs.Parse(args)
+
+	dlqTopic := pkgKafka.DeadLetterTopic(common.topic)
+	ranges, err := partitionRanges(ctx, common.brokerList(), dlqTopic)
+	if err != nil {
+		return err
+	}
+
+	shown := 0
+	for _, r := range ranges {
+		if r.FirstOffset >= r.LastOffset {
+			continue
+		}
+		n, err := listPartition(ctx, common.brokerList(), dlqTopic, r, *limit-shown, *values)
+		shown += n
+		if err != nil {
+			return err
+		}
+		if *limit > 0 && shown >= *limit {
+			break
+		}
+	}
+	if shown == 0 {
+		fmt.Println("dead-letter topic is empty")
+	}
+	return nil
+}
+
+// listPartition печатает сообщения партиции от первого до последнего offset (не больше limit, если limit > 0)
+func listPartition(ctx context.Context, brokers []string, topic string, r kafka.PartitionOffsets, limit int, values bool) (int, error) {
+	reader := kafka.NewReader(kafka.ReaderConfig{
+		Brokers:   brokers,
+		Topic:     topic,
+		Partition: r.Partition,
+	})
+	defer reader.Close()
+
+	if err := reader.SetOffset(r.FirstOffset); err != nil {
+		return 0, err
+	}
+
+	shown := 0
+	for limit <= 0 || shown < limit {
+		msg, err := reader.ReadMessage(ctx)
+		if err != nil {
+			return shown, fmt.Errorf("read %s partition %d: %w", topic, r.Partition, err)
+		}
+		printDeadLetter(pkgKafka.ParseDeadLetter(msg), values)
+		shown++
+		if msg.Offset >= r.LastOffset-1 {
+			break
+		}
+	}
+	return shown, nil
+}
+
+func printDeadLetter(dl pkgKafka.DeadLetter, withValue bool) {
+	fmt.Printf("%d/%d key=%s attempts=%d origin=%s/%d/%d failed_at=%s\n  error: %s\n",
+		dl.Message.Partition, dl.Message.Offset, dl.Message.Key, dl.Attempts,
+		dl.Topic, dl.Partition, dl.Offset, dl.FailedAt.Format(time.RFC3339), dl.Error)
+	if withValue {
+		fmt.Printf("  value: %s\n", dl.Message.Value)
+	}
+}
+
+func runReplay(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("replay", flag.ExitOnError)
+	var common commonFlags
+	common.register(fs)
+	group := fs.String("group", "", "consumer group для чтения DLQ (по умолчанию <topic>.dlq-replay)")
+	limit := fs.Int("limit", 0, "сколько сообщений вернуть (0 - все)")
+	wait := fs.Duration("wait", 5*time.Second, "завершиться, если новых сообщений нет столько времени")
+	partition := fs.Int("partition", -1, "вернуть одно сообщение: партиция DLQ")
+	offset := fs.Int64("offset", -1, "вернуть одно сообщение: offset в DLQ")
+	_ = fs.Parse(args)
+
+	writer := &kafka.Writer{
+		Addr:         kafka.TCP(common.brokerList()...),
+		Balancer:     &kafka.Hash{},
+		RequiredAcks: kafka.RequireAll,
+	}
+	defer writer.Close()
+
+	dlqTopic := pkgKafka.DeadLetterTopic(common.topic)
+
+	if *partition >= 0 || *offset >= 0 {
+		if *partition < 0 || *offset < 0 {
+			return errors.New("-partition and -offset must be set together")
+		}
+		msg, err := readAt(ctx, common.brokerList(), dlqTopic, *partition, *offset)
+		if err != nil {
+			return err
+		}
+		return replay(ctx, writer, common.topic, msg)
+	}
+
+	if *group == "" {
+		*group = dlqTopic + "-replay"
+	}
+	// Группа запоминает, что уже возвращено: повторный запуск не отправит сообщение дважды
+	reader := kafka.NewReader(kafka.ReaderConfig{
+		Brokers:     common.brokerList(),
+		Topic:       dlqTopic,
+		GroupID:     *group,
+		StartOffset: kafka.FirstOffset,
+	})
+	defer reader.Close()
+
+	replayed := 0
+	for *limit == 0 || replayed < *limit {
+		fetchCtx, cancel := context.WithTimeout(ctx, *wait)
+		msg, err := reader.FetchMessage(fetchCtx)
+		cancel()
+		if err != nil {
+			if ctx.Err() == nil && errors.Is(err, context.DeadlineExceeded) {
+				break
+			}
+			return err
+		}
+
+		if err := replay(ctx, writer, common.topic, msg); err != nil {
+			return err
+		}
+		if err := reader.CommitMessages(ctx, msg); err != nil {
+			return fmt.Errorf("commit %d/%d: %w", msg.Partition, msg.Offset, err)
+		}
+		replayed++
+	}
+	fmt.Printf("replayed %d message(s) to %s\n", replayed, common.topic)
+	return nil
+}
+
+func replay(ctx context.Context, writer *kafka.Writer, topic string, msg kafka.Message) error {
+	dl := pkgKafka.ParseDeadLetter(msg)
+	out := dl.ReplayMessage()
+	if out.Topic == "" {
+		out.Topic = topic
+	}
+	if err := writer.WriteMessages(ctx, out); err != nil {
+		return fmt.Errorf("replay %d/%d: %w", msg.Partition, msg.Offset, err)
+	}
+	fmt.Printf("%d/%d -> %s key=%s\n", msg.Partition, msg.Offset, out.Topic, out.Key)
+	return nil
+}
+
+// partitionRanges возвращает первый и следующий за последним offset каждой партиции топика
+func partitionRanges(ctx context.Context, brokers []string, topic string) ([]kafka.PartitionOffsets, error) {
+	client := &kafka.Client{Addr: kafka.TCP(brokers...)}
+
+	meta, err := client.Metadata(ctx, &kafka.MetadataRequest{Topics: []string{topic}})
+	if err != nil {
+		return nil, err
+	}
+	if len(meta.Topics) == 0 {
+		return nil, fmt.Errorf("topic %s not found", topic)
+	}
+	if meta.Topics[0].Error != nil {
+		return nil, fmt.Errorf("topic %s: %w", topic, meta.Topics[0].Error)
+	}
+
+	var requests []kafka.OffsetRequest
+	for _, p := range meta.Topics[0].Partitions {
+		requests = append(requests, kafka.FirstOffsetOf(p.ID), kafka.LastOffsetOf(p.ID))
+	}
+	resp, err := client.ListOffsets(ctx, &kafka.ListOffsetsRequest{
+		Topics: map[string][]kafka.OffsetRequest{topic: requests},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	ranges := resp.Topics[topic]
+	for _, r := range ranges {
+		if r.Error != nil {
+			return nil, fmt.Errorf("offsets of %s partition %d: %w", topic, r.Partition, r.Error)
+		}
+	}
+	sort.Slice(ranges, func(i, j int) bool { return ranges[i].Partition < ranges[j].Partition })
+	return ranges, nil
+}
+
+// readAt читает одно сообщение партиции по offset без consumer group
+func readAt(ctx context.Context, brokers []string, topic string, partition int, offset int64) (kafka.Message, error) {
+	reader := kafka.NewReader(kafka.ReaderConfig{
+		Brokers:   brokers,
+		Topic:     topic,
+		Partition: partition,
+	})
+	defer reader.Close()
+
+	if err := reader.SetOffset(offset); err != nil {
+		return kafka.Message{}, err
+	}
+	msg, err := reader.ReadMessage(ctx)
+	if err != nil {
+		return kafka.Message{}, fmt.Errorf("read %s %d/%d: %w", topic, partition, offset, err)
+	}
+	return msg, nil
+}
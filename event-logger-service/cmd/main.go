@@ -2,50 +2,157 @@ package main
 
 import (
 	"context"
+	"errors"
 	"log"
+	"net/http"
 	"os"
 	"os/signal"
 	"syscall"
+	"time"
 
+	"github.com/N0F1X3d/todo/event-logger-service/internal/api"
+	"github.com/N0F1X3d/todo/event-logger-service/internal/config"
+	"github.com/N0F1X3d/todo/event-logger-service/internal/eventlog"
+	"github.com/N0F1X3d/todo/event-logger-service/internal/stats"
+	"github.com/N0F1X3d/todo/event-logger-service/internal/store"
+	"github.com/N0F1X3d/todo/pkg/eventbus"
 	"github.com/N0F1X3d/todo/pkg/kafka"
+	"github.com/prometheus/client_golang/prometheus"
 )
 
 func main() {
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
-	// создаём файл логов
-	logFile, err := os.OpenFile(
-		"/logs/events.log",
-		os.O_CREATE|os.O_WRONLY|os.O_APPEND,
-		0666,
-	)
+	cfg, err := config.Load()
 	if err != nil {
-		log.Fatalf("failed to open log file: %v", err)
+		log.Fatalf("failed to load config: %v", err)
+	}
+
+	// Журнал событий; сообщения самого сервиса идут в stderr
+	logFile, err := eventlog.Open(cfg.EventLogOptions())
+	if err != nil {
+		log.Fatalf("failed to open event log: %v", err)
 	}
 	defer logFile.Close()
 
-	log.SetOutput(logFile)
+	eventLog, err := eventlog.NewLogger(logFile, cfg.LogFormat)
+	if err != nil {
+		log.Fatalf("failed to create event log: %v", err)
+	}
 
-	// graceful shutdown
+	// graceful shutdown; SIGHUP - переоткрыть журнал после внешней ротации
 	go func() {
 		sigCh := make(chan os.Signal, 1)
-		signal.Notify(sigCh, os.Interrupt, syscall.SIGTERM)
-		<-sigCh
-		cancel()
+		signal.Notify(sigCh, os.Interrupt, syscall.SIGTERM, syscall.SIGHUP)
+		for sig := range sigCh {
+			if sig == syscall.SIGHUP {
+				if err := logFile.Reopen(); err != nil {
+					log.Printf("failed to reopen event log: %v", err)
+				}
+				continue
+			}
+			cancel()
+			return
+		}
 	}()
 
-	brokers := []string{"kafka:9092"}
-	topic := "task-events"
-	groupID := "event-logger-group"
+	events, err := openStore(ctx, cfg)
+	if err != nil {
+		log.Fatalf("failed to open event store: %v", err)
+	}
+	defer events.Close()
+
+	aggregator := stats.NewAggregator(cfg.StatsWindow, stats.NewMetrics(prometheus.DefaultRegisterer, cfg.StatsWindow))
+	go runRollup(ctx, events, cfg.StatsRollupInterval)
+
+	server := &http.Server{
+		Addr:              cfg.HTTPAddr,
+		Handler:           api.NewHandler(events, aggregator).Routes(),
+		ReadHeaderTimeout: 5 * time.Second,
+	}
+	go func() {
+		if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Fatalf("failed to start http server: %v", err)
+		}
+	}()
+	defer func() {
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		_ = server.Shutdown(shutdownCtx)
+	}()
+
+	retry := kafka.DefaultRetryPolicy
+	retry.MaxAttempts = cfg.ConsumerMaxAttempts
+	consumerOpts := []kafka.ConsumerOption{
+		kafka.WithRetryPolicy(retry),
+		kafka.WithLagReport(cfg.ConsumerLagReport),
+		kafka.WithWorkers(cfg.ConsumerWorkers, cfg.ConsumerMaxInFlight),
+	}
+	if cfg.EventBus == eventbus.DriverKafka {
+		// Событие, которое не удалось записать за MaxAttempts попыток, уходит в <topic>.dlq
+		deadLetters := kafka.NewDeadLetterWriter(cfg.KafkaBrokers, cfg.KafkaTopic)
+		defer deadLetters.Close()
+		consumerOpts = append(consumerOpts, kafka.WithDeadLetterQueue(deadLetters))
+	}
 
-	consumer := kafka.NewConsumer(brokers, topic, groupID)
-	defer consumer.Close()
+	subscriber, err := eventbus.NewSubscriber(cfg.EventBusConfig(), consumerOpts...)
+	if err != nil {
+		log.Fatalf("failed to create subscriber: %v", err)
+	}
+	defer subscriber.Close()
 
-	log.Println("event-logger-service started...")
+	log.Printf("event-logger-service started (event bus: %s)...", cfg.EventBus)
 
-	consumer.Start(ctx, func(event kafka.TaskEvent) error {
-		log.Printf("EVENT: %+v\n", event)
+	err = subscriber.Subscribe(ctx, func(event kafka.TaskEvent) error {
+		// Повторно доставленное событие (at-least-once) не сохраняется и не пишется в лог
+		saved, err := events.Save(ctx, event)
+		if err != nil || !saved {
+			return err
+		}
+		aggregator.Observe(event, time.Now())
+		// Событие уже в task_events: ошибка журнала не повод читать его повторно
+		if err := eventLog.Log(event); err != nil {
+			log.Printf("failed to write event log: %v", err)
+		}
 		return nil
 	})
+	if err != nil {
+		log.Printf("subscriber stopped: %v", err)
+		return
+	}
+	log.Println("event-logger-service stopped")
+}
+
+// openStore открывает хранилище событий, выбранное EVENT_STORE_DRIVER
+func openStore(ctx context.Context, cfg *config.Config) (store.Store, error) {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	if cfg.StoreDriver == store.DriverPostgres {
+		return store.OpenPostgres(ctx, cfg.StoreDSN)
+	}
+	return store.OpenSQLite(ctx, cfg.StoreSQLitePath)
+}
+
+// runRollup раз в interval пересчитывает итоги вчерашнего и текущего дня,
+// пока ctx не отменен
+func runRollup(ctx context.Context, events store.Store, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		now := time.Now().UTC()
+		for _, day := range []time.Time{now.AddDate(0, 0, -1), now} {
+			if err := events.Rollup(ctx, day); err != nil && ctx.Err() == nil {
+				log.Printf("daily rollup %s: %v", day.Format(store.DayLayout), err)
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
 }
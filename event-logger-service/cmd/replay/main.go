@@ -0,0 +1,353 @@
+// Команда replay заново читает историю task-events во временной consumer group
+// и передает события выбранному обработчику, чтобы пересобрать производные данные.
+//
+//	replay [-handler print|store|stats|log] [-from-offset N | -since 2025-01-01T00:00:00Z]
+//	       [-dry-run] [-rate 0] [-limit 0] [-idle 10s] [-progress 5s]
+//
+// store и stats сохраняют события со временем записи в топик, поэтому история
+// попадает в свои дни; stats после прогона пересчитывает итоги этих дней
+// (task_events_daily). Брокеры, топик, хранилище и журнал берутся из тех же
+// переменных окружения, что и у event-logger-service.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"os/signal"
+	"sort"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+
+	"github.com/N0F1X3d/todo/event-logger-service/internal/config"
+	"github.com/N0F1X3d/todo/event-logger-service/internal/eventlog"
+	"github.com/N0F1X3d/todo/event-logger-service/internal/replay"
+	"github.com/N0F1X3d/todo/event-logger-service/internal/store"
+	pkgKafka "github.com/N0F1X3d/todo/pkg/kafka"
+)
+
+func main() {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	if err := run(ctx); err != nil {
+		fmt.Fprintln(os.Stderr, "replay:", err)
+		os.Exit(1)
+	}
+}
+
+func run(ctx context.Context) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+
+	fs := flag.NewFlagSet("replay", flag.ExitOnError)
+	brokers := fs.String("brokers", strings.Join(cfg.KafkaBrokers, ","), "брокеры Kafka через запятую")
+	topic := fs.String("topic", cfg.KafkaTopic, "топик событий")
+	group := fs.String("group", "", "consumer group прогона (по умолчанию <topic>.replay-<время>, удаляется после прогона)")
+	keepGroup := fs.Bool("keep-group", false, "не удалять consumer group после прогона")
+	handlerName := fs.String("handler", "print", "обработчик: print (stdout), store (task_events), stats (task_events и итоги дней) или log (журнал событий)")
+	fromOffset := fs.Int64("from-offset", -1, "начать с offset (в каждой партиции)")
+	since := fs.String("since", "", "начать с событий, записанных в топик не раньше времени (RFC 3339)")
+	dryRun := fs.Bool("dry-run", false, "только прочитать и посчитать события, не вызывая обработчик")
+	rate := fs.Float64("rate", 0, "не больше событий в секунду (0 - без ограничения)")
+	limit := fs.Int("limit", 0, "остановиться после стольких событий (0 - все)")
+	idle := fs.Duration("idle", 10*time.Second, "завершиться, если новых событий нет столько времени")
+	progress := fs.Duration("progress", 5*time.Second, "как часто печатать прогресс (0 - не печатать)")
+	verbose := fs.Bool("verbose", false, "печатать лог consumer по каждому сообщению")
+	_ = fs.Parse(os.Args[1:])
+
+	var sinceTime time.Time
+	if *since != "" {
+		if *fromOffset >= 0 {
+			return errors.New("-from-offset and -since are mutually exclusive")
+		}
+		if sinceTime, err = time.Parse(time.RFC3339, *since); err != nil {
+			return fmt.Errorf("-since must be an RFC 3339 timestamp: %w", err)
+		}
+	}
+	if !*verbose {
+		log.SetOutput(io.Discard)
+	}
+
+	handler, closeHandler, err := newHandler(ctx, cfg, *handlerName, *dryRun)
+	if err != nil {
+		return err
+	}
+	defer closeHandler()
+
+	brokerList := strings.Split(*brokers, ",")
+	client := &kafka.Client{Addr: kafka.TCP(brokerList...)}
+
+	if *group == "" {
+		*group = fmt.Sprintf("%s.replay-%d", *topic, time.Now().Unix())
+	}
+	starts, err := startOffsets(ctx, client, *topic, *fromOffset, sinceTime)
+	if err != nil {
+		return err
+	}
+	if err := commitOffsets(ctx, client, *group, *topic, starts); err != nil {
+		return err
+	}
+	if !*keepGroup {
+		defer deleteGroup(client, *group)
+	}
+
+	retry := pkgKafka.DefaultRetryPolicy
+	retry.MaxAttempts = cfg.ConsumerMaxAttempts
+	consumer := pkgKafka.NewConsumer(brokerList, *topic, *group,
+		pkgKafka.WithRetryPolicy(retry),
+		// Группа временная: частые коммиты не нужны
+		pkgKafka.WithBatchCommit(500, time.Second),
+	)
+	defer consumer.Close()
+
+	fmt.Printf("replaying %s from %s into %s (group %s)\n", *topic, describeStart(starts), *handlerName, *group)
+	if *dryRun {
+		fmt.Println("dry run: events are read but not handled")
+	}
+
+	// Лог consumer по умолчанию выключен, поэтому ошибки обработчика печатаются здесь
+	handle := func(event pkgKafka.TaskEvent) error {
+		if err := handler(event); err != nil {
+			fmt.Fprintf(os.Stderr, "replay: event %s: %v\n", event.EventID, err)
+			return err
+		}
+		return nil
+	}
+
+	result, err := replay.Run(ctx, consumer, handle, replay.Options{
+		DryRun:   *dryRun,
+		Rate:     *rate,
+		Limit:    *limit,
+		Idle:     *idle,
+		Progress: *progress,
+		Out:      os.Stdout,
+	})
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("replayed %d event(s) in %s\n", result.Events, result.Elapsed.Round(time.Millisecond))
+	actions := make([]string, 0, len(result.Actions))
+	for action := range result.Actions {
+		actions = append(actions, action)
+	}
+	sort.Strings(actions)
+	for _, action := range actions {
+		fmt.Printf("  %s: %d\n", action, result.Actions[action])
+	}
+	return nil
+}
+
+// newHandler создает обработчик по имени. В dry-run хранилище и журнал не открываются.
+func newHandler(ctx context.Context, cfg *config.Config, name string, dryRun bool) (func(pkgKafka.TaskEvent) error, func(), error) {
+	noop := func() {}
+	if dryRun {
+		switch name {
+		case "print", "store", "stats", "log":
+			return func(pkgKafka.TaskEvent) error { return nil }, noop, nil
+		}
+		return nil, noop, fmt.Errorf("unknown handler %q", name)
+	}
+
+	switch name {
+	case "print":
+		encoder := json.NewEncoder(os.Stdout)
+		return func(event pkgKafka.TaskEvent) error {
+			return encoder.Encode(event)
+		}, noop, nil
+
+	case "store", "stats":
+		events, err := openStore(ctx, cfg)
+		if err != nil {
+			return nil, noop, fmt.Errorf("open event store: %w", err)
+		}
+		// Сохранение идемпотентно: уже сохраненные события пропускаются
+		storeHandler := replay.NewStoreHandler(events)
+		handle := func(event pkgKafka.TaskEvent) error {
+			return storeHandler.Handle(ctx, event)
+		}
+		if name == "store" {
+			return handle, func() { _ = events.Close() }, nil
+		}
+		return handle, func() {
+			rollup(storeHandler)
+			_ = events.Close()
+		}, nil
+
+	case "log":
+		logFile, err := eventlog.Open(cfg.EventLogOptions())
+		if err != nil {
+			return nil, noop, fmt.Errorf("open event log: %w", err)
+		}
+		eventLog, err := eventlog.NewLogger(logFile, cfg.LogFormat)
+		if err != nil {
+			_ = logFile.Close()
+			return nil, noop, err
+		}
+		return eventLog.Log, func() { _ = logFile.Close() }, nil
+	}
+	return nil, noop, fmt.Errorf("unknown handler %q", name)
+}
+
+// rollup пересчитывает итоги дней прогона, в том числе прерванного:
+// ctx прогона к этому моменту может быть отменен
+func rollup(h *replay.StoreHandler) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	days, err := h.Rollup(ctx)
+	if len(days) > 0 {
+		fmt.Printf("rolled up %d day(s): %s\n", len(days), strings.Join(days, ", "))
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "replay: daily rollup: %v\n", err)
+	}
+}
+
+func openStore(ctx context.Context, cfg *config.Config) (store.Store, error) {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	if cfg.StoreDriver == store.DriverPostgres {
+		return store.OpenPostgres(ctx, cfg.StoreDSN)
+	}
+	return store.OpenSQLite(ctx, cfg.StoreSQLitePath)
+}
+
+// startOffsets возвращает offset начала чтения по партициям: с начала топика,
+// с fromOffset (в пределах партиции) или с первого сообщения не раньше since
+func startOffsets(ctx context.Context, client *kafka.Client, topic string, fromOffset int64, since time.Time) (map[int]int64, error) {
+	meta, err := client.Metadata(ctx, &kafka.MetadataRequest{Topics: []string{topic}})
+	if err != nil {
+		return nil, err
+	}
+	if len(meta.Topics) == 0 {
+		return nil, fmt.Errorf("topic %s not found", topic)
+	}
+	if meta.Topics[0].Error != nil {
+		return nil, fmt.Errorf("topic %s: %w", topic, meta.Topics[0].Error)
+	}
+
+	var requests []kafka.OffsetRequest
+	for _, p := range meta.Topics[0].Partitions {
+		requests = append(requests, kafka.FirstOffsetOf(p.ID), kafka.LastOffsetOf(p.ID))
+	}
+	ranges, err := listOffsets(ctx, client, topic, requests)
+	if err != nil {
+		return nil, err
+	}
+
+	starts := map[int]int64{}
+	ends := map[int]int64{}
+	for _, r := range ranges {
+		ends[r.Partition] = r.LastOffset
+		starts[r.Partition] = r.FirstOffset
+		if fromOffset >= 0 {
+			starts[r.Partition] = min(max(fromOffset, r.FirstOffset), r.LastOffset)
+		}
+	}
+	if since.IsZero() {
+		return starts, nil
+	}
+
+	// Запрос по времени отдельно: ответ без подходящего сообщения kafka-go
+	// не отличает от ответа на LastOffsetOf
+	requests = requests[:0]
+	for _, r := range ranges {
+		requests = append(requests, kafka.TimeOffsetOf(r.Partition, since))
+	}
+	found, err := listOffsets(ctx, client, topic, requests)
+	if err != nil {
+		return nil, err
+	}
+	for _, r := range found {
+		// Сообщений не раньше since нет - читать нечего
+		starts[r.Partition] = ends[r.Partition]
+		for offset := range r.Offsets {
+			if offset >= 0 {
+				starts[r.Partition] = offset
+			}
+		}
+	}
+	return starts, nil
+}
+
+func listOffsets(ctx context.Context, client *kafka.Client, topic string, requests []kafka.OffsetRequest) ([]kafka.PartitionOffsets, error) {
+	resp, err := client.ListOffsets(ctx, &kafka.ListOffsetsRequest{
+		Topics: map[string][]kafka.OffsetRequest{topic: requests},
+	})
+	if err != nil {
+		return nil, err
+	}
+	offsets := resp.Topics[topic]
+	for _, r := range offsets {
+		if r.Error != nil {
+			return nil, fmt.Errorf("offsets of %s partition %d: %w", topic, r.Partition, r.Error)
+		}
+	}
+	sort.Slice(offsets, func(i, j int) bool { return offsets[i].Partition < offsets[j].Partition })
+	return offsets, nil
+}
+
+// commitOffsets записывает начальные offset'ы в новую группу: consumer группы
+// начнет чтение с них
+func commitOffsets(ctx context.Context, client *kafka.Client, group, topic string, starts map[int]int64) error {
+	commits := make([]kafka.OffsetCommit, 0, len(starts))
+	for partition, offset := range starts {
+		commits = append(commits, kafka.OffsetCommit{Partition: partition, Offset: offset})
+	}
+	// Без участников у группы нет поколения: коммит идет с GenerationID -1
+	resp, err := client.OffsetCommit(ctx, &kafka.OffsetCommitRequest{
+		GroupID:      group,
+		GenerationID: -1,
+		Topics:       map[string][]kafka.OffsetCommit{topic: commits},
+	})
+	if err != nil {
+		return fmt.Errorf("commit start offsets for %s: %w", group, err)
+	}
+	for _, p := range resp.Topics[topic] {
+		if p.Error != nil {
+			return fmt.Errorf("commit start offset for %s partition %d: %w", group, p.Partition, p.Error)
+		}
+	}
+	return nil
+}
+
+// deleteGroup удаляет временную группу; ctx прогона к этому моменту может быть отменен
+func deleteGroup(client *kafka.Client, group string) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	resp, err := client.DeleteGroups(ctx, &kafka.DeleteGroupsRequest{GroupIDs: []string{group}})
+	if err == nil {
+		err = resp.Errors[group]
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "replay: delete group %s: %v\n", group, err)
+	}
+}
+
+func describeStart(starts map[int]int64) string {
+	partitions := make([]int, 0, len(starts))
+	for p := range starts {
+		partitions = append(partitions, p)
+	}
+	sort.Ints(partitions)
+
+	parts := make([]string, 0, len(partitions))
+	for _, p := range partitions {
+		parts = append(parts, fmt.Sprintf("%d/%d", p, starts[p]))
+	}
+	return strings.Join(parts, ",")
+}
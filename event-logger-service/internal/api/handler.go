@@ -0,0 +1,129 @@
+// Package api - HTTP API выборки сохраненных событий
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/N0F1X3d/todo/event-logger-service/internal/stats"
+	"github.com/N0F1X3d/todo/event-logger-service/internal/store"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// Handler отдает события из store.Store и агрегаты stats.Aggregator
+type Handler struct {
+	store store.Store
+	stats *stats.Aggregator
+}
+
+func NewHandler(st store.Store, agg *stats.Aggregator) *Handler {
+	return &Handler{store: st, stats: agg}
+}
+
+// Routes регистрирует маршруты API
+func (h *Handler) Routes() *http.ServeMux {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /events", h.ListEvents)
+	mux.HandleFunc("GET /stats", h.Stats)
+	mux.HandleFunc("GET /stats/daily", h.DailyStats)
+	mux.Handle("GET /metrics", promhttp.Handler())
+	return mux
+}
+
+// GET /events?task_id=&action=&from=&to=&limit=&page_token=
+// from и to - RFC 3339, выборка по полуинтервалу [from, to).
+func (h *Handler) ListEvents(w http.ResponseWriter, r *http.Request) {
+	filter, err := parseFilter(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	page, err := h.store.List(r.Context(), filter)
+	if errors.Is(err, store.ErrInvalidPageToken) {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		log.Printf("list events: %v", err)
+		http.Error(w, "Failed to list events", http.StatusInternalServerError)
+		return
+	}
+
+	writeJSON(w, page)
+}
+
+// GET /stats - агрегаты за скользящее окно
+func (h *Handler) Stats(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, h.stats.Snapshot(time.Now()))
+}
+
+// GET /stats/daily?from=&to= - итоги по дням (2006-01-02, включительно),
+// по умолчанию за последние 7 дней
+func (h *Handler) DailyStats(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	to := time.Now().UTC()
+	from := to.AddDate(0, 0, -6)
+
+	var err error
+	if v := q.Get("from"); v != "" {
+		if from, err = time.Parse(store.DayLayout, v); err != nil {
+			http.Error(w, "from must be a date (2006-01-02)", http.StatusBadRequest)
+			return
+		}
+	}
+	if v := q.Get("to"); v != "" {
+		if to, err = time.Parse(store.DayLayout, v); err != nil {
+			http.Error(w, "to must be a date (2006-01-02)", http.StatusBadRequest)
+			return
+		}
+	}
+
+	days, err := h.store.Daily(r.Context(), from, to)
+	if err != nil {
+		log.Printf("daily stats: %v", err)
+		http.Error(w, "Failed to get daily stats", http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, map[string]any{"days": days})
+}
+
+func writeJSON(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func parseFilter(r *http.Request) (store.Filter, error) {
+	q := r.URL.Query()
+	filter := store.Filter{
+		Action:    q.Get("action"),
+		PageToken: q.Get("page_token"),
+	}
+
+	var err error
+	if v := q.Get("task_id"); v != "" {
+		if filter.TaskID, err = strconv.Atoi(v); err != nil || filter.TaskID <= 0 {
+			return filter, errors.New("task_id must be a positive integer")
+		}
+	}
+	if v := q.Get("limit"); v != "" {
+		if filter.Limit, err = strconv.Atoi(v); err != nil || filter.Limit <= 0 {
+			return filter, errors.New("limit must be a positive integer")
+		}
+	}
+	if v := q.Get("from"); v != "" {
+		if filter.From, err = time.Parse(time.RFC3339, v); err != nil {
+			return filter, errors.New("from must be an RFC 3339 timestamp")
+		}
+	}
+	if v := q.Get("to"); v != "" {
+		if filter.To, err = time.Parse(time.RFC3339, v); err != nil {
+			return filter, errors.New("to must be an RFC 3339 timestamp")
+		}
+	}
+	return filter, nil
+}
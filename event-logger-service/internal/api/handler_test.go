@@ -0,0 +1,99 @@
+package api_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/N0F1X3d/todo/event-logger-service/internal/api"
+	"github.com/N0F1X3d/todo/event-logger-service/internal/stats"
+	"github.com/N0F1X3d/todo/event-logger-service/internal/store"
+	"github.com/N0F1X3d/todo/pkg/kafka"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandler_ListEvents(t *testing.T) {
+	ctx := context.Background()
+	st, err := store.OpenSQLite(ctx, filepath.Join(t.TempDir(), "events.db"))
+	require.NoError(t, err)
+	defer st.Close()
+
+	for _, taskID := range []int{1, 2, 1} {
+		event := kafka.NewTaskEvent(ctx, kafka.ActionCompleteTask, time.Now())
+		event.TaskID = taskID
+		_, err := st.Save(ctx, event)
+		require.NoError(t, err)
+	}
+
+	routes := api.NewHandler(st, stats.NewAggregator(time.Hour, nil)).Routes()
+
+	rec := httptest.NewRecorder()
+	routes.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/events?task_id=1&limit=1", nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	var page store.Page
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&page))
+	require.Len(t, page.Events, 1)
+	assert.Equal(t, 1, page.Events[0].TaskID)
+	require.NotEmpty(t, page.NextPageToken)
+
+	rec = httptest.NewRecorder()
+	routes.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/events?task_id=1&limit=1&page_token="+page.NextPageToken, nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	page = store.Page{}
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&page))
+	require.Len(t, page.Events, 1)
+	assert.Empty(t, page.NextPageToken)
+}
+
+func TestHandler_ListEvents_BadRequest(t *testing.T) {
+	st, err := store.OpenSQLite(context.Background(), filepath.Join(t.TempDir(), "events.db"))
+	require.NoError(t, err)
+	defer st.Close()
+
+	routes := api.NewHandler(st, stats.NewAggregator(time.Hour, nil)).Routes()
+
+	for _, query := range []string{"task_id=x", "limit=0", "from=yesterday", "page_token=abc"} {
+		rec := httptest.NewRecorder()
+		routes.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/events?"+query, nil))
+		assert.Equal(t, http.StatusBadRequest, rec.Code, query)
+	}
+}
+
+func TestHandler_Stats(t *testing.T) {
+	ctx := context.Background()
+	st, err := store.OpenSQLite(ctx, filepath.Join(t.TempDir(), "events.db"))
+	require.NoError(t, err)
+	defer st.Close()
+
+	agg := stats.NewAggregator(time.Hour, nil)
+	now := time.Now()
+	agg.Observe(kafka.NewTaskEvent(ctx, kafka.ActionCreateTask, now.Add(-10*time.Millisecond)), now)
+
+	routes := api.NewHandler(st, agg).Routes()
+
+	rec := httptest.NewRecorder()
+	routes.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/stats", nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	var snap stats.Snapshot
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&snap))
+	require.Len(t, snap.PerMinute, 1)
+	assert.Equal(t, 1, snap.PerMinute[0].Actions[kafka.ActionCreateTask])
+	assert.Equal(t, 1, snap.Latency.Count)
+
+	rec = httptest.NewRecorder()
+	routes.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/stats/daily?from=2025-01-01&to=2025-01-07", nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.JSONEq(t, `{"days": []}`, rec.Body.String())
+
+	rec = httptest.NewRecorder()
+	routes.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/stats/daily?from=monday", nil))
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+}
@@ -0,0 +1,95 @@
+package config
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/ilyakaznacheev/cleanenv"
+
+	"github.com/N0F1X3d/todo/event-logger-service/internal/eventlog"
+	"github.com/N0F1X3d/todo/event-logger-service/internal/store"
+	"github.com/N0F1X3d/todo/pkg/eventbus"
+)
+
+type Config struct {
+	// Журнал событий в файле: формат text или json, ротация по размеру и времени
+	LogPath        string        `env:"EVENT_LOG_PATH" env-default:"/logs/events.log"`
+	LogFormat      string        `env:"EVENT_LOG_FORMAT" env-default:"text"`
+	LogMaxSizeMB   int64         `env:"EVENT_LOG_MAX_SIZE_MB" env-default:"100"`
+	LogRotateEvery time.Duration `env:"EVENT_LOG_ROTATE_EVERY" env-default:"24h"`
+	LogMaxBackups  int           `env:"EVENT_LOG_MAX_BACKUPS" env-default:"7"`
+	LogCompress    bool          `env:"EVENT_LOG_COMPRESS" env-default:"true"`
+
+	// Хранилище событий: postgres или sqlite (локальный запуск без PostgreSQL)
+	StoreDriver     string `env:"EVENT_STORE_DRIVER" env-default:"sqlite"`
+	StoreDSN        string `env:"EVENT_STORE_DSN"`
+	StoreSQLitePath string `env:"EVENT_STORE_SQLITE_PATH" env-default:"events.db"`
+
+	// HTTPAddr - адрес API выборки событий
+	HTTPAddr string `env:"HTTP_ADDR" env-default:":8081"`
+
+	// StatsWindow - окно скользящих агрегатов /stats
+	StatsWindow time.Duration `env:"STATS_WINDOW" env-default:"1h"`
+	// StatsRollupInterval - как часто пересчитывать итоги дня в task_events_daily
+	StatsRollupInterval time.Duration `env:"STATS_ROLLUP_INTERVAL" env-default:"10m"`
+
+	// Шина событий: kafka, nats, memory или noop
+	EventBus     string   `env:"EVENT_BUS" env-default:"kafka"`
+	KafkaBrokers []string `env:"KAFKA_BROKERS" env-separator:"," env-default:"localhost:9092"`
+	KafkaTopic   string   `env:"KAFKA_TOPIC" env-default:"task-events"`
+	KafkaGroupID string   `env:"KAFKA_GROUP_ID" env-default:"event-logger-group"`
+	NATSURL      string   `env:"NATS_URL" env-default:"nats://localhost:4222"`
+
+	// Обработка событий Kafka
+	ConsumerWorkers     int           `env:"CONSUMER_WORKERS" env-default:"1"`
+	ConsumerMaxInFlight int           `env:"CONSUMER_MAX_IN_FLIGHT" env-default:"64"`
+	ConsumerMaxAttempts int           `env:"CONSUMER_MAX_ATTEMPTS" env-default:"5"`
+	ConsumerLagReport   time.Duration `env:"CONSUMER_LAG_REPORT" env-default:"30s"`
+}
+
+func Load() (*Config, error) {
+	var cfg Config
+	if err := cleanenv.ReadEnv(&cfg); err != nil {
+		return nil, fmt.Errorf("failed to load config: %w", err)
+	}
+	switch cfg.LogFormat {
+	case eventlog.FormatText, eventlog.FormatJSON:
+	default:
+		return nil, fmt.Errorf("unknown EVENT_LOG_FORMAT %q", cfg.LogFormat)
+	}
+	switch cfg.StoreDriver {
+	case store.DriverPostgres:
+		if cfg.StoreDSN == "" {
+			return nil, fmt.Errorf("EVENT_STORE_DSN is required for %s", store.DriverPostgres)
+		}
+	case store.DriverSQLite:
+	default:
+		return nil, fmt.Errorf("unknown EVENT_STORE_DRIVER %q", cfg.StoreDriver)
+	}
+	if cfg.ConsumerWorkers < 1 {
+		return nil, fmt.Errorf("CONSUMER_WORKERS must be positive, got %d", cfg.ConsumerWorkers)
+	}
+	return &cfg, nil
+}
+
+// EventLogOptions возвращает настройки ротации журнала событий
+func (c *Config) EventLogOptions() eventlog.Options {
+	return eventlog.Options{
+		Path:        c.LogPath,
+		MaxSize:     c.LogMaxSizeMB << 20,
+		RotateEvery: c.LogRotateEvery,
+		MaxBackups:  c.LogMaxBackups,
+		Compress:    c.LogCompress,
+	}
+}
+
+// EventBusConfig возвращает настройки шины событий
+func (c *Config) EventBusConfig() eventbus.Config {
+	return eventbus.Config{
+		Driver:       c.EventBus,
+		Topic:        c.KafkaTopic,
+		Group:        c.KafkaGroupID,
+		KafkaBrokers: c.KafkaBrokers,
+		NATSURL:      c.NATSURL,
+	}
+}
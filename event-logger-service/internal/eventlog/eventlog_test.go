@@ -0,0 +1,159 @@
+package eventlog_test
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/N0F1X3d/todo/event-logger-service/internal/eventlog"
+	"github.com/N0F1X3d/todo/pkg/kafka"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func readGzip(t *testing.T, path string) string {
+	t.Helper()
+
+	f, err := os.Open(path)
+	require.NoError(t, err)
+	defer f.Close()
+
+	zr, err := gzip.NewReader(f)
+	require.NoError(t, err)
+	data, err := io.ReadAll(zr)
+	require.NoError(t, err)
+	return string(data)
+}
+
+func TestFile_RotatesBySizeAndCompresses(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "events.log")
+	f, err := eventlog.Open(eventlog.Options{Path: path, MaxSize: 10, Compress: true})
+	require.NoError(t, err)
+	defer f.Close()
+
+	_, err = f.Write([]byte("line-1\n"))
+	require.NoError(t, err)
+	// Вторая строка не помещается в 10 байт: первая уходит в сжатый резервный файл
+	_, err = f.Write([]byte("line-2\n"))
+	require.NoError(t, err)
+
+	backups, err := f.Backups()
+	require.NoError(t, err)
+	require.Len(t, backups, 1)
+	assert.True(t, strings.HasSuffix(backups[0], ".gz"))
+	assert.Equal(t, "line-1\n", readGzip(t, backups[0]))
+
+	current, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "line-2\n", string(current))
+}
+
+func TestFile_KeepsMaxBackups(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "events.log")
+	f, err := eventlog.Open(eventlog.Options{Path: path, MaxBackups: 2})
+	require.NoError(t, err)
+	defer f.Close()
+
+	for i := range 4 {
+		_, err := f.Write([]byte{byte('a' + i), '\n'})
+		require.NoError(t, err)
+		require.NoError(t, f.Rotate())
+	}
+
+	backups, err := f.Backups()
+	require.NoError(t, err)
+	require.Len(t, backups, 2)
+
+	// Остаются самые новые файлы
+	oldest, err := os.ReadFile(backups[0])
+	require.NoError(t, err)
+	assert.Equal(t, "c\n", string(oldest))
+}
+
+func TestFile_RotatesFileFromPreviousPeriod(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "events.log")
+	require.NoError(t, os.WriteFile(path, []byte("yesterday\n"), 0o644))
+	yesterday := time.Now().Add(-24 * time.Hour)
+	require.NoError(t, os.Chtimes(path, yesterday, yesterday))
+
+	f, err := eventlog.Open(eventlog.Options{Path: path, RotateEvery: 24 * time.Hour})
+	require.NoError(t, err)
+	defer f.Close()
+
+	_, err = f.Write([]byte("today\n"))
+	require.NoError(t, err)
+
+	backups, err := f.Backups()
+	require.NoError(t, err)
+	require.Len(t, backups, 1)
+
+	current, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "today\n", string(current))
+}
+
+func TestFile_Reopen(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "events.log")
+	f, err := eventlog.Open(eventlog.Options{Path: path})
+	require.NoError(t, err)
+	defer f.Close()
+
+	_, err = f.Write([]byte("before\n"))
+	require.NoError(t, err)
+
+	// Внешняя ротация (logrotate) переименовывает файл и посылает SIGHUP
+	require.NoError(t, os.Rename(path, filepath.Join(dir, "events.log.1")))
+	require.NoError(t, f.Reopen())
+
+	_, err = f.Write([]byte("after\n"))
+	require.NoError(t, err)
+
+	current, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "after\n", string(current))
+
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o644), info.Mode().Perm())
+}
+
+func TestLogger_Formats(t *testing.T) {
+	event := kafka.NewTaskEvent(context.Background(), kafka.ActionCreateTask, time.Now())
+	event.TaskID = 7
+
+	var text bytes.Buffer
+	logger, err := eventlog.NewLogger(&text, eventlog.FormatText)
+	require.NoError(t, err)
+	require.NoError(t, logger.Log(event))
+	assert.Contains(t, text.String(), " EVENT: {")
+	assert.Contains(t, text.String(), event.EventID)
+
+	var lines bytes.Buffer
+	logger, err = eventlog.NewLogger(&lines, eventlog.FormatJSON)
+	require.NoError(t, err)
+	require.NoError(t, logger.Log(event))
+	require.NoError(t, logger.Log(event))
+
+	decoder := json.NewDecoder(&lines)
+	for range 2 {
+		var line struct {
+			LoggedAt time.Time       `json:"logged_at"`
+			Event    kafka.TaskEvent `json:"event"`
+		}
+		require.NoError(t, decoder.Decode(&line))
+		assert.Equal(t, event.EventID, line.Event.EventID)
+		assert.Equal(t, 7, line.Event.TaskID)
+		assert.False(t, line.LoggedAt.IsZero())
+	}
+
+	_, err = eventlog.NewLogger(&lines, "xml")
+	assert.Error(t, err)
+}
@@ -0,0 +1,242 @@
+// Package eventlog - журнал событий в файле с ротацией по размеру и времени,
+// сжатием старых файлов и переоткрытием по SIGHUP.
+package eventlog
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// backupLayout - суффикс имени ротированного файла: events.log.20250101T120000.000000000
+const backupLayout = "20060102T150405.000000000"
+
+// Options - настройки ротации
+type Options struct {
+	Path string
+	// MaxSize - размер файла в байтах, после которого он ротируется; 0 - без ограничения
+	MaxSize int64
+	// RotateEvery - период ротации по времени (24h - раз в сутки в полночь UTC); 0 - выключена
+	RotateEvery time.Duration
+	// MaxBackups - сколько ротированных файлов хранить; 0 - все
+	MaxBackups int
+	// Compress - сжимать ротированные файлы gzip
+	Compress bool
+}
+
+// File - io.Writer в файл Options.Path с ротацией. Безопасен для одновременного использования.
+type File struct {
+	opts Options
+	now  func() time.Time
+
+	mu   sync.Mutex
+	file *os.File
+	size int64
+	// period - начало периода RotateEvery, в котором открыт файл
+	period time.Time
+}
+
+// Open открывает (или создает) файл журнала
+func Open(opts Options) (*File, error) {
+	f := &File{opts: opts, now: time.Now}
+	if err := f.open(); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+func (f *File) Write(p []byte) (int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.file == nil {
+		return 0, os.ErrClosed
+	}
+	if f.shouldRotate(int64(len(p))) {
+		if err := f.rotate(); err != nil {
+			return 0, err
+		}
+	}
+	n, err := f.file.Write(p)
+	f.size += int64(n)
+	return n, err
+}
+
+// Rotate переименовывает текущий файл в резервный и начинает новый
+func (f *File) Rotate() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.rotate()
+}
+
+// Reopen закрывает и заново открывает файл по тому же пути: после внешней
+// ротации (logrotate) запись продолжается в новый файл
+func (f *File) Reopen() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.file != nil {
+		if err := f.file.Close(); err != nil {
+			return err
+		}
+		f.file = nil
+	}
+	return f.open()
+}
+
+func (f *File) Close() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.file == nil {
+		return nil
+	}
+	err := f.file.Close()
+	f.file = nil
+	return err
+}
+
+// open открывает файл на дозапись. Период существующего файла считается по
+// времени последней записи, чтобы после перезапуска ротация по времени не сдвигалась.
+func (f *File) open() error {
+	const op = "eventlog.open"
+
+	if err := os.MkdirAll(filepath.Dir(f.opts.Path), 0o755); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	file, err := os.OpenFile(f.opts.Path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	info, err := file.Stat()
+	if err != nil {
+		_ = file.Close()
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	f.file = file
+	f.size = info.Size()
+	f.period = f.periodOf(f.now())
+	if f.size > 0 {
+		f.period = f.periodOf(info.ModTime())
+	}
+	return nil
+}
+
+func (f *File) shouldRotate(next int64) bool {
+	if f.size == 0 {
+		return false
+	}
+	if f.opts.MaxSize > 0 && f.size+next > f.opts.MaxSize {
+		return true
+	}
+	return f.opts.RotateEvery > 0 && f.periodOf(f.now()).After(f.period)
+}
+
+func (f *File) periodOf(t time.Time) time.Time {
+	if f.opts.RotateEvery <= 0 {
+		return time.Time{}
+	}
+	return t.UTC().Truncate(f.opts.RotateEvery)
+}
+
+// rotate вызывается под mu
+func (f *File) rotate() error {
+	const op = "eventlog.rotate"
+
+	if f.file != nil {
+		if err := f.file.Close(); err != nil {
+			return fmt.Errorf("%s: %w", op, err)
+		}
+		f.file = nil
+	}
+
+	backup := f.opts.Path + "." + f.now().UTC().Format(backupLayout)
+	if err := os.Rename(f.opts.Path, backup); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if err := f.open(); err != nil {
+		return err
+	}
+
+	if f.opts.Compress {
+		if err := compress(backup); err != nil {
+			return fmt.Errorf("%s: %w", op, err)
+		}
+	}
+	return f.removeOld()
+}
+
+// compress сжимает path в path.gz и удаляет исходный файл
+func compress(path string) error {
+	src, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+	defer src.Close()
+
+	dst, err := os.OpenFile(path+".gz", os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o644)
+	if err != nil {
+		return err
+	}
+	zw := gzip.NewWriter(dst)
+	if _, err := io.Copy(zw, src); err != nil {
+		_ = dst.Close()
+		return err
+	}
+	if err := zw.Close(); err != nil {
+		_ = dst.Close()
+		return err
+	}
+	if err := dst.Close(); err != nil {
+		return err
+	}
+	return os.Remove(path)
+}
+
+// removeOld удаляет самые старые ротированные файлы сверх MaxBackups
+func (f *File) removeOld() error {
+	if f.opts.MaxBackups <= 0 {
+		return nil
+	}
+	backups, err := f.Backups()
+	if err != nil {
+		return err
+	}
+	for len(backups) > f.opts.MaxBackups {
+		if err := os.Remove(backups[0]); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		backups = backups[1:]
+	}
+	return nil
+}
+
+// Backups возвращает ротированные файлы от старых к новым
+func (f *File) Backups() ([]string, error) {
+	dir := filepath.Dir(f.opts.Path)
+	prefix := filepath.Base(f.opts.Path) + "."
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	var backups []string
+	for _, entry := range entries {
+		if !entry.IsDir() && strings.HasPrefix(entry.Name(), prefix) {
+			backups = append(backups, filepath.Join(dir, entry.Name()))
+		}
+	}
+	// Суффикс - время ротации, поэтому порядок имен совпадает с порядком ротаций
+	sort.Strings(backups)
+	return backups, nil
+}
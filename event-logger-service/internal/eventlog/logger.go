@@ -0,0 +1,68 @@
+package eventlog
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/N0F1X3d/todo/pkg/kafka"
+)
+
+// Форматы журнала
+const (
+	// FormatText - строка "<время> EVENT: <событие в JSON>"
+	FormatText = "text"
+	// FormatJSON - JSON lines: {"logged_at": ..., "event": {...}}
+	FormatJSON = "json"
+)
+
+// Logger пишет события в w в выбранном формате, по строке на событие
+type Logger struct {
+	w      io.Writer
+	format string
+
+	mu sync.Mutex
+}
+
+// NewLogger создает Logger формата format (text или json)
+func NewLogger(w io.Writer, format string) (*Logger, error) {
+	switch format {
+	case FormatText, FormatJSON:
+	default:
+		return nil, fmt.Errorf("unknown event log format %q", format)
+	}
+	return &Logger{w: w, format: format}, nil
+}
+
+type jsonLine struct {
+	LoggedAt time.Time       `json:"logged_at"`
+	Event    kafka.TaskEvent `json:"event"`
+}
+
+// Log записывает событие одной строкой
+func (l *Logger) Log(event kafka.TaskEvent) error {
+	now := time.Now().UTC()
+
+	var line []byte
+	if l.format == FormatJSON {
+		data, err := json.Marshal(jsonLine{LoggedAt: now, Event: event})
+		if err != nil {
+			return err
+		}
+		line = append(data, '\n')
+	} else {
+		data, err := json.Marshal(event)
+		if err != nil {
+			return err
+		}
+		line = fmt.Appendf(nil, "%s EVENT: %s\n", now.Format(time.RFC3339Nano), data)
+	}
+
+	// Строка пишется одним Write, чтобы ротация не разрезала ее
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	_, err := l.w.Write(line)
+	return err
+}
@@ -0,0 +1,168 @@
+// Package replay - повторное чтение истории событий из Kafka в обработчик,
+// чтобы пересобрать производные данные (task_events, журнал, статистику).
+package replay
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/N0F1X3d/todo/pkg/kafka"
+)
+
+// Options - настройки прогона
+type Options struct {
+	// DryRun - читать и считать события, не вызывая обработчик
+	DryRun bool
+	// Rate - не больше Rate событий в секунду; 0 - без ограничения
+	Rate float64
+	// Limit - остановиться после Limit событий; 0 - без ограничения
+	Limit int
+	// Idle - остановиться, если новых событий нет столько времени (история прочитана)
+	Idle time.Duration
+	// Progress - как часто писать прогресс в Out; 0 - не писать
+	Progress time.Duration
+	Out      io.Writer
+}
+
+// Result - итог прогона
+type Result struct {
+	Events  int
+	Actions map[string]int
+	Elapsed time.Duration
+}
+
+// Run читает события consumer и передает их handler, пока не кончится история
+// (Options.Idle без новых событий), не будет достигнут Options.Limit или не отменен ctx.
+// Ошибки handler повторяются по RetryPolicy consumer, как в основном сервисе.
+func Run(ctx context.Context, consumer *kafka.Consumer, handler func(kafka.TaskEvent) error, opts Options) (Result, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	r := &run{opts: opts, start: time.Now(), actions: map[string]int{}}
+	r.lastEvent = r.start
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		r.watch(ctx, cancel, consumer)
+	}()
+
+	err := consumer.Start(ctx, func(event kafka.TaskEvent) error {
+		if err := r.throttle(ctx); err != nil {
+			return err
+		}
+		if !opts.DryRun {
+			if err := handler(event); err != nil {
+				return err
+			}
+		}
+		if r.observe(event) {
+			cancel()
+		}
+		return nil
+	})
+	cancel()
+	<-done
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return Result{Events: r.events, Actions: r.actions, Elapsed: time.Since(r.start)}, err
+}
+
+type run struct {
+	opts  Options
+	start time.Time
+
+	mu        sync.Mutex
+	events    int
+	actions   map[string]int
+	lastEvent time.Time
+}
+
+// observe учитывает обработанное событие. Возвращает true, если достигнут Limit.
+func (r *run) observe(event kafka.TaskEvent) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.events++
+	r.actions[event.Action]++
+	r.lastEvent = time.Now()
+	return r.opts.Limit > 0 && r.events >= r.opts.Limit
+}
+
+// throttle ждет, пока очередное событие уложится в Rate
+func (r *run) throttle(ctx context.Context) error {
+	if r.opts.Rate <= 0 {
+		return nil
+	}
+	r.mu.Lock()
+	next := r.start.Add(time.Duration(float64(r.events) / r.opts.Rate * float64(time.Second)))
+	r.mu.Unlock()
+
+	timer := time.NewTimer(time.Until(next))
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// watch пишет прогресс и отменяет прогон, когда события перестали приходить
+func (r *run) watch(ctx context.Context, cancel context.CancelFunc, consumer *kafka.Consumer) {
+	var progress <-chan time.Time
+	if r.opts.Progress > 0 && r.opts.Out != nil {
+		ticker := time.NewTicker(r.opts.Progress)
+		defer ticker.Stop()
+		progress = ticker.C
+	}
+	var idle <-chan time.Time
+	if r.opts.Idle > 0 {
+		ticker := time.NewTicker(max(r.opts.Idle/10, 10*time.Millisecond))
+		defer ticker.Stop()
+		idle = ticker.C
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-progress:
+			r.report(consumer)
+		case <-idle:
+			r.mu.Lock()
+			quiet := time.Since(r.lastEvent)
+			r.mu.Unlock()
+			// Во время паузы Rate события не приходят, но история не кончилась
+			if quiet >= r.opts.Idle+r.rateInterval() {
+				cancel()
+				return
+			}
+		}
+	}
+}
+
+func (r *run) rateInterval() time.Duration {
+	if r.opts.Rate <= 0 {
+		return 0
+	}
+	return time.Duration(float64(time.Second) / r.opts.Rate)
+}
+
+func (r *run) report(consumer *kafka.Consumer) {
+	r.mu.Lock()
+	events := r.events
+	r.mu.Unlock()
+
+	var lag int64
+	for _, n := range consumer.Lag() {
+		lag += n
+	}
+	elapsed := time.Since(r.start)
+	_, _ = fmt.Fprintf(r.opts.Out, "replayed %d event(s) in %s (%.1f/s), lag %d\n",
+		events, elapsed.Round(time.Second), float64(events)/elapsed.Seconds(), lag)
+}
@@ -0,0 +1,145 @@
+package replay_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/N0F1X3d/todo/event-logger-service/internal/replay"
+	"github.com/N0F1X3d/todo/event-logger-service/internal/store"
+	"github.com/N0F1X3d/todo/pkg/kafka"
+	"github.com/N0F1X3d/todo/pkg/kafka/kafkatest"
+	kafkago "github.com/segmentio/kafka-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testTopic = "task-events"
+
+func produceEvents(t *testing.T, broker *kafkatest.Broker, actions ...string) {
+	t.Helper()
+
+	for _, action := range actions {
+		value, err := kafka.JSONCodec.Marshal(kafka.NewTaskEvent(context.Background(), action, time.Now()))
+		require.NoError(t, err)
+		broker.Produce(testTopic, kafkago.Message{
+			Key:     []byte("1"),
+			Value:   value,
+			Headers: []kafkago.Header{{Key: kafka.HeaderContentType, Value: []byte(kafka.ContentTypeJSON)}},
+		})
+	}
+}
+
+func newConsumer(broker *kafkatest.Broker) *kafka.Consumer {
+	return kafka.NewConsumerFromReader(broker.Reader(testTopic, "replay"),
+		kafka.WithRetryPolicy(kafka.RetryPolicy{MaxAttempts: 2, InitialBackoff: time.Millisecond, MaxBackoff: time.Millisecond}))
+}
+
+func TestRun_ReplaysUntilIdle(t *testing.T) {
+	broker := kafkatest.NewBroker(1)
+	produceEvents(t, broker, kafka.ActionCreateTask, kafka.ActionCreateTask, kafka.ActionDeleteTask)
+
+	var handled []string
+	var progress bytes.Buffer
+	result, err := replay.Run(context.Background(), newConsumer(broker), func(event kafka.TaskEvent) error {
+		handled = append(handled, event.Action)
+		return nil
+	}, replay.Options{Idle: 50 * time.Millisecond, Progress: 10 * time.Millisecond, Out: &progress})
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{kafka.ActionCreateTask, kafka.ActionCreateTask, kafka.ActionDeleteTask}, handled)
+	assert.Equal(t, 3, result.Events)
+	assert.Equal(t, map[string]int{kafka.ActionCreateTask: 2, kafka.ActionDeleteTask: 1}, result.Actions)
+	assert.Contains(t, progress.String(), "replayed 3 event(s)")
+}
+
+func TestRun_DryRunAndLimit(t *testing.T) {
+	broker := kafkatest.NewBroker(1)
+	produceEvents(t, broker, kafka.ActionCreateTask, kafka.ActionCompleteTask, kafka.ActionDeleteTask)
+
+	called := false
+	result, err := replay.Run(context.Background(), newConsumer(broker), func(kafka.TaskEvent) error {
+		called = true
+		return nil
+	}, replay.Options{DryRun: true, Limit: 2, Idle: time.Second})
+	require.NoError(t, err)
+
+	assert.False(t, called)
+	assert.Equal(t, 2, result.Events)
+	// Лимит останавливает прогон сразу, не дожидаясь Idle
+	assert.Less(t, result.Elapsed, time.Second)
+}
+
+func TestRun_RateLimit(t *testing.T) {
+	broker := kafkatest.NewBroker(1)
+	produceEvents(t, broker, kafka.ActionCreateTask, kafka.ActionCreateTask, kafka.ActionCreateTask)
+
+	result, err := replay.Run(context.Background(), newConsumer(broker), func(kafka.TaskEvent) error {
+		return nil
+	}, replay.Options{Rate: 20, Limit: 3})
+	require.NoError(t, err)
+
+	assert.Equal(t, 3, result.Events)
+	// 3 события при 20/s: второе и третье ждут по 50ms
+	assert.GreaterOrEqual(t, result.Elapsed, 100*time.Millisecond)
+}
+
+func TestRun_SkipsFailedEvents(t *testing.T) {
+	broker := kafkatest.NewBroker(1)
+	produceEvents(t, broker, kafka.ActionCreateTask, kafka.ActionDeleteTask)
+
+	result, err := replay.Run(context.Background(), newConsumer(broker), func(event kafka.TaskEvent) error {
+		if event.Action == kafka.ActionCreateTask {
+			return errors.New("boom")
+		}
+		return nil
+	}, replay.Options{Idle: 50 * time.Millisecond})
+	require.NoError(t, err)
+
+	assert.Equal(t, map[string]int{kafka.ActionDeleteTask: 1}, result.Actions)
+}
+
+func TestStoreHandler_KeepsHistoryInItsDays(t *testing.T) {
+	st, err := store.OpenSQLite(context.Background(), filepath.Join(t.TempDir(), "events.db"))
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = st.Close() })
+
+	broker := kafkatest.NewBroker(1)
+	published := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	for i, action := range []string{kafka.ActionCreateTask, kafka.ActionCreateTask, kafka.ActionDeleteTask} {
+		event := kafka.NewTaskEvent(context.Background(), action, published.Add(-time.Second))
+		value, err := kafka.JSONCodec.Marshal(event)
+		require.NoError(t, err)
+		broker.Produce(testTopic, kafkago.Message{
+			Key:     []byte("1"),
+			Value:   value,
+			Time:    published.AddDate(0, 0, i/2),
+			Headers: []kafkago.Header{{Key: kafka.HeaderContentType, Value: []byte(kafka.ContentTypeJSON)}},
+		})
+	}
+
+	handler := replay.NewStoreHandler(st)
+	_, err = replay.Run(context.Background(), newConsumer(broker), func(event kafka.TaskEvent) error {
+		return handler.Handle(context.Background(), event)
+	}, replay.Options{Idle: 50 * time.Millisecond})
+	require.NoError(t, err)
+
+	days, err := handler.Rollup(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, []string{"2025-01-01", "2025-01-02"}, days)
+
+	// События лежат в днях записи в топик, а не в дне прогона
+	daily, err := st.Daily(context.Background(), published, published.AddDate(0, 0, 1))
+	require.NoError(t, err)
+	require.Len(t, daily, 2)
+	assert.Equal(t, store.DailyStats{Day: "2025-01-01", Action: kafka.ActionCreateTask, Events: 2, LatencyP50: 1000, LatencyP95: 1000, LatencyP99: 1000}, daily[0])
+	assert.Equal(t, "2025-01-02", daily[1].Day)
+	assert.Equal(t, kafka.ActionDeleteTask, daily[1].Action)
+
+	today, err := st.Daily(context.Background(), time.Now(), time.Now())
+	require.NoError(t, err)
+	assert.Empty(t, today)
+}
@@ -0,0 +1,65 @@
+package replay
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/N0F1X3d/todo/event-logger-service/internal/store"
+	"github.com/N0F1X3d/todo/pkg/kafka"
+)
+
+// ReceivedAt - время получения события при повторном чтении: время записи в топик,
+// а если его нет - время события. Так история попадает в свои дни, а не в день прогона.
+func ReceivedAt(event kafka.TaskEvent) time.Time {
+	if !event.PublishedAt.IsZero() {
+		return event.PublishedAt
+	}
+	return event.OccurredAt
+}
+
+// StoreHandler сохраняет события в task_events со временем ReceivedAt
+// и запоминает дни, в которые они попали, для пересчета итогов
+type StoreHandler struct {
+	store store.Store
+
+	mu   sync.Mutex
+	days map[string]time.Time
+}
+
+func NewStoreHandler(st store.Store) *StoreHandler {
+	return &StoreHandler{store: st, days: map[string]time.Time{}}
+}
+
+// Handle сохраняет событие. Уже сохраненное событие пропускается, но его день
+// все равно пересчитывается.
+func (h *StoreHandler) Handle(ctx context.Context, event kafka.TaskEvent) error {
+	receivedAt := ReceivedAt(event).UTC()
+	if _, err := h.store.SaveAt(ctx, event, receivedAt); err != nil {
+		return err
+	}
+
+	h.mu.Lock()
+	h.days[receivedAt.Format(store.DayLayout)] = receivedAt
+	h.mu.Unlock()
+	return nil
+}
+
+// Rollup пересчитывает итоги дней, в которые попали события, и возвращает эти дни по порядку
+func (h *StoreHandler) Rollup(ctx context.Context) ([]string, error) {
+	h.mu.Lock()
+	days := make([]string, 0, len(h.days))
+	for day := range h.days {
+		days = append(days, day)
+	}
+	h.mu.Unlock()
+	sort.Strings(days)
+
+	for i, day := range days {
+		if err := h.store.Rollup(ctx, h.days[day]); err != nil {
+			return days[:i], err
+		}
+	}
+	return days, nil
+}
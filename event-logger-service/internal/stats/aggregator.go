@@ -0,0 +1,180 @@
+// Package stats - скользящие агрегаты по прочитанным событиям: число событий
+// по действиям за минуту и перцентили задержки от запроса к БД до чтения события.
+package stats
+
+import (
+	"math"
+	"math/rand/v2"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/N0F1X3d/todo/pkg/kafka"
+)
+
+// maxSamplesPerMinute ограничивает память под задержки одной минуты:
+// сверх него выборка пополняется reservoir sampling
+const maxSamplesPerMinute = 1024
+
+// Aggregator считает агрегаты за последние window минут
+type Aggregator struct {
+	window  time.Duration
+	metrics *Metrics
+
+	mu      sync.Mutex
+	minutes map[time.Time]*minuteBucket
+}
+
+type minuteBucket struct {
+	actions map[string]int
+	// latencies - выборка задержек по действиям, мс
+	latencies map[string][]float64
+	// observed - сколько задержек наблюдалось по действиям (для reservoir sampling)
+	observed map[string]int
+}
+
+// NewAggregator создает агрегатор с окном window. metrics может быть nil.
+func NewAggregator(window time.Duration, metrics *Metrics) *Aggregator {
+	return &Aggregator{
+		window:  window,
+		metrics: metrics,
+		minutes: map[time.Time]*minuteBucket{},
+	}
+}
+
+// Observe учитывает событие, прочитанное в consumedAt. Задержка считается
+// от DBRequestTime; события без него учитываются только в счетчиках.
+func (a *Aggregator) Observe(event kafka.TaskEvent, consumedAt time.Time) {
+	latency, hasLatency := Latency(event, consumedAt)
+	a.metrics.observe(event.Action, latency, hasLatency)
+
+	minute := consumedAt.UTC().Truncate(time.Minute)
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	b, ok := a.minutes[minute]
+	if !ok {
+		b = &minuteBucket{
+			actions:   map[string]int{},
+			latencies: map[string][]float64{},
+			observed:  map[string]int{},
+		}
+		a.minutes[minute] = b
+		a.evict(consumedAt)
+	}
+	b.actions[event.Action]++
+
+	if !hasLatency {
+		return
+	}
+	ms := float64(latency) / float64(time.Millisecond)
+	b.observed[event.Action]++
+	if samples := b.latencies[event.Action]; len(samples) < maxSamplesPerMinute {
+		b.latencies[event.Action] = append(samples, ms)
+	} else if i := rand.IntN(b.observed[event.Action]); i < maxSamplesPerMinute {
+		samples[i] = ms
+	}
+}
+
+// Latency возвращает задержку от запроса к БД до чтения события
+func Latency(event kafka.TaskEvent, consumedAt time.Time) (time.Duration, bool) {
+	if event.DBRequestTime.IsZero() {
+		return 0, false
+	}
+	// Часы сервисов могут расходиться: отрицательную задержку считаем нулевой
+	return max(consumedAt.Sub(event.DBRequestTime), 0), true
+}
+
+// evict удаляет минуты вне окна. Вызывается под mu.
+func (a *Aggregator) evict(now time.Time) {
+	oldest := now.UTC().Truncate(time.Minute).Add(-a.window)
+	for minute := range a.minutes {
+		if !minute.After(oldest) {
+			delete(a.minutes, minute)
+		}
+	}
+}
+
+// Snapshot - агрегаты за окно
+type Snapshot struct {
+	WindowMinutes int            `json:"window_minutes"`
+	GeneratedAt   time.Time      `json:"generated_at"`
+	PerMinute     []MinuteCounts `json:"per_minute"`
+	// Latency - задержка по всем действиям
+	Latency         Percentiles            `json:"latency"`
+	LatencyByAction map[string]Percentiles `json:"latency_by_action"`
+}
+
+// MinuteCounts - число событий по действиям за минуту
+type MinuteCounts struct {
+	Minute  time.Time      `json:"minute"`
+	Actions map[string]int `json:"actions"`
+}
+
+// Percentiles - перцентили задержки в миллисекундах
+type Percentiles struct {
+	// Count - размер выборки (не больше 1024 задержек на минуту и действие)
+	Count int     `json:"count"`
+	P50   float64 `json:"p50_ms"`
+	P95   float64 `json:"p95_ms"`
+	P99   float64 `json:"p99_ms"`
+}
+
+// Snapshot возвращает агрегаты за окно, заканчивающееся в now
+func (a *Aggregator) Snapshot(now time.Time) Snapshot {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.evict(now)
+
+	snap := Snapshot{
+		WindowMinutes:   int(a.window / time.Minute),
+		GeneratedAt:     now.UTC(),
+		PerMinute:       make([]MinuteCounts, 0, len(a.minutes)),
+		LatencyByAction: map[string]Percentiles{},
+	}
+
+	var all []float64
+	byAction := map[string][]float64{}
+	for minute, b := range a.minutes {
+		counts := MinuteCounts{Minute: minute, Actions: make(map[string]int, len(b.actions))}
+		for action, n := range b.actions {
+			counts.Actions[action] = n
+		}
+		snap.PerMinute = append(snap.PerMinute, counts)
+
+		for action, samples := range b.latencies {
+			all = append(all, samples...)
+			byAction[action] = append(byAction[action], samples...)
+		}
+	}
+	sort.Slice(snap.PerMinute, func(i, j int) bool {
+		return snap.PerMinute[i].Minute.Before(snap.PerMinute[j].Minute)
+	})
+
+	snap.Latency = ComputePercentiles(all)
+	for action, samples := range byAction {
+		snap.LatencyByAction[action] = ComputePercentiles(samples)
+	}
+	return snap
+}
+
+// ComputePercentiles считает p50/p95/p99 методом ближайшего ранга. samples сортируется.
+func ComputePercentiles(samples []float64) Percentiles {
+	if len(samples) == 0 {
+		return Percentiles{}
+	}
+	sort.Float64s(samples)
+	return Percentiles{
+		Count: len(samples),
+		P50:   percentile(samples, 0.50),
+		P95:   percentile(samples, 0.95),
+		P99:   percentile(samples, 0.99),
+	}
+}
+
+func percentile(sorted []float64, p float64) float64 {
+	rank := int(math.Ceil(p*float64(len(sorted)))) - 1
+	return sorted[min(max(rank, 0), len(sorted)-1)]
+}
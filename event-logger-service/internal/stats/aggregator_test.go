@@ -0,0 +1,77 @@
+package stats_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/N0F1X3d/todo/event-logger-service/internal/stats"
+	"github.com/N0F1X3d/todo/pkg/kafka"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func eventAt(action string, dbRequestTime time.Time) kafka.TaskEvent {
+	return kafka.NewTaskEvent(context.Background(), action, dbRequestTime)
+}
+
+func TestAggregator_CountsPerMinute(t *testing.T) {
+	agg := stats.NewAggregator(time.Hour, nil)
+	base := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	agg.Observe(eventAt(kafka.ActionCreateTask, base), base.Add(10*time.Second))
+	agg.Observe(eventAt(kafka.ActionCreateTask, base), base.Add(20*time.Second))
+	agg.Observe(eventAt(kafka.ActionDeleteTask, base), base.Add(70*time.Second))
+
+	snap := agg.Snapshot(base.Add(2 * time.Minute))
+
+	assert.Equal(t, 60, snap.WindowMinutes)
+	require.Len(t, snap.PerMinute, 2)
+	assert.Equal(t, base, snap.PerMinute[0].Minute)
+	assert.Equal(t, map[string]int{kafka.ActionCreateTask: 2}, snap.PerMinute[0].Actions)
+	assert.Equal(t, map[string]int{kafka.ActionDeleteTask: 1}, snap.PerMinute[1].Actions)
+}
+
+func TestAggregator_LatencyPercentiles(t *testing.T) {
+	agg := stats.NewAggregator(time.Hour, nil)
+	consumedAt := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	// Задержки 1..100 мс
+	for ms := 1; ms <= 100; ms++ {
+		agg.Observe(eventAt(kafka.ActionCreateTask, consumedAt.Add(-time.Duration(ms)*time.Millisecond)), consumedAt)
+	}
+	// Событие без DBRequestTime учитывается только в счетчиках
+	agg.Observe(kafka.TaskEvent{Action: kafka.ActionListTasks}, consumedAt)
+
+	snap := agg.Snapshot(consumedAt)
+
+	assert.Equal(t, stats.Percentiles{Count: 100, P50: 50, P95: 95, P99: 99}, snap.Latency)
+	assert.Equal(t, snap.Latency, snap.LatencyByAction[kafka.ActionCreateTask])
+	assert.NotContains(t, snap.LatencyByAction, kafka.ActionListTasks)
+	assert.Equal(t, 1, snap.PerMinute[0].Actions[kafka.ActionListTasks])
+}
+
+func TestAggregator_EvictsOutsideWindow(t *testing.T) {
+	agg := stats.NewAggregator(5*time.Minute, nil)
+	base := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	agg.Observe(eventAt(kafka.ActionCreateTask, base), base)
+	agg.Observe(eventAt(kafka.ActionCreateTask, base), base.Add(4*time.Minute))
+
+	snap := agg.Snapshot(base.Add(5 * time.Minute))
+	require.Len(t, snap.PerMinute, 1)
+	assert.Equal(t, base.Add(4*time.Minute), snap.PerMinute[0].Minute)
+}
+
+func TestAggregator_Metrics(t *testing.T) {
+	metrics := stats.NewMetrics(prometheus.NewRegistry(), time.Hour)
+	agg := stats.NewAggregator(time.Hour, metrics)
+	now := time.Now()
+
+	agg.Observe(eventAt(kafka.ActionCompleteTask, now.Add(-time.Second)), now)
+	agg.Observe(eventAt(kafka.ActionCompleteTask, now.Add(-time.Second)), now)
+
+	assert.Equal(t, float64(2), testutil.ToFloat64(metrics.Consumed(kafka.ActionCompleteTask)))
+}
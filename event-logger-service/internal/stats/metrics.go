@@ -0,0 +1,47 @@
+package stats
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Metrics - метрики прочитанных событий. Методы nil-безопасны.
+type Metrics struct {
+	consumed *prometheus.CounterVec
+	latency  *prometheus.SummaryVec
+}
+
+// NewMetrics регистрирует метрики в reg. Перцентили задержки считаются
+// за скользящее окно window.
+func NewMetrics(reg prometheus.Registerer, window time.Duration) *Metrics {
+	m := &Metrics{
+		consumed: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "task_events_consumed_total",
+			Help: "Task events consumed by event-logger-service by action.",
+		}, []string{"action"}),
+		latency: prometheus.NewSummaryVec(prometheus.SummaryOpts{
+			Name:       "task_event_consume_latency_seconds",
+			Help:       "Time from the db-service request to consuming the event, by action.",
+			Objectives: map[float64]float64{0.5: 0.05, 0.95: 0.01, 0.99: 0.001},
+			MaxAge:     window,
+		}, []string{"action"}),
+	}
+	reg.MustRegister(m.consumed, m.latency)
+	return m
+}
+
+// Consumed возвращает счетчик событий действия (для тестов и отладки)
+func (m *Metrics) Consumed(action string) prometheus.Counter {
+	return m.consumed.WithLabelValues(action)
+}
+
+func (m *Metrics) observe(action string, latency time.Duration, hasLatency bool) {
+	if m == nil {
+		return
+	}
+	m.consumed.WithLabelValues(action).Inc()
+	if hasLatency {
+		m.latency.WithLabelValues(action).Observe(latency.Seconds())
+	}
+}
@@ -0,0 +1,382 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	_ "github.com/jackc/pgx/v5/stdlib"
+	_ "github.com/mattn/go-sqlite3"
+
+	"github.com/N0F1X3d/todo/pkg/kafka"
+)
+
+const postgresSchema = `
+CREATE TABLE IF NOT EXISTS task_events (
+    id BIGSERIAL PRIMARY KEY,
+    event_id TEXT NOT NULL UNIQUE,
+    schema_version INT NOT NULL,
+    action TEXT NOT NULL,
+    task_id BIGINT NOT NULL DEFAULT 0,
+    actor TEXT NOT NULL DEFAULT '',
+    request_id TEXT NOT NULL DEFAULT '',
+    occurred_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    db_request_time TIMESTAMP WITH TIME ZONE,
+    payload JSONB NOT NULL,
+    received_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+CREATE INDEX IF NOT EXISTS task_events_task_id_idx ON task_events (task_id, id);
+CREATE INDEX IF NOT EXISTS task_events_action_idx ON task_events (action, id);
+CREATE INDEX IF NOT EXISTS task_events_occurred_at_idx ON task_events (occurred_at);
+ALTER TABLE task_events ADD COLUMN IF NOT EXISTS db_request_time TIMESTAMP WITH TIME ZONE;
+CREATE INDEX IF NOT EXISTS task_events_received_at_idx ON task_events (received_at);
+CREATE TABLE IF NOT EXISTS task_events_daily (
+    day DATE NOT NULL,
+    action TEXT NOT NULL,
+    events BIGINT NOT NULL,
+    latency_p50_ms DOUBLE PRECISION NOT NULL,
+    latency_p95_ms DOUBLE PRECISION NOT NULL,
+    latency_p99_ms DOUBLE PRECISION NOT NULL,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    PRIMARY KEY (day, action)
+);`
+
+const sqliteSchema = `
+CREATE TABLE IF NOT EXISTS task_events (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    event_id TEXT NOT NULL UNIQUE,
+    schema_version INTEGER NOT NULL,
+    action TEXT NOT NULL,
+    task_id INTEGER NOT NULL DEFAULT 0,
+    actor TEXT NOT NULL DEFAULT '',
+    request_id TEXT NOT NULL DEFAULT '',
+    occurred_at TIMESTAMP NOT NULL,
+    db_request_time TIMESTAMP,
+    payload TEXT NOT NULL,
+    received_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+CREATE INDEX IF NOT EXISTS task_events_task_id_idx ON task_events (task_id, id);
+CREATE INDEX IF NOT EXISTS task_events_action_idx ON task_events (action, id);
+CREATE INDEX IF NOT EXISTS task_events_occurred_at_idx ON task_events (occurred_at);
+CREATE INDEX IF NOT EXISTS task_events_received_at_idx ON task_events (received_at);
+CREATE TABLE IF NOT EXISTS task_events_daily (
+    day TEXT NOT NULL,
+    action TEXT NOT NULL,
+    events INTEGER NOT NULL,
+    latency_p50_ms REAL NOT NULL,
+    latency_p95_ms REAL NOT NULL,
+    latency_p99_ms REAL NOT NULL,
+    updated_at TIMESTAMP NOT NULL,
+    PRIMARY KEY (day, action)
+);`
+
+// sqliteAddColumns - колонки, добавленные после создания task_events.
+// В SQLite нет ADD COLUMN IF NOT EXISTS: ошибка "duplicate column" пропускается.
+var sqliteAddColumns = []string{
+	`ALTER TABLE task_events ADD COLUMN db_request_time TIMESTAMP`,
+}
+
+// sqlStore - Store поверх database/sql. Запросы пишутся с '?', для PostgreSQL
+// они переписываются в $1, $2, ...
+type sqlStore struct {
+	db       *sql.DB
+	postgres bool
+}
+
+// OpenPostgres подключается к PostgreSQL по dsn и создает таблицу task_events
+func OpenPostgres(ctx context.Context, dsn string) (Store, error) {
+	const op = "store.OpenPostgres"
+
+	db, err := sql.Open("pgx", dsn)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	if err := db.PingContext(ctx); err != nil {
+		_ = db.Close()
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	if _, err := db.ExecContext(ctx, postgresSchema); err != nil {
+		_ = db.Close()
+		return nil, fmt.Errorf("%s: create schema: %w", op, err)
+	}
+	return &sqlStore{db: db, postgres: true}, nil
+}
+
+// OpenSQLite открывает базу SQLite по пути path и создает таблицу task_events.
+// Драйвер github.com/mattn/go-sqlite3 требует сборки с CGO_ENABLED=1.
+func OpenSQLite(ctx context.Context, path string) (Store, error) {
+	const op = "store.OpenSQLite"
+
+	db, err := sql.Open("sqlite3", fmt.Sprintf("file:%s?_busy_timeout=5000", path))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	for _, stmt := range sqliteAddColumns {
+		_, err := db.ExecContext(ctx, stmt)
+		if err != nil && !strings.Contains(err.Error(), "duplicate column") && !strings.Contains(err.Error(), "no such table") {
+			_ = db.Close()
+			return nil, fmt.Errorf("%s: migrate schema: %w", op, err)
+		}
+	}
+	if _, err := db.ExecContext(ctx, sqliteSchema); err != nil {
+		_ = db.Close()
+		return nil, fmt.Errorf("%s: create schema: %w", op, err)
+	}
+	return &sqlStore{db: db}, nil
+}
+
+func (s *sqlStore) Save(ctx context.Context, event kafka.TaskEvent) (bool, error) {
+	return s.SaveAt(ctx, event, time.Now())
+}
+
+func (s *sqlStore) SaveAt(ctx context.Context, event kafka.TaskEvent, receivedAt time.Time) (bool, error) {
+	const op = "store.Save"
+
+	if event.EventID == "" {
+		return false, fmt.Errorf("%s: %w", op, ErrNoEventID)
+	}
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return false, fmt.Errorf("%s: %w", op, err)
+	}
+
+	var dbRequestTime *time.Time
+	if !event.DBRequestTime.IsZero() {
+		t := event.DBRequestTime.UTC()
+		dbRequestTime = &t
+	}
+
+	query := `INSERT INTO task_events (event_id, schema_version, action, task_id, actor, request_id,
+			  occurred_at, db_request_time, payload, received_at)
+			  VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+			  ON CONFLICT (event_id) DO NOTHING`
+	res, err := s.db.ExecContext(ctx, s.rebind(query),
+		event.EventID, event.SchemaVersion, event.Action, event.TaskID, event.Actor, event.RequestID,
+		event.OccurredAt.UTC(), dbRequestTime, string(payload), receivedAt.UTC(),
+	)
+	if err != nil {
+		return false, fmt.Errorf("%s: %w", op, err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("%s: %w", op, err)
+	}
+	return n > 0, nil
+}
+
+// List возвращает события по фильтру в порядке сохранения. Токен страницы -
+// id последнего события предыдущей страницы.
+func (s *sqlStore) List(ctx context.Context, filter Filter) (Page, error) {
+	const op = "store.List"
+
+	var (
+		where []string
+		args  []any
+	)
+	if filter.TaskID != 0 {
+		where = append(where, "task_id = ?")
+		args = append(args, filter.TaskID)
+	}
+	if filter.Action != "" {
+		where = append(where, "action = ?")
+		args = append(args, filter.Action)
+	}
+	if !filter.From.IsZero() {
+		where = append(where, "occurred_at >= ?")
+		args = append(args, filter.From.UTC())
+	}
+	if !filter.To.IsZero() {
+		where = append(where, "occurred_at < ?")
+		args = append(args, filter.To.UTC())
+	}
+	if filter.PageToken != "" {
+		after, err := strconv.ParseInt(filter.PageToken, 10, 64)
+		if err != nil || after < 0 {
+			return Page{}, fmt.Errorf("%s: %w", op, ErrInvalidPageToken)
+		}
+		where = append(where, "id > ?")
+		args = append(args, after)
+	}
+
+	query := "SELECT id, payload FROM task_events"
+	if len(where) > 0 {
+		query += " WHERE " + strings.Join(where, " AND ")
+	}
+	// Читаем на одно событие больше, чтобы понять, есть ли следующая страница
+	limit := filter.limit()
+	query += " ORDER BY id LIMIT ?"
+	args = append(args, limit+1)
+
+	rows, err := s.db.QueryContext(ctx, s.rebind(query), args...)
+	if err != nil {
+		return Page{}, fmt.Errorf("%s: %w", op, err)
+	}
+	defer rows.Close()
+
+	page := Page{Events: []kafka.TaskEvent{}}
+	var lastID int64
+	for rows.Next() {
+		if len(page.Events) == limit {
+			page.NextPageToken = strconv.FormatInt(lastID, 10)
+			break
+		}
+		var payload []byte
+		if err := rows.Scan(&lastID, &payload); err != nil {
+			return Page{}, fmt.Errorf("%s: %w", op, err)
+		}
+		var event kafka.TaskEvent
+		if err := json.Unmarshal(payload, &event); err != nil {
+			return Page{}, fmt.Errorf("%s: decode event %d: %w", op, lastID, err)
+		}
+		page.Events = append(page.Events, event)
+	}
+	if err := rows.Err(); err != nil {
+		return Page{}, fmt.Errorf("%s: %w", op, err)
+	}
+	return page, nil
+}
+
+// Итоги дня по действиям считаются в БД: число событий и перцентили задержки
+// (received_at - db_request_time, мс) по ближайшему рангу, как stats.ComputePercentiles.
+// В SQLite нет percentile_disc: ранги считаются оконными функциями, задержка - с точностью до мс.
+const (
+	postgresRollup = `SELECT action, COUNT(*),
+		COALESCE(percentile_disc(0.50) WITHIN GROUP (ORDER BY latency), 0),
+		COALESCE(percentile_disc(0.95) WITHIN GROUP (ORDER BY latency), 0),
+		COALESCE(percentile_disc(0.99) WITHIN GROUP (ORDER BY latency), 0)
+		FROM (
+			SELECT action, CASE WHEN db_request_time IS NOT NULL
+				THEN GREATEST(EXTRACT(EPOCH FROM received_at - db_request_time) * 1000, 0)::DOUBLE PRECISION
+				END AS latency
+			FROM task_events WHERE received_at >= ? AND received_at < ?
+		) day
+		GROUP BY action`
+	sqliteRollup = `WITH day AS (
+			SELECT action, CASE WHEN db_request_time IS NOT NULL
+				THEN MAX(ROUND((unixepoch(received_at, 'subsec') - unixepoch(db_request_time, 'subsec')) * 1000, 3), 0)
+				END AS latency
+			FROM task_events WHERE received_at >= ? AND received_at < ?
+		), ranked AS (
+			SELECT action, latency,
+				ROW_NUMBER() OVER (PARTITION BY action ORDER BY latency) AS rn,
+				COUNT(*) OVER (PARTITION BY action) AS n
+			FROM day WHERE latency IS NOT NULL
+		)
+		SELECT counts.action, counts.events,
+			COALESCE(p.p50, 0), COALESCE(p.p95, 0), COALESCE(p.p99, 0)
+		FROM (SELECT action, COUNT(*) AS events FROM day GROUP BY action) counts
+		LEFT JOIN (
+			SELECT action,
+				MAX(CASE WHEN rn = MAX((50 * n + 99) / 100, 1) THEN latency END) AS p50,
+				MAX(CASE WHEN rn = MAX((95 * n + 99) / 100, 1) THEN latency END) AS p95,
+				MAX(CASE WHEN rn = MAX((99 * n + 99) / 100, 1) THEN latency END) AS p99
+			FROM ranked GROUP BY action
+		) p ON p.action = counts.action`
+)
+
+// Rollup пересчитывает итоги дня в одной транзакции: строки дня удаляются и пишутся
+// заново, поэтому действия, которых за день больше нет, не остаются в task_events_daily
+func (s *sqlStore) Rollup(ctx context.Context, day time.Time) error {
+	const op = "store.Rollup"
+
+	start := day.UTC().Truncate(24 * time.Hour)
+	query := sqliteRollup
+	if s.postgres {
+		query = postgresRollup
+	}
+	rows, err := s.db.QueryContext(ctx, s.rebind(query), start, start.Add(24*time.Hour))
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	defer rows.Close()
+
+	var days []DailyStats
+	for rows.Next() {
+		d := DailyStats{Day: start.Format(DayLayout)}
+		if err := rows.Scan(&d.Action, &d.Events, &d.LatencyP50, &d.LatencyP95, &d.LatencyP99); err != nil {
+			return fmt.Errorf("%s: %w", op, err)
+		}
+		days = append(days, d)
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if _, err := tx.ExecContext(ctx, s.rebind(`DELETE FROM task_events_daily WHERE day = ?`), start.Format(DayLayout)); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	query = `INSERT INTO task_events_daily (day, action, events, latency_p50_ms, latency_p95_ms, latency_p99_ms, updated_at)
+			 VALUES (?, ?, ?, ?, ?, ?, ?)`
+	for _, d := range days {
+		if _, err := tx.ExecContext(ctx, s.rebind(query),
+			d.Day, d.Action, d.Events, d.LatencyP50, d.LatencyP95, d.LatencyP99, time.Now().UTC(),
+		); err != nil {
+			return fmt.Errorf("%s: %w", op, err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	return nil
+}
+
+func (s *sqlStore) Daily(ctx context.Context, from, to time.Time) ([]DailyStats, error) {
+	const op = "store.Daily"
+
+	rows, err := s.db.QueryContext(ctx, s.rebind(
+		`SELECT CAST(day AS TEXT), action, events, latency_p50_ms, latency_p95_ms, latency_p99_ms
+		 FROM task_events_daily
+		 WHERE day >= ? AND day <= ?
+		 ORDER BY day, action`),
+		from.UTC().Format(DayLayout), to.UTC().Format(DayLayout),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer rows.Close()
+
+	days := []DailyStats{}
+	for rows.Next() {
+		var d DailyStats
+		if err := rows.Scan(&d.Day, &d.Action, &d.Events, &d.LatencyP50, &d.LatencyP95, &d.LatencyP99); err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		days = append(days, d)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	return days, nil
+}
+
+func (s *sqlStore) Close() error {
+	return s.db.Close()
+}
+
+// rebind заменяет '?' на $1, $2, ... для PostgreSQL
+func (s *sqlStore) rebind(query string) string {
+	if !s.postgres {
+		return query
+	}
+	var b strings.Builder
+	n := 0
+	for _, r := range query {
+		if r == '?' {
+			n++
+			b.WriteString("$" + strconv.Itoa(n))
+			continue
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
@@ -0,0 +1,90 @@
+// Package store - хранилище прочитанных событий задач (таблица task_events)
+// в PostgreSQL или SQLite с выборкой по задаче, действию и времени.
+package store
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/N0F1X3d/todo/pkg/kafka"
+)
+
+// Драйверы хранилища
+const (
+	DriverPostgres = "postgres"
+	DriverSQLite   = "sqlite"
+)
+
+// Размер страницы List
+const (
+	DefaultLimit = 50
+	MaxLimit     = 500
+)
+
+var (
+	ErrNoEventID        = errors.New("event has no id")
+	ErrInvalidPageToken = errors.New("invalid page token")
+)
+
+// Filter - условия выборки событий. Пустые поля не ограничивают выборку.
+type Filter struct {
+	TaskID int
+	Action string
+	// From, To - полуинтервал [From, To) по времени события
+	From time.Time
+	To   time.Time
+	// Limit - размер страницы, 0 - DefaultLimit, не больше MaxLimit
+	Limit int
+	// PageToken - NextPageToken предыдущей страницы
+	PageToken string
+}
+
+// Page - страница событий в порядке сохранения
+type Page struct {
+	Events []kafka.TaskEvent `json:"events"`
+	// NextPageToken - токен следующей страницы, пусто - страница последняя
+	NextPageToken string `json:"next_page_token,omitempty"`
+}
+
+// DailyStats - итоги дня по действию (таблица task_events_daily). День и задержка -
+// по времени получения события event-logger-service (UTC).
+type DailyStats struct {
+	// Day - дата в формате 2006-01-02
+	Day        string  `json:"day"`
+	Action     string  `json:"action"`
+	Events     int64   `json:"events"`
+	LatencyP50 float64 `json:"latency_p50_ms"`
+	LatencyP95 float64 `json:"latency_p95_ms"`
+	LatencyP99 float64 `json:"latency_p99_ms"`
+}
+
+// DayLayout - формат DailyStats.Day
+const DayLayout = time.DateOnly
+
+// Store хранит события задач
+type Store interface {
+	// Save сохраняет событие, полученное сейчас; повторное событие с тем же EventID
+	// игнорируется (false)
+	Save(ctx context.Context, event kafka.TaskEvent) (bool, error)
+	// SaveAt сохраняет событие как Save, но с временем получения receivedAt
+	// (replay истории: время записи в топик вместо времени прогона)
+	SaveAt(ctx context.Context, event kafka.TaskEvent, receivedAt time.Time) (bool, error)
+	List(ctx context.Context, filter Filter) (Page, error)
+	// Rollup пересчитывает итоги дня day в task_events_daily
+	Rollup(ctx context.Context, day time.Time) error
+	// Daily возвращает итоги дней с from по to включительно
+	Daily(ctx context.Context, from, to time.Time) ([]DailyStats, error)
+	Close() error
+}
+
+func (f Filter) limit() int {
+	switch {
+	case f.Limit <= 0:
+		return DefaultLimit
+	case f.Limit > MaxLimit:
+		return MaxLimit
+	default:
+		return f.Limit
+	}
+}
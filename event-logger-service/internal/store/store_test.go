@@ -0,0 +1,193 @@
+package store_test
+
+import (
+	"context"
+	"database/sql"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/N0F1X3d/todo/event-logger-service/internal/store"
+	"github.com/N0F1X3d/todo/pkg/kafka"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func openTestStore(t *testing.T) store.Store {
+	t.Helper()
+
+	st, err := store.OpenSQLite(context.Background(), filepath.Join(t.TempDir(), "events.db"))
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = st.Close() })
+	return st
+}
+
+func newEvent(action string, taskID int, occurredAt time.Time) kafka.TaskEvent {
+	event := kafka.NewTaskEvent(context.Background(), action, occurredAt)
+	event.TaskID = taskID
+	event.OccurredAt = occurredAt
+	return event
+}
+
+func TestStore_SaveIsIdempotent(t *testing.T) {
+	st := openTestStore(t)
+	ctx := context.Background()
+	event := newEvent(kafka.ActionCreateTask, 1, time.Now())
+
+	saved, err := st.Save(ctx, event)
+	require.NoError(t, err)
+	assert.True(t, saved)
+
+	saved, err = st.Save(ctx, event)
+	require.NoError(t, err)
+	assert.False(t, saved)
+
+	page, err := st.List(ctx, store.Filter{})
+	require.NoError(t, err)
+	require.Len(t, page.Events, 1)
+	assert.Equal(t, event.EventID, page.Events[0].EventID)
+	assert.True(t, event.OccurredAt.Equal(page.Events[0].OccurredAt))
+
+	_, err = st.Save(ctx, kafka.TaskEvent{Action: kafka.ActionCreateTask})
+	assert.ErrorIs(t, err, store.ErrNoEventID)
+}
+
+func TestStore_ListFilters(t *testing.T) {
+	st := openTestStore(t)
+	ctx := context.Background()
+	base := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	events := []kafka.TaskEvent{
+		newEvent(kafka.ActionCreateTask, 1, base),
+		newEvent(kafka.ActionCreateTask, 2, base.Add(time.Minute)),
+		newEvent(kafka.ActionCompleteTask, 1, base.Add(2*time.Minute)),
+		newEvent(kafka.ActionDeleteTask, 1, base.Add(3*time.Minute)),
+	}
+	for _, event := range events {
+		_, err := st.Save(ctx, event)
+		require.NoError(t, err)
+	}
+
+	actions := func(filter store.Filter) []string {
+		page, err := st.List(ctx, filter)
+		require.NoError(t, err)
+		var got []string
+		for _, event := range page.Events {
+			got = append(got, event.Action)
+		}
+		return got
+	}
+
+	assert.Equal(t,
+		[]string{kafka.ActionCreateTask, kafka.ActionCompleteTask, kafka.ActionDeleteTask},
+		actions(store.Filter{TaskID: 1}))
+	assert.Len(t, actions(store.Filter{Action: kafka.ActionCreateTask}), 2)
+	assert.Equal(t,
+		[]string{kafka.ActionCreateTask, kafka.ActionCompleteTask},
+		actions(store.Filter{From: base.Add(time.Minute), To: base.Add(3 * time.Minute)}))
+	assert.Equal(t,
+		[]string{kafka.ActionCompleteTask},
+		actions(store.Filter{TaskID: 1, From: base.Add(time.Second), To: base.Add(3 * time.Minute)}))
+}
+
+func TestStore_ListPaginates(t *testing.T) {
+	st := openTestStore(t)
+	ctx := context.Background()
+	base := time.Now()
+
+	for i := 1; i <= 5; i++ {
+		_, err := st.Save(ctx, newEvent(kafka.ActionCreateTask, i, base.Add(time.Duration(i)*time.Second)))
+		require.NoError(t, err)
+	}
+
+	var taskIDs []int
+	filter := store.Filter{Limit: 2}
+	pages := 0
+	for {
+		page, err := st.List(ctx, filter)
+		require.NoError(t, err)
+		pages++
+		for _, event := range page.Events {
+			taskIDs = append(taskIDs, event.TaskID)
+		}
+		if page.NextPageToken == "" {
+			break
+		}
+		filter.PageToken = page.NextPageToken
+	}
+
+	assert.Equal(t, []int{1, 2, 3, 4, 5}, taskIDs)
+	assert.Equal(t, 3, pages)
+
+	_, err := st.List(ctx, store.Filter{PageToken: "abc"})
+	assert.ErrorIs(t, err, store.ErrInvalidPageToken)
+}
+
+func TestStore_RollupDaily(t *testing.T) {
+	st := openTestStore(t)
+	ctx := context.Background()
+	now := time.Now().UTC()
+
+	for _, action := range []string{kafka.ActionCreateTask, kafka.ActionCreateTask, kafka.ActionDeleteTask} {
+		_, err := st.Save(ctx, newEvent(action, 1, now.Add(-time.Second)))
+		require.NoError(t, err)
+	}
+	// Событие без DBRequestTime считается, но не влияет на задержку
+	legacy := newEvent(kafka.ActionDeleteTask, 1, now)
+	legacy.DBRequestTime = time.Time{}
+	_, err := st.Save(ctx, legacy)
+	require.NoError(t, err)
+
+	require.NoError(t, st.Rollup(ctx, now))
+	// Повторный пересчет заменяет итоги, а не добавляет
+	require.NoError(t, st.Rollup(ctx, now))
+
+	days, err := st.Daily(ctx, now, now)
+	require.NoError(t, err)
+	require.Len(t, days, 2)
+
+	assert.Equal(t, now.Format(store.DayLayout), days[0].Day)
+	assert.Equal(t, kafka.ActionCreateTask, days[0].Action)
+	assert.Equal(t, int64(2), days[0].Events)
+	assert.GreaterOrEqual(t, days[0].LatencyP50, float64(1000))
+	assert.Equal(t, int64(2), days[1].Events)
+
+	days, err = st.Daily(ctx, now.AddDate(0, 0, -3), now.AddDate(0, 0, -1))
+	require.NoError(t, err)
+	assert.Empty(t, days)
+}
+
+func TestStore_RollupComputesPercentilesAndDropsStaleActions(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "events.db")
+	st, err := store.OpenSQLite(context.Background(), path)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = st.Close() })
+	ctx := context.Background()
+	day := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	// Задержки 1..10 с: ближайший ранг дает p50 = 5 с, p95 и p99 = 10 с
+	for i := 1; i <= 10; i++ {
+		receivedAt := day.Add(time.Duration(i) * time.Hour)
+		_, err := st.SaveAt(ctx, newEvent(kafka.ActionCreateTask, i, receivedAt.Add(-time.Duration(i)*time.Second)), receivedAt)
+		require.NoError(t, err)
+	}
+	deleted := newEvent(kafka.ActionDeleteTask, 1, day)
+	_, err = st.SaveAt(ctx, deleted, day.Add(time.Hour))
+	require.NoError(t, err)
+	require.NoError(t, st.Rollup(ctx, day))
+
+	// Событие удалено из task_events: его действие должно пропасть из итогов дня
+	db, err := sql.Open("sqlite3", path)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = db.Close() })
+	_, err = db.Exec(`DELETE FROM task_events WHERE event_id = ?`, deleted.EventID)
+	require.NoError(t, err)
+	require.NoError(t, st.Rollup(ctx, day))
+
+	days, err := st.Daily(ctx, day, day)
+	require.NoError(t, err)
+	assert.Equal(t, []store.DailyStats{{
+		Day: "2025-01-01", Action: kafka.ActionCreateTask, Events: 10,
+		LatencyP50: 5000, LatencyP95: 10000, LatencyP99: 10000,
+	}}, days)
+}
@@ -0,0 +1,327 @@
+package eventbus
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/N0F1X3d/todo/pkg/kafka"
+)
+
+var (
+	ErrQueueFull = errors.New("event queue is full")
+	ErrClosed    = errors.New("event publisher is closed")
+)
+
+type asyncItem struct {
+	key   string
+	event kafka.TaskEvent
+}
+
+// Async публикует события в фоне: Publish только ставит событие в ограниченную
+// очередь. Пока брокер недоступен, события копятся в спуле на диске и после
+// восстановления отправляются по порядку. Close дожидается отправки очереди.
+type Async struct {
+	next      Publisher
+	queue     chan asyncItem
+	spoolPath string
+	spool     *spool
+	metrics   *Metrics
+
+	publishTimeout time.Duration
+	initialBackoff time.Duration
+	maxBackoff     time.Duration
+	drainTimeout   time.Duration
+
+	mu      sync.RWMutex
+	closed  bool
+	closing chan struct{}
+	done    chan struct{}
+	// lost - события, потерянные при остановке
+	lost int
+}
+
+// AsyncOption настраивает Async
+type AsyncOption func(*Async)
+
+// WithQueueSize задает размер очереди (по умолчанию 1024). Когда очередь
+// заполнена, Publish возвращает ErrQueueFull.
+func WithQueueSize(size int) AsyncOption {
+	return func(a *Async) {
+		a.queue = make(chan asyncItem, size)
+	}
+}
+
+// WithSpool задает файл спула. Без спула событие, которое не удалось отправить, теряется.
+func WithSpool(path string) AsyncOption {
+	return func(a *Async) {
+		a.spoolPath = path
+	}
+}
+
+// WithMetrics включает метрики очереди, спула и потерь
+func WithMetrics(m *Metrics) AsyncOption {
+	return func(a *Async) {
+		a.metrics = m
+	}
+}
+
+// WithPublishTimeout ограничивает одну попытку отправки (по умолчанию 5s)
+func WithPublishTimeout(d time.Duration) AsyncOption {
+	return func(a *Async) {
+		a.publishTimeout = d
+	}
+}
+
+// WithRetryBackoff задает паузу между попытками отправить спул, пока брокер
+// недоступен: от initial с удвоением до max (по умолчанию 500ms..30s)
+func WithRetryBackoff(initial, max time.Duration) AsyncOption {
+	return func(a *Async) {
+		a.initialBackoff = initial
+		a.maxBackoff = max
+	}
+}
+
+// WithDrainTimeout ограничивает отправку очереди в Close (по умолчанию 5s).
+// Что не успело отправиться, остается в спуле до следующего запуска.
+func WithDrainTimeout(d time.Duration) AsyncOption {
+	return func(a *Async) {
+		a.drainTimeout = d
+	}
+}
+
+// NewAsync запускает фоновую отправку событий в next. События, оставшиеся
+// в спуле с прошлого запуска, отправляются первыми.
+func NewAsync(next Publisher, opts ...AsyncOption) (*Async, error) {
+	a := &Async{
+		next:           next,
+		queue:          make(chan asyncItem, 1024),
+		publishTimeout: 5 * time.Second,
+		initialBackoff: 500 * time.Millisecond,
+		maxBackoff:     30 * time.Second,
+		drainTimeout:   5 * time.Second,
+		closing:        make(chan struct{}),
+		done:           make(chan struct{}),
+	}
+	for _, opt := range opts {
+		opt(a)
+	}
+
+	a.metrics.watchQueue(func() int { return len(a.queue) })
+
+	if a.spoolPath != "" {
+		s, err := openSpool(a.spoolPath)
+		if err != nil {
+			return nil, err
+		}
+		a.spool = s
+		if s.len() > 0 {
+			log.Printf("event spool %s: %d events left from previous run", a.spoolPath, s.len())
+		}
+		a.metrics.setSpoolDepth(s.len())
+	}
+
+	go a.run()
+	return a, nil
+}
+
+// Publish ставит событие в очередь и не ждет брокера. ctx запроса не используется:
+// событие отправляется после ответа клиенту.
+func (a *Async) Publish(_ context.Context, key string, event kafka.TaskEvent) error {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	if a.closed {
+		return ErrClosed
+	}
+	select {
+	case a.queue <- asyncItem{key: key, event: event}:
+		return nil
+	default:
+		a.metrics.drop(DropQueueFull)
+		return ErrQueueFull
+	}
+}
+
+// Close перестает принимать события, отправляет очередь и закрывает next.
+// Ошибка - если часть событий потеряна.
+func (a *Async) Close() error {
+	a.mu.Lock()
+	if a.closed {
+		a.mu.Unlock()
+		return nil
+	}
+	a.closed = true
+	close(a.closing)
+	a.mu.Unlock()
+
+	<-a.done
+
+	var errs []error
+	if a.lost > 0 {
+		errs = append(errs, fmt.Errorf("%d events lost on shutdown", a.lost))
+	}
+	if a.spool != nil {
+		if n := a.spool.len(); n > 0 {
+			log.Printf("event spool %s: %d events will be sent on next start", a.spoolPath, n)
+		}
+		errs = append(errs, a.spool.close())
+	}
+	errs = append(errs, a.next.Close())
+	return errors.Join(errs...)
+}
+
+func (a *Async) run() {
+	defer close(a.done)
+
+	// Начатая отправка не прерывается при Close: ее ограничивает publishTimeout
+	ctx := context.Background()
+	backoff := a.initialBackoff
+	for {
+		if !a.flushSpool(ctx) {
+			// Брокер недоступен: до следующей попытки события копятся в спуле
+			if !a.spoolFor(backoff) {
+				a.drain()
+				return
+			}
+			backoff = min(backoff*2, a.maxBackoff)
+			continue
+		}
+		backoff = a.initialBackoff
+
+		select {
+		case item := <-a.queue:
+			// Событие ушло в спул: повтор по backoff, как после неудачной отправки спула
+			if !a.deliver(ctx, item) && a.spool != nil {
+				if !a.spoolFor(backoff) {
+					a.drain()
+					return
+				}
+				backoff = min(backoff*2, a.maxBackoff)
+			}
+		case <-a.closing:
+			a.drain()
+			return
+		}
+	}
+}
+
+// spoolFor перекладывает новые события из очереди в спул за накопленными, не
+// обращаясь к брокеру, пока не пройдет d. false - вызван Close.
+func (a *Async) spoolFor(d time.Duration) bool {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	for {
+		select {
+		case item := <-a.queue:
+			a.toSpool(item, DropPublishError)
+		case <-timer.C:
+			return true
+		case <-a.closing:
+			return false
+		}
+	}
+}
+
+// drain отправляет спул и очередь за drainTimeout. Что не отправлено,
+// остается в спуле, без спула - теряется.
+func (a *Async) drain() {
+	ctx, cancel := context.WithTimeout(context.Background(), a.drainTimeout)
+	defer cancel()
+
+	flushed := a.flushSpool(ctx)
+	for {
+		select {
+		case item := <-a.queue:
+			if !flushed || ctx.Err() != nil {
+				a.lostOnShutdown(item)
+				continue
+			}
+			if err := a.publish(ctx, item); err != nil {
+				log.Printf("publish event %s: %v", item.event.EventID, err)
+				flushed = false
+				a.lostOnShutdown(item)
+			}
+		default:
+			return
+		}
+	}
+}
+
+// lostOnShutdown сохраняет неотправленное при остановке событие в спул
+func (a *Async) lostOnShutdown(item asyncItem) {
+	if a.spool == nil {
+		a.metrics.drop(DropShutdown)
+		a.lost++
+		return
+	}
+	if !a.toSpool(item, DropShutdown) {
+		a.lost++
+	}
+}
+
+// deliver отправляет событие; при ошибке кладет его в спул и возвращает false
+func (a *Async) deliver(ctx context.Context, item asyncItem) bool {
+	if err := a.publish(ctx, item); err != nil {
+		log.Printf("publish event %s: %v", item.event.EventID, err)
+		a.toSpool(item, DropPublishError)
+		return false
+	}
+	return true
+}
+
+// flushSpool отправляет события из спула по порядку. false - спул не пуст
+// (брокер недоступен или ctx отменен).
+func (a *Async) flushSpool(ctx context.Context) bool {
+	if a.spool == nil {
+		return true
+	}
+	for {
+		rec, ok, err := a.spool.peek()
+		if err != nil {
+			log.Printf("read event spool: %v", err)
+			return false
+		}
+		if !ok {
+			return true
+		}
+		if err := a.publish(ctx, asyncItem{key: rec.Key, event: rec.Event}); err != nil {
+			return false
+		}
+		if err := a.spool.pop(); err != nil {
+			log.Printf("truncate event spool: %v", err)
+		}
+		a.metrics.setSpoolDepth(a.spool.len())
+	}
+}
+
+func (a *Async) publish(ctx context.Context, item asyncItem) error {
+	ctx, cancel := context.WithTimeout(ctx, a.publishTimeout)
+	defer cancel()
+
+	if err := a.next.Publish(ctx, item.key, item.event); err != nil {
+		return err
+	}
+	a.metrics.publishedOne()
+	return nil
+}
+
+// toSpool дописывает событие в спул; без спула или при ошибке записи событие
+// теряется с причиной reason
+func (a *Async) toSpool(item asyncItem, reason string) bool {
+	if a.spool == nil {
+		a.metrics.drop(reason)
+		return false
+	}
+	if err := a.spool.append(item.key, item.event); err != nil {
+		log.Printf("write event spool: %v", err)
+		a.metrics.drop(DropSpoolError)
+		return false
+	}
+	a.metrics.setSpoolDepth(a.spool.len())
+	return true
+}
@@ -0,0 +1,196 @@
+package eventbus_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/N0F1X3d/todo/pkg/eventbus"
+	"github.com/N0F1X3d/todo/pkg/kafka"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// flakyPublisher - Memory, которая отвечает ошибкой, пока down, и ждет release, если он задан
+type flakyPublisher struct {
+	*eventbus.Memory
+	down    atomic.Bool
+	release chan struct{}
+}
+
+func newFlakyPublisher() *flakyPublisher {
+	return &flakyPublisher{Memory: eventbus.NewMemory()}
+}
+
+func (p *flakyPublisher) Publish(ctx context.Context, key string, event kafka.TaskEvent) error {
+	if p.release != nil {
+		select {
+		case <-p.release:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	if p.down.Load() {
+		return errors.New("broker unavailable")
+	}
+	return p.Memory.Publish(ctx, key, event)
+}
+
+func taskEvent(id int) kafka.TaskEvent {
+	event := kafka.NewTaskEvent(context.Background(), kafka.ActionCompleteTask, time.Now())
+	event.TaskID = id
+	return event
+}
+
+func publishedIDs(p *flakyPublisher) []int {
+	var ids []int
+	for _, published := range p.Published() {
+		ids = append(ids, published.Event.TaskID)
+	}
+	return ids
+}
+
+func TestAsync_DrainsQueueOnClose(t *testing.T) {
+	next := newFlakyPublisher()
+	async, err := eventbus.NewAsync(next)
+	require.NoError(t, err)
+
+	for id := 1; id <= 10; id++ {
+		require.NoError(t, async.Publish(context.Background(), "1", taskEvent(id)))
+	}
+	require.NoError(t, async.Close())
+
+	assert.Equal(t, []int{1, 2, 3, 4, 5, 6, 7, 8, 9, 10}, publishedIDs(next))
+	assert.ErrorIs(t, async.Publish(context.Background(), "1", taskEvent(11)), eventbus.ErrClosed)
+}
+
+func TestAsync_SpoolsWhileBrokerDownAndReplaysInOrder(t *testing.T) {
+	next := newFlakyPublisher()
+	next.down.Store(true)
+	metrics := eventbus.NewMetrics(prometheus.NewRegistry())
+	spoolPath := filepath.Join(t.TempDir(), "events.spool")
+
+	async, err := eventbus.NewAsync(next,
+		eventbus.WithSpool(spoolPath),
+		eventbus.WithMetrics(metrics),
+		eventbus.WithRetryBackoff(5*time.Millisecond, 20*time.Millisecond),
+	)
+	require.NoError(t, err)
+	defer async.Close()
+
+	for id := 1; id <= 5; id++ {
+		require.NoError(t, async.Publish(context.Background(), "1", taskEvent(id)))
+	}
+	require.Eventually(t, func() bool {
+		return testutil.ToFloat64(metrics.SpoolDepth()) == 5
+	}, time.Second, time.Millisecond)
+	assert.Empty(t, next.Published())
+
+	// Событие после восстановления уходит за накопленными в спуле
+	next.down.Store(false)
+	require.NoError(t, async.Publish(context.Background(), "1", taskEvent(6)))
+
+	require.Eventually(t, func() bool {
+		return len(next.Published()) == 6
+	}, time.Second, time.Millisecond)
+	assert.Equal(t, []int{1, 2, 3, 4, 5, 6}, publishedIDs(next))
+	assert.Equal(t, float64(0), testutil.ToFloat64(metrics.SpoolDepth()))
+
+	info, err := os.Stat(spoolPath)
+	require.NoError(t, err)
+	assert.Zero(t, info.Size())
+}
+
+func TestAsync_SpoolSurvivesRestart(t *testing.T) {
+	spoolPath := filepath.Join(t.TempDir(), "events.spool")
+
+	down := newFlakyPublisher()
+	down.down.Store(true)
+	async, err := eventbus.NewAsync(down,
+		eventbus.WithSpool(spoolPath),
+		eventbus.WithDrainTimeout(50*time.Millisecond),
+	)
+	require.NoError(t, err)
+	for id := 1; id <= 3; id++ {
+		require.NoError(t, async.Publish(context.Background(), "1", taskEvent(id)))
+	}
+	// Неотправленные события остаются в спуле, а не теряются
+	require.NoError(t, async.Close())
+
+	next := newFlakyPublisher()
+	async, err = eventbus.NewAsync(next, eventbus.WithSpool(spoolPath))
+	require.NoError(t, err)
+	require.NoError(t, async.Publish(context.Background(), "1", taskEvent(4)))
+	require.NoError(t, async.Close())
+
+	assert.Equal(t, []int{1, 2, 3, 4}, publishedIDs(next))
+}
+
+func TestAsync_DropsWhenQueueFull(t *testing.T) {
+	next := newFlakyPublisher()
+	next.release = make(chan struct{})
+	metrics := eventbus.NewMetrics(prometheus.NewRegistry())
+
+	async, err := eventbus.NewAsync(next, eventbus.WithQueueSize(1), eventbus.WithMetrics(metrics))
+	require.NoError(t, err)
+
+	// Первое событие забирает отправка, второе ждет в очереди, третье не помещается
+	require.NoError(t, async.Publish(context.Background(), "1", taskEvent(1)))
+	require.Eventually(t, func() bool {
+		return testutil.ToFloat64(metrics.QueueDepth()) == 0
+	}, time.Second, time.Millisecond)
+	require.NoError(t, async.Publish(context.Background(), "1", taskEvent(2)))
+	assert.ErrorIs(t, async.Publish(context.Background(), "1", taskEvent(3)), eventbus.ErrQueueFull)
+
+	assert.Equal(t, float64(1), testutil.ToFloat64(metrics.QueueDepth()))
+	assert.Equal(t, float64(1), testutil.ToFloat64(metrics.Dropped(eventbus.DropQueueFull)))
+
+	close(next.release)
+	require.NoError(t, async.Close())
+	assert.Equal(t, []int{1, 2}, publishedIDs(next))
+}
+
+func TestAsync_SpoolsQueueWithoutBrokerWhileDown(t *testing.T) {
+	// Брокер не отвечает: каждая попытка отправки занимает весь publishTimeout
+	next := newFlakyPublisher()
+	next.release = make(chan struct{})
+	metrics := eventbus.NewMetrics(prometheus.NewRegistry())
+	const queueSize = 4
+
+	async, err := eventbus.NewAsync(next,
+		eventbus.WithQueueSize(queueSize),
+		eventbus.WithSpool(filepath.Join(t.TempDir(), "events.spool")),
+		eventbus.WithMetrics(metrics),
+		eventbus.WithPublishTimeout(time.Second),
+		eventbus.WithRetryBackoff(time.Minute, time.Minute),
+		eventbus.WithDrainTimeout(10*time.Millisecond),
+	)
+	require.NoError(t, err)
+	defer async.Close()
+
+	require.NoError(t, async.Publish(context.Background(), "1", taskEvent(0)))
+	require.Eventually(t, func() bool {
+		return testutil.ToFloat64(metrics.SpoolDepth()) == 1
+	}, 3*time.Second, time.Millisecond)
+
+	// До следующей попытки по backoff события уходят в спул, не дожидаясь брокера
+	id := 1
+	for burst := 1; burst <= 3; burst++ {
+		for range queueSize {
+			require.NoError(t, async.Publish(context.Background(), "1", taskEvent(id)))
+			id++
+		}
+		require.Eventually(t, func() bool {
+			return testutil.ToFloat64(metrics.SpoolDepth()) == float64(id)
+		}, 500*time.Millisecond, time.Millisecond)
+	}
+
+	assert.Equal(t, float64(0), testutil.ToFloat64(metrics.Dropped(eventbus.DropQueueFull)))
+	assert.Empty(t, next.Published())
+}
@@ -0,0 +1,112 @@
+// Package eventbus - публикация и чтение событий задач независимо от брокера.
+// Реализация выбирается в конфигурации: kafka, nats, memory (в процессе) или noop.
+package eventbus
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/N0F1X3d/todo/pkg/kafka"
+)
+
+// Драйверы шины событий
+const (
+	DriverKafka  = "kafka"
+	DriverNATS   = "nats"
+	DriverMemory = "memory"
+	DriverNoop   = "noop"
+)
+
+// Publisher публикует события задач. key - ключ партиционирования (id задачи).
+type Publisher interface {
+	Publish(ctx context.Context, key string, event kafka.TaskEvent) error
+	Close() error
+}
+
+// Subscriber передает события handler, пока ctx не отменен
+type Subscriber interface {
+	Subscribe(ctx context.Context, handler func(kafka.TaskEvent) error) error
+	Close() error
+}
+
+// Pinger - шина, доступность которой можно проверить (для readiness)
+type Pinger interface {
+	Ping(ctx context.Context) error
+}
+
+// Config - настройки шины событий
+type Config struct {
+	// Driver - kafka, nats, memory или noop
+	Driver string
+	// Topic - топик Kafka или subject NATS
+	Topic string
+	// Group - consumer group Kafka или queue group NATS подписчика
+	Group string
+	// Codec - формат событий: json или protobuf
+	Codec string
+
+	KafkaBrokers []string
+	// SchemaRegistryPath - файл schema registry для схемы событий Kafka; пусто - не регистрировать
+	SchemaRegistryPath string
+
+	NATSURL string
+}
+
+// Validate проверяет, что для выбранного драйвера заданы нужные параметры
+func (c Config) Validate() error {
+	if _, err := kafka.CodecByName(c.Codec); err != nil {
+		return err
+	}
+	switch c.Driver {
+	case DriverKafka:
+		if len(c.KafkaBrokers) == 0 || c.Topic == "" {
+			return fmt.Errorf("event bus %s: brokers and topic are required", c.Driver)
+		}
+	case DriverNATS:
+		if c.NATSURL == "" || c.Topic == "" {
+			return fmt.Errorf("event bus %s: url and topic are required", c.Driver)
+		}
+	case DriverMemory, DriverNoop:
+	default:
+		return fmt.Errorf("unknown event bus driver %q", c.Driver)
+	}
+	return nil
+}
+
+// NewPublisher создает Publisher выбранного драйвера
+func NewPublisher(ctx context.Context, cfg Config) (Publisher, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	codec, _ := kafka.CodecByName(cfg.Codec)
+
+	switch cfg.Driver {
+	case DriverKafka:
+		return newKafkaPublisher(ctx, cfg, codec)
+	case DriverNATS:
+		return newNATSPublisher(cfg, codec)
+	case DriverMemory:
+		return NewMemory(), nil
+	default:
+		return Noop{}, nil
+	}
+}
+
+// NewSubscriber создает Subscriber выбранного драйвера. consumerOpts применяются
+// только к Kafka (повторы, DLQ, пул обработчиков).
+func NewSubscriber(cfg Config, consumerOpts ...kafka.ConsumerOption) (Subscriber, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+
+	switch cfg.Driver {
+	case DriverKafka:
+		return &kafkaSubscriber{consumer: kafka.NewConsumer(cfg.KafkaBrokers, cfg.Topic, cfg.Group, consumerOpts...)}, nil
+	case DriverNATS:
+		return newNATSSubscriber(cfg)
+	case DriverMemory:
+		return NewMemory(), nil
+	default:
+		return Noop{}, nil
+	}
+}
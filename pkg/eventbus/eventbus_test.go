@@ -0,0 +1,82 @@
+package eventbus_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/N0F1X3d/todo/pkg/eventbus"
+	"github.com/N0F1X3d/todo/pkg/kafka"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemory_DeliversInOrder(t *testing.T) {
+	bus := eventbus.NewMemory()
+	ctx := context.Background()
+	require.NoError(t, bus.Publish(ctx, "1", kafka.NewTaskEvent(ctx, kafka.ActionCreateTask, time.Now())))
+
+	subCtx, cancel := context.WithCancel(ctx)
+	var mu sync.Mutex
+	var actions []string
+	done := make(chan error, 1)
+	go func() {
+		done <- bus.Subscribe(subCtx, func(event kafka.TaskEvent) error {
+			mu.Lock()
+			defer mu.Unlock()
+			actions = append(actions, event.Action)
+			return nil
+		})
+	}()
+
+	// Событие после подписки доставляется следом за уже опубликованным
+	require.NoError(t, bus.Publish(ctx, "1", kafka.NewTaskEvent(ctx, kafka.ActionCompleteTask, time.Now())))
+
+	require.Eventually(t, func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return len(actions) == 2
+	}, time.Second, time.Millisecond)
+	assert.Equal(t, []string{kafka.ActionCreateTask, kafka.ActionCompleteTask}, actions)
+
+	cancel()
+	require.NoError(t, <-done)
+
+	published := bus.Published()
+	require.Len(t, published, 2)
+	assert.Equal(t, "1", published[0].Key)
+}
+
+func TestNewPublisher_SelectsDriver(t *testing.T) {
+	ctx := context.Background()
+
+	publisher, err := eventbus.NewPublisher(ctx, eventbus.Config{Driver: eventbus.DriverMemory})
+	require.NoError(t, err)
+	assert.IsType(t, &eventbus.Memory{}, publisher)
+
+	publisher, err = eventbus.NewPublisher(ctx, eventbus.Config{Driver: eventbus.DriverNoop})
+	require.NoError(t, err)
+	assert.NoError(t, publisher.Publish(ctx, "1", kafka.TaskEvent{}))
+
+	_, err = eventbus.NewPublisher(ctx, eventbus.Config{Driver: "rabbitmq"})
+	assert.Error(t, err)
+}
+
+func TestConfig_Validate(t *testing.T) {
+	assert.Error(t, eventbus.Config{Driver: eventbus.DriverKafka, Topic: "task-events"}.Validate())
+	assert.Error(t, eventbus.Config{Driver: eventbus.DriverNATS, Topic: "task-events"}.Validate())
+	assert.Error(t, eventbus.Config{Driver: eventbus.DriverNoop, Codec: "avro"}.Validate())
+	assert.NoError(t, eventbus.Config{
+		Driver:       eventbus.DriverKafka,
+		Topic:        "task-events",
+		KafkaBrokers: []string{"localhost:9092"},
+		Codec:        "protobuf",
+	}.Validate())
+}
+
+func TestNoop_SubscribeReturnsOnCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	assert.NoError(t, eventbus.Noop{}.Subscribe(ctx, func(kafka.TaskEvent) error { return nil }))
+}
@@ -0,0 +1,52 @@
+package eventbus
+
+import (
+	"context"
+
+	"github.com/N0F1X3d/todo/pkg/kafka"
+	"github.com/N0F1X3d/todo/pkg/schemaregistry"
+)
+
+type kafkaPublisher struct {
+	producer *kafka.Producer
+}
+
+func newKafkaPublisher(ctx context.Context, cfg Config, codec kafka.Codec) (*kafkaPublisher, error) {
+	producer := kafka.NewProducer(cfg.KafkaBrokers, cfg.Topic, kafka.WithCodec(codec))
+	if cfg.SchemaRegistryPath != "" {
+		if err := producer.RegisterSchema(ctx, schemaregistry.NewFileRegistry(cfg.SchemaRegistryPath)); err != nil {
+			_ = producer.Close()
+			return nil, err
+		}
+	}
+	return &kafkaPublisher{producer: producer}, nil
+}
+
+func (p *kafkaPublisher) Publish(ctx context.Context, key string, event kafka.TaskEvent) error {
+	return p.producer.Send(ctx, key, event)
+}
+
+func (p *kafkaPublisher) Ping(ctx context.Context) error {
+	return p.producer.Ping(ctx)
+}
+
+func (p *kafkaPublisher) Close() error {
+	return p.producer.Close()
+}
+
+type kafkaSubscriber struct {
+	consumer *kafka.Consumer
+}
+
+func (s *kafkaSubscriber) Subscribe(ctx context.Context, handler func(kafka.TaskEvent) error) error {
+	return s.consumer.Start(ctx, handler)
+}
+
+// Lag - отставание consumer по партициям
+func (s *kafkaSubscriber) Lag() map[int]int64 {
+	return s.consumer.Lag()
+}
+
+func (s *kafkaSubscriber) Close() error {
+	return s.consumer.Close()
+}
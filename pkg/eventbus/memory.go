@@ -0,0 +1,88 @@
+package eventbus
+
+import (
+	"context"
+	"log"
+	"sync"
+
+	"github.com/N0F1X3d/todo/pkg/kafka"
+)
+
+// Published - событие, опубликованное в Memory
+type Published struct {
+	Key   string
+	Event kafka.TaskEvent
+}
+
+// Memory - шина в памяти процесса для локального запуска и тестов. Подписчик
+// получает все события по порядку, начиная с первого опубликованного.
+type Memory struct {
+	mu        sync.Mutex
+	published []Published
+	// notify закрывается и заменяется при каждой публикации
+	notify chan struct{}
+}
+
+// NewMemory создает пустую шину в памяти
+func NewMemory() *Memory {
+	return &Memory{notify: make(chan struct{})}
+}
+
+func (m *Memory) Publish(ctx context.Context, key string, event kafka.TaskEvent) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.published = append(m.published, Published{Key: key, Event: event})
+	close(m.notify)
+	m.notify = make(chan struct{})
+	return nil
+}
+
+// Published возвращает опубликованные события по порядку
+func (m *Memory) Published() []Published {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]Published(nil), m.published...)
+}
+
+// Subscribe передает события handler, пока ctx не отменен. Ошибка handler
+// пишется в лог, событие не повторяется.
+func (m *Memory) Subscribe(ctx context.Context, handler func(kafka.TaskEvent) error) error {
+	next := 0
+	for {
+		m.mu.Lock()
+		batch := append([]Published(nil), m.published[next:]...)
+		notify := m.notify
+		m.mu.Unlock()
+
+		for _, p := range batch {
+			if err := handler(p.Event); err != nil {
+				log.Printf("handler error: %v", err)
+			}
+		}
+		next += len(batch)
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-notify:
+		}
+	}
+}
+
+func (m *Memory) Close() error { return nil }
+
+// Noop отбрасывает публикуемые события и ничего не доставляет подписчикам
+type Noop struct{}
+
+func (Noop) Publish(context.Context, string, kafka.TaskEvent) error { return nil }
+
+func (Noop) Subscribe(ctx context.Context, _ func(kafka.TaskEvent) error) error {
+	<-ctx.Done()
+	return nil
+}
+
+func (Noop) Close() error { return nil }
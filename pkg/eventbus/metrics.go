@@ -0,0 +1,97 @@
+package eventbus
+
+import (
+	"sync/atomic"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Причины потери события асинхронным publisher
+const (
+	DropQueueFull    = "queue_full"
+	DropPublishError = "publish_error"
+	DropSpoolError   = "spool_error"
+	DropShutdown     = "shutdown"
+)
+
+// Metrics - метрики асинхронного publisher. Методы nil-безопасны.
+type Metrics struct {
+	queueDepth prometheus.GaugeFunc
+	// queueLen - длина очереди Async, читается при сборе метрик
+	queueLen   atomic.Pointer[func() int]
+	spoolDepth prometheus.Gauge
+	published  prometheus.Counter
+	dropped    *prometheus.CounterVec
+}
+
+// NewMetrics регистрирует метрики в reg
+func NewMetrics(reg prometheus.Registerer) *Metrics {
+	m := &Metrics{
+		spoolDepth: prometheus.NewGauge(prometheus.GaugeOpts{
+			Name: "event_publisher_spool_depth",
+			Help: "Events held in the disk spool until the broker is reachable.",
+		}),
+		published: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "event_publisher_published_total",
+			Help: "Events delivered to the broker by the async publisher.",
+		}),
+		dropped: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "event_publisher_dropped_total",
+			Help: "Events lost by the async publisher by reason.",
+		}, []string{"reason"}),
+	}
+	m.queueDepth = prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+		Name: "event_publisher_queue_depth",
+		Help: "Events waiting in the in-memory publish queue.",
+	}, func() float64 {
+		if queueLen := m.queueLen.Load(); queueLen != nil {
+			return float64((*queueLen)())
+		}
+		return 0
+	})
+	reg.MustRegister(m.queueDepth, m.spoolDepth, m.published, m.dropped)
+	return m
+}
+
+// Dropped возвращает счетчик потерянных событий (для тестов и отладки)
+func (m *Metrics) Dropped(reason string) prometheus.Counter {
+	return m.dropped.WithLabelValues(reason)
+}
+
+// QueueDepth возвращает глубину очереди (для тестов и отладки)
+func (m *Metrics) QueueDepth() prometheus.GaugeFunc {
+	return m.queueDepth
+}
+
+// SpoolDepth возвращает число событий в спуле (для тестов и отладки)
+func (m *Metrics) SpoolDepth() prometheus.Gauge {
+	return m.spoolDepth
+}
+
+func (m *Metrics) watchQueue(queueLen func() int) {
+	if m == nil {
+		return
+	}
+	m.queueLen.Store(&queueLen)
+}
+
+func (m *Metrics) setSpoolDepth(n int) {
+	if m == nil {
+		return
+	}
+	m.spoolDepth.Set(float64(n))
+}
+
+func (m *Metrics) publishedOne() {
+	if m == nil {
+		return
+	}
+	m.published.Inc()
+}
+
+func (m *Metrics) drop(reason string) {
+	if m == nil {
+		return
+	}
+	m.dropped.WithLabelValues(reason).Inc()
+}
@@ -0,0 +1,98 @@
+package eventbus
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+
+	"github.com/N0F1X3d/todo/pkg/kafka"
+	"github.com/nats-io/nats.go"
+)
+
+// headerKey - ключ события в заголовке сообщения NATS
+const headerKey = "key"
+
+// natsPublisher публикует события в subject NATS (core NATS: at-most-once,
+// события без подписчиков не сохраняются)
+type natsPublisher struct {
+	conn    *nats.Conn
+	subject string
+	codec   kafka.Codec
+}
+
+func newNATSPublisher(cfg Config, codec kafka.Codec) (*natsPublisher, error) {
+	conn, err := nats.Connect(cfg.NATSURL, nats.MaxReconnects(-1))
+	if err != nil {
+		return nil, fmt.Errorf("connect to nats: %w", err)
+	}
+	return &natsPublisher{conn: conn, subject: cfg.Topic, codec: codec}, nil
+}
+
+func (p *natsPublisher) Publish(_ context.Context, key string, event kafka.TaskEvent) error {
+	data, err := p.codec.Marshal(event)
+	if err != nil {
+		return err
+	}
+	msg := nats.NewMsg(p.subject)
+	msg.Header.Set(kafka.HeaderContentType, p.codec.ContentType())
+	msg.Header.Set(headerKey, key)
+	msg.Data = data
+	return p.conn.PublishMsg(msg)
+}
+
+func (p *natsPublisher) Ping(ctx context.Context) error {
+	if !p.conn.IsConnected() {
+		return errors.New("nats is not connected")
+	}
+	return p.conn.FlushWithContext(ctx)
+}
+
+func (p *natsPublisher) Close() error {
+	return p.conn.Drain()
+}
+
+// natsSubscriber читает subject в queue group: каждое событие получает
+// один экземпляр сервиса группы
+type natsSubscriber struct {
+	conn    *nats.Conn
+	subject string
+	group   string
+}
+
+func newNATSSubscriber(cfg Config) (*natsSubscriber, error) {
+	conn, err := nats.Connect(cfg.NATSURL, nats.MaxReconnects(-1))
+	if err != nil {
+		return nil, fmt.Errorf("connect to nats: %w", err)
+	}
+	return &natsSubscriber{conn: conn, subject: cfg.Topic, group: cfg.Group}, nil
+}
+
+func (s *natsSubscriber) Subscribe(ctx context.Context, handler func(kafka.TaskEvent) error) error {
+	sub, err := s.conn.QueueSubscribe(s.subject, s.group, func(msg *nats.Msg) {
+		codec, err := kafka.CodecForContentType(msg.Header.Get(kafka.HeaderContentType))
+		if err != nil {
+			log.Printf("error decoding event: %v", err)
+			return
+		}
+		event, err := codec.Unmarshal(msg.Data)
+		if err != nil {
+			log.Printf("error decoding event: %v", err)
+			return
+		}
+		if err := handler(event); err != nil {
+			log.Printf("handler error: %v", err)
+		}
+	})
+	if err != nil {
+		return fmt.Errorf("subscribe to %s: %w", s.subject, err)
+	}
+
+	<-ctx.Done()
+	// Drain дожидается обработчиков уже полученных сообщений
+	return sub.Drain()
+}
+
+func (s *natsSubscriber) Close() error {
+	return s.conn.Drain()
+}
@@ -0,0 +1,138 @@
+package eventbus
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"os"
+
+	"github.com/N0F1X3d/todo/pkg/kafka"
+)
+
+// spoolRecord - строка файла спула
+type spoolRecord struct {
+	Key   string          `json:"key"`
+	Event kafka.TaskEvent `json:"event"`
+}
+
+// spool - очередь событий в файле (JSON lines). Записи дописываются в конец
+// и читаются с начала; когда прочитаны все, файл обрезается. Используется
+// из одной горутины.
+type spool struct {
+	file   *os.File
+	reader *bufio.Reader
+	// head - прочитанная, но еще не отправленная первая запись
+	head *spoolRecord
+	// size - записей, которые еще не отправлены
+	size int
+	// end - длина файла: запись идет через WriteAt, не сдвигая позицию чтения
+	end int64
+}
+
+// openSpool открывает файл спула, оставшиеся в нем записи будут отправлены первыми.
+// Недописанная последняя строка (падение во время записи) отбрасывается.
+func openSpool(path string) (*spool, error) {
+	const op = "eventbus.openSpool"
+
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	data, err := io.ReadAll(file)
+	if err != nil {
+		_ = file.Close()
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	complete := bytes.LastIndexByte(data, '\n') + 1
+	if complete < len(data) {
+		if err := file.Truncate(int64(complete)); err != nil {
+			_ = file.Close()
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+	}
+
+	s := &spool{
+		file: file,
+		size: bytes.Count(data[:complete], []byte{'\n'}),
+		end:  int64(complete),
+	}
+	if err := s.rewind(); err != nil {
+		_ = file.Close()
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	return s, nil
+}
+
+func (s *spool) len() int {
+	return s.size
+}
+
+// append дописывает событие в конец спула и сбрасывает его на диск
+func (s *spool) append(key string, event kafka.TaskEvent) error {
+	data, err := json.Marshal(spoolRecord{Key: key, Event: event})
+	if err != nil {
+		return err
+	}
+	data = append(data, '\n')
+	if _, err := s.file.WriteAt(data, s.end); err != nil {
+		return err
+	}
+	if err := s.file.Sync(); err != nil {
+		return err
+	}
+	s.end += int64(len(data))
+	s.size++
+	return nil
+}
+
+// peek возвращает первую неотправленную запись. Нечитаемые строки пропускаются.
+func (s *spool) peek() (spoolRecord, bool, error) {
+	for s.head == nil {
+		if s.size == 0 {
+			return spoolRecord{}, false, nil
+		}
+		line, err := s.reader.ReadBytes('\n')
+		if err != nil {
+			return spoolRecord{}, false, err
+		}
+		var rec spoolRecord
+		if err := json.Unmarshal(line, &rec); err != nil {
+			log.Printf("skip corrupted spool record: %v", err)
+			s.size--
+			continue
+		}
+		s.head = &rec
+	}
+	return *s.head, true, nil
+}
+
+// pop отмечает первую запись отправленной. Когда спул пуст, файл обрезается.
+func (s *spool) pop() error {
+	s.head = nil
+	s.size--
+	if s.size > 0 {
+		return nil
+	}
+	if err := s.file.Truncate(0); err != nil {
+		return err
+	}
+	s.end = 0
+	return s.rewind()
+}
+
+// rewind начинает чтение с начала файла
+func (s *spool) rewind() error {
+	if _, err := s.file.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+	s.reader = bufio.NewReader(s.file)
+	return nil
+}
+
+func (s *spool) close() error {
+	return s.file.Close()
+}
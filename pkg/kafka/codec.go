@@ -0,0 +1,150 @@
+package kafka
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/N0F1X3d/todo/pkg/proto/convert"
+	"github.com/N0F1X3d/todo/pkg/proto/events"
+	"google.golang.org/protobuf/proto"
+)
+
+// Заголовки сообщений, по которым consumer выбирает формат события
+const (
+	HeaderContentType = "content-type"
+	// HeaderSchemaVersion - версия схемы события в schema registry
+	HeaderSchemaVersion = "schema-version"
+)
+
+// Форматы событий в заголовке content-type. Сообщение без заголовка - JSON.
+const (
+	ContentTypeJSON     = "application/json"
+	ContentTypeProtobuf = "application/x-protobuf"
+)
+
+// Codec кодирует TaskEvent в тело сообщения
+type Codec interface {
+	ContentType() string
+	Marshal(event TaskEvent) ([]byte, error)
+	Unmarshal(data []byte) (TaskEvent, error)
+}
+
+var (
+	// JSONCodec - события в JSON, формат по умолчанию
+	JSONCodec Codec = jsonCodec{}
+	// ProtobufCodec - события в protobuf (proto/events.TaskEvent)
+	ProtobufCodec Codec = protobufCodec{}
+)
+
+// CodecByName возвращает codec по имени из конфигурации: json или protobuf
+func CodecByName(name string) (Codec, error) {
+	switch name {
+	case "", "json":
+		return JSONCodec, nil
+	case "protobuf":
+		return ProtobufCodec, nil
+	}
+	return nil, fmt.Errorf("unknown event codec %q", name)
+}
+
+// CodecForContentType возвращает codec по заголовку content-type сообщения
+func CodecForContentType(contentType string) (Codec, error) {
+	switch contentType {
+	case "", ContentTypeJSON:
+		return JSONCodec, nil
+	case ContentTypeProtobuf:
+		return ProtobufCodec, nil
+	}
+	return nil, fmt.Errorf("unsupported content type %q", contentType)
+}
+
+type jsonCodec struct{}
+
+func (jsonCodec) ContentType() string { return ContentTypeJSON }
+
+func (jsonCodec) Marshal(event TaskEvent) ([]byte, error) { return json.Marshal(event) }
+
+func (jsonCodec) Unmarshal(data []byte) (TaskEvent, error) { return DecodeTaskEvent(data) }
+
+type protobufCodec struct{}
+
+func (protobufCodec) ContentType() string { return ContentTypeProtobuf }
+
+func (protobufCodec) Marshal(event TaskEvent) ([]byte, error) {
+	return proto.Marshal(eventToProto(event))
+}
+
+func (protobufCodec) Unmarshal(data []byte) (TaskEvent, error) {
+	var msg events.TaskEvent
+	if err := proto.Unmarshal(data, &msg); err != nil {
+		return TaskEvent{}, err
+	}
+	return eventFromProto(&msg), nil
+}
+
+func eventToProto(event TaskEvent) *events.TaskEvent {
+	return &events.TaskEvent{
+		EventId:        event.EventID,
+		SchemaVersion:  int32(event.SchemaVersion),
+		Action:         event.Action,
+		TaskId:         int64(event.TaskID),
+		Actor:          event.Actor,
+		ForwardedActor: event.ForwardedActor,
+		OccurredAt:     convert.Timestamp(event.OccurredAt),
+		RequestId:      event.RequestID,
+		TraceId:        event.TraceID,
+		Before:         snapshotToProto(event.Before),
+		After:          snapshotToProto(event.After),
+		DbRequestTime:  convert.Timestamp(event.DBRequestTime),
+	}
+}
+
+func eventFromProto(msg *events.TaskEvent) TaskEvent {
+	return TaskEvent{
+		EventID:        msg.GetEventId(),
+		SchemaVersion:  int(msg.GetSchemaVersion()),
+		Action:         msg.GetAction(),
+		TaskID:         int(msg.GetTaskId()),
+		Actor:          msg.GetActor(),
+		ForwardedActor: msg.GetForwardedActor(),
+		OccurredAt:     convert.Time(msg.GetOccurredAt()),
+		RequestID:      msg.GetRequestId(),
+		TraceID:        msg.GetTraceId(),
+		Before:         snapshotFromProto(msg.GetBefore()),
+		After:          snapshotFromProto(msg.GetAfter()),
+		DBRequestTime:  convert.Time(msg.GetDbRequestTime()),
+	}
+}
+
+func snapshotToProto(s *TaskSnapshot) *events.TaskSnapshot {
+	if s == nil {
+		return nil
+	}
+	msg := &events.TaskSnapshot{
+		Id:          int64(s.ID),
+		Title:       s.Title,
+		Description: s.Description,
+		Completed:   s.Completed,
+		CreatedAt:   convert.Timestamp(s.CreatedAt),
+		UpdatedAt:   convert.Timestamp(s.UpdatedAt),
+	}
+	if s.UUID != "" {
+		msg.Uuid = proto.String(s.UUID)
+	}
+	return msg
+}
+
+func snapshotFromProto(msg *events.TaskSnapshot) *TaskSnapshot {
+	if msg == nil {
+		return nil
+	}
+	return &TaskSnapshot{
+		ID:          int(msg.GetId()),
+		UUID:        msg.GetUuid(),
+		Title:       msg.GetTitle(),
+		Description: msg.GetDescription(),
+		Completed:   msg.GetCompleted(),
+		CreatedAt:   convert.Time(msg.GetCreatedAt()),
+		UpdatedAt:   convert.Time(msg.GetUpdatedAt()),
+	}
+}
@@ -0,0 +1,67 @@
+package kafka_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/N0F1X3d/todo/pkg/kafka"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func sampleEvent() kafka.TaskEvent {
+	now := time.Date(2026, 1, 2, 3, 4, 5, 6000, time.UTC)
+	ctx := kafka.ContextWithEventMeta(context.Background(), kafka.EventMeta{Actor: "api-service", ForwardedActor: "alice", RequestID: "req-1"})
+
+	event := kafka.NewTaskEvent(ctx, kafka.ActionCompleteTask, now)
+	event.OccurredAt = now
+	event.TaskID = 7
+	event.Before = &kafka.TaskSnapshot{ID: 7, UUID: "3f0e", Title: "task", CreatedAt: now, UpdatedAt: now}
+	event.After = &kafka.TaskSnapshot{ID: 7, UUID: "3f0e", Title: "task", Completed: true, CreatedAt: now, UpdatedAt: now}
+	return event
+}
+
+func TestCodecs_RoundTrip(t *testing.T) {
+	for _, codec := range []kafka.Codec{kafka.JSONCodec, kafka.ProtobufCodec} {
+		t.Run(codec.ContentType(), func(t *testing.T) {
+			event := sampleEvent()
+
+			data, err := codec.Marshal(event)
+			require.NoError(t, err)
+			decoded, err := codec.Unmarshal(data)
+			require.NoError(t, err)
+
+			assert.Equal(t, event, decoded)
+		})
+	}
+}
+
+func TestProtobufCodec_CreateEventWithoutBefore(t *testing.T) {
+	event := sampleEvent()
+	event.Before = nil
+
+	data, err := kafka.ProtobufCodec.Marshal(event)
+	require.NoError(t, err)
+	decoded, err := kafka.ProtobufCodec.Unmarshal(data)
+	require.NoError(t, err)
+
+	assert.Nil(t, decoded.Before)
+	assert.NotNil(t, decoded.After)
+}
+
+func TestCodecForContentType(t *testing.T) {
+	codec, err := kafka.CodecForContentType("")
+	require.NoError(t, err)
+	assert.Equal(t, kafka.ContentTypeJSON, codec.ContentType(), "сообщения без заголовка - JSON")
+
+	codec, err = kafka.CodecForContentType(kafka.ContentTypeProtobuf)
+	require.NoError(t, err)
+	assert.Equal(t, kafka.ProtobufCodec, codec)
+
+	_, err = kafka.CodecForContentType("application/avro")
+	assert.Error(t, err)
+
+	_, err = kafka.CodecByName("avro")
+	assert.Error(t, err)
+}
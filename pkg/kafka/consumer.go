@@ -2,47 +2,383 @@ package kafka
 
 import (
 	"context"
-	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
 	"log"
+	"strconv"
+	"sync"
+	"time"
 
 	"github.com/segmentio/kafka-go"
 )
 
+// MessageReader - часть kafka.Reader, которой пользуется Consumer
+type MessageReader interface {
+	FetchMessage(ctx context.Context) (kafka.Message, error)
+	CommitMessages(ctx context.Context, msgs ...kafka.Message) error
+	Close() error
+}
+
+// RetryPolicy задает повторы обработчика для одного сообщения
+type RetryPolicy struct {
+	// MaxAttempts - сколько раз вызвать обработчик; 0 - повторять, пока не получится
+	MaxAttempts int
+	// InitialBackoff - пауза после первой неудачи, дальше удваивается до MaxBackoff
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+}
+
+// DefaultRetryPolicy - повторы без ограничения числа попыток: сообщение не теряется,
+// пока обработчик не справится с ним
+var DefaultRetryPolicy = RetryPolicy{
+	InitialBackoff: 100 * time.Millisecond,
+	MaxBackoff:     10 * time.Second,
+}
+
+func (p RetryPolicy) backoff(attempt int) time.Duration {
+	d := p.InitialBackoff
+	for i := 1; i < attempt && d < p.MaxBackoff; i++ {
+		d *= 2
+	}
+	return min(d, p.MaxBackoff)
+}
+
+// Consumer читает события с семантикой at-least-once: offset сообщения коммитится
+// только после успешной обработки, поэтому после падения сообщение будет прочитано снова.
 type Consumer struct {
-	reader *kafka.Reader
+	reader MessageReader
+	retry  RetryPolicy
+	// batchSize и batchInterval - коммит пачкой: после batchSize обработанных
+	// сообщений или через batchInterval после предыдущего коммита
+	batchSize     int
+	batchInterval time.Duration
+	// deadLetters - dead-letter топик для сообщений, которые не удалось обработать
+	deadLetters MessageWriter
+	// fetchBackoff - паузы между повторами чтения, пока брокер недоступен
+	fetchBackoff RetryPolicy
+	// lagInterval - как часто писать в лог отставание; 0 - не писать
+	lagInterval time.Duration
+	// shutdownTimeout - сколько ждать финального коммита после отмены ctx
+	shutdownTimeout time.Duration
+	// workers и maxInFlight - параллельная обработка (см. WithWorkers)
+	workers     int
+	maxInFlight int
+
+	lagMu sync.Mutex
+	lag   map[int]int64
 }
 
-func NewConsumer(brokers []string, topic, groupID string) *Consumer {
-	return &Consumer{
-		reader: kafka.NewReader(kafka.ReaderConfig{
-			Brokers: brokers,
-			Topic:   topic,
-			GroupID: groupID,
-		}),
+// ConsumerOption настраивает Consumer
+type ConsumerOption func(*Consumer)
+
+// WithRetryPolicy задает повторы обработчика (по умолчанию DefaultRetryPolicy)
+func WithRetryPolicy(policy RetryPolicy) ConsumerOption {
+	return func(c *Consumer) {
+		c.retry = policy
 	}
 }
 
-func (c *Consumer) Start(ctx context.Context, handler func(TaskEvent) error) {
-	for {
-		msg, err := c.reader.ReadMessage(ctx)
-		if err != nil {
-			log.Printf("error reading message: %v", err)
-			continue
-		}
+// WithBatchCommit включает коммит пачкой вместо коммита каждого сообщения.
+// После падения повторно обработаются до size сообщений.
+func WithBatchCommit(size int, interval time.Duration) ConsumerOption {
+	return func(c *Consumer) {
+		c.batchSize = size
+		c.batchInterval = interval
+	}
+}
+
+// WithDeadLetterQueue отправляет в writer (обычно NewDeadLetterWriter) сообщения,
+// которые не удалось разобрать или обработать за RetryPolicy.MaxAttempts попыток.
+// Без dead-letter топика такие сообщения пропускаются с записью в лог.
+func WithDeadLetterQueue(writer MessageWriter) ConsumerOption {
+	return func(c *Consumer) {
+		c.deadLetters = writer
+	}
+}
+
+// WithFetchBackoff задает паузы между повторами чтения при ошибках брокера:
+// от initial с удвоением до max (по умолчанию 100ms..10s)
+func WithFetchBackoff(initial, max time.Duration) ConsumerOption {
+	return func(c *Consumer) {
+		c.fetchBackoff = RetryPolicy{InitialBackoff: initial, MaxBackoff: max}
+	}
+}
+
+// WithLagReport включает запись отставания по партициям в лог раз в interval
+func WithLagReport(interval time.Duration) ConsumerOption {
+	return func(c *Consumer) {
+		c.lagInterval = interval
+	}
+}
+
+// WithShutdownTimeout задает, сколько Start ждет коммита обработанных сообщений
+// после отмены ctx (по умолчанию 5s)
+func WithShutdownTimeout(timeout time.Duration) ConsumerOption {
+	return func(c *Consumer) {
+		c.shutdownTimeout = timeout
+	}
+}
+
+func NewConsumer(brokers []string, topic, groupID string, opts ...ConsumerOption) *Consumer {
+	return NewConsumerFromReader(kafka.NewReader(kafka.ReaderConfig{
+		Brokers: brokers,
+		Topic:   topic,
+		GroupID: groupID,
+	}), opts...)
+}
+
+// NewConsumerFromReader создает Consumer поверх готового reader
+func NewConsumerFromReader(reader MessageReader, opts ...ConsumerOption) *Consumer {
+	c := &Consumer{
+		reader:          reader,
+		retry:           DefaultRetryPolicy,
+		batchSize:       1,
+		fetchBackoff:    RetryPolicy{InitialBackoff: 100 * time.Millisecond, MaxBackoff: 10 * time.Second},
+		shutdownTimeout: 5 * time.Second,
+		lag:             map[int]int64{},
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// Start читает сообщения и передает события handler, пока ctx не отменен.
+// Ошибка handler повторяется по RetryPolicy; offset коммитится только после успеха.
+// С WithWorkers handler вызывается параллельно из нескольких горутин.
+// После отмены ctx Start дожидается уже начатых обработчиков, коммитит
+// обработанные сообщения и возвращает nil. Ошибку Start возвращает, только если
+// reader закрыт.
+func (c *Consumer) Start(ctx context.Context, handler func(TaskEvent) error) error {
+	if c.lagInterval > 0 {
+		reportCtx, stopReport := context.WithCancel(ctx)
+		defer stopReport()
+		go c.reportLag(reportCtx)
+	}
+	if c.workers > 1 {
+		return c.startPool(ctx, handler)
+	}
 
-		var event TaskEvent
-		if err := json.Unmarshal(msg.Value, &event); err != nil {
-			log.Printf("error unmarshalling event: %v", err)
+	var pending []kafka.Message
+	lastCommit := time.Now()
+	// Финальный коммит: ctx уже отменен, поэтому у него свой таймаут
+	defer func() {
+		commitCtx, cancel := context.WithTimeout(context.Background(), c.shutdownTimeout)
+		defer cancel()
+		c.commit(commitCtx, pending, lastCommit)
+	}()
+
+	failures := 0
+	for ctx.Err() == nil {
+		fetchCtx, cancel := c.fetchContext(ctx, len(pending), lastCommit)
+		msg, err := c.reader.FetchMessage(fetchCtx)
+		cancel()
+		if err != nil {
+			// Пока не было новых сообщений, подошло время закоммитить пачку
+			if ctx.Err() == nil && errors.Is(err, context.DeadlineExceeded) {
+				pending, lastCommit = c.commit(ctx, pending, lastCommit)
+				continue
+			}
+			failures++
+			if done, err := c.handleFetchError(ctx, err, failures); done {
+				return err
+			}
 			continue
 		}
+		failures = 0
+		c.observeLag(msg)
 
 		log.Printf("message received: key=%s partition=%d offset=%d",
 			string(msg.Key), msg.Partition, msg.Offset)
 
-		if err := handler(event); err != nil {
-			log.Printf("handler error: %v", err)
+		if !c.process(ctx, msg, handler) {
+			return nil
+		}
+
+		pending = append(pending, msg)
+		if len(pending) >= c.batchSize || (c.batchInterval > 0 && time.Since(lastCommit) >= c.batchInterval) {
+			pending, lastCommit = c.commit(ctx, pending, lastCommit)
 		}
 	}
+	return nil
+}
+
+// handleFetchError разбирает ошибку чтения. done - Start должен вернуть err
+// (ctx отменен или reader закрыт); иначе чтение повторяется после паузы.
+func (c *Consumer) handleFetchError(ctx context.Context, err error, failures int) (bool, error) {
+	switch {
+	case ctx.Err() != nil:
+		return true, nil
+	case errors.Is(err, io.EOF):
+		return true, fmt.Errorf("consumer reader closed: %w", err)
+	}
+
+	delay := c.fetchBackoff.backoff(failures)
+	log.Printf("error fetching message (attempt %d, retry in %v): %v", failures, delay, err)
+	sleep(ctx, delay)
+	return false, nil
+}
+
+// Lag возвращает отставание по партициям: сколько сообщений осталось прочитать
+// после последнего полученного
+func (c *Consumer) Lag() map[int]int64 {
+	c.lagMu.Lock()
+	defer c.lagMu.Unlock()
+
+	lag := make(map[int]int64, len(c.lag))
+	for p, n := range c.lag {
+		lag[p] = n
+	}
+	return lag
+}
+
+func (c *Consumer) observeLag(msg kafka.Message) {
+	if msg.HighWaterMark <= 0 {
+		return
+	}
+	c.lagMu.Lock()
+	defer c.lagMu.Unlock()
+	c.lag[msg.Partition] = max(msg.HighWaterMark-msg.Offset-1, 0)
+}
+
+func (c *Consumer) reportLag(ctx context.Context) {
+	ticker := time.NewTicker(c.lagInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			lag := c.Lag()
+			var total int64
+			for _, n := range lag {
+				total += n
+			}
+			log.Printf("consumer lag: total=%d partitions=%v", total, lag)
+		}
+	}
+}
+
+// fetchContext ограничивает ожидание сообщения моментом, когда пора коммитить пачку
+func (c *Consumer) fetchContext(ctx context.Context, pending int, lastCommit time.Time) (context.Context, context.CancelFunc) {
+	if pending == 0 || c.batchInterval <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithDeadline(ctx, lastCommit.Add(c.batchInterval))
+}
+
+// process обрабатывает сообщение с повторами. Возвращает false, если ctx отменен
+// до успешной обработки: сообщение останется незакоммиченным.
+func (c *Consumer) process(ctx context.Context, msg kafka.Message, handler func(TaskEvent) error) bool {
+	event, err := decodeMessage(msg)
+	if err != nil {
+		// Повтор не исправит испорченное сообщение
+		log.Printf("error decoding event: partition=%d offset=%d: %v", msg.Partition, msg.Offset, err)
+		return c.deadLetter(ctx, msg, fmt.Errorf("decode event: %w", err), 1)
+	}
+	if event.SchemaVersion == SchemaVersionLegacy {
+		upgradeLegacy(&event, msg)
+	}
+	event.PublishedAt = msg.Time
+
+	for attempt := 1; ; attempt++ {
+		err := handler(event)
+		if err == nil {
+			return true
+		}
+		log.Printf("handler error: partition=%d offset=%d attempt=%d: %v", msg.Partition, msg.Offset, attempt, err)
+
+		if c.retry.MaxAttempts > 0 && attempt >= c.retry.MaxAttempts {
+			return c.deadLetter(ctx, msg, err, attempt)
+		}
+		if !sleep(ctx, c.retry.backoff(attempt)) {
+			return false
+		}
+	}
+}
+
+// deadLetter отправляет сообщение в dead-letter топик, повторяя запись, пока она
+// не удастся: иначе после коммита сообщение было бы потеряно
+func (c *Consumer) deadLetter(ctx context.Context, msg kafka.Message, cause error, attempts int) bool {
+	if c.deadLetters == nil {
+		log.Printf("skipping message: partition=%d offset=%d: %v", msg.Partition, msg.Offset, cause)
+		return true
+	}
+
+	dl := deadLetterMessage(msg, cause, attempts)
+	for attempt := 1; ; attempt++ {
+		err := c.deadLetters.WriteMessages(ctx, dl)
+		if err == nil {
+			log.Printf("message sent to dead-letter topic: partition=%d offset=%d: %v", msg.Partition, msg.Offset, cause)
+			return true
+		}
+		log.Printf("error writing dead letter: partition=%d offset=%d: %v", msg.Partition, msg.Offset, err)
+		if !sleep(ctx, c.retry.backoff(attempt)) {
+			return false
+		}
+	}
+}
+
+// sleep ждет d; false - ctx отменен раньше
+func sleep(ctx context.Context, d time.Duration) bool {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return false
+	case <-timer.C:
+		return true
+	}
+}
+
+// commit коммитит обработанные сообщения. При ошибке они остаются в пачке
+// и попадут в следующий коммит.
+func (c *Consumer) commit(ctx context.Context, pending []kafka.Message, lastCommit time.Time) ([]kafka.Message, time.Time) {
+	if len(pending) == 0 {
+		return pending, time.Now()
+	}
+	if !c.commitMessages(ctx, pending) {
+		return pending, lastCommit
+	}
+	return pending[:0], time.Now()
+}
+
+func (c *Consumer) commitMessages(ctx context.Context, msgs []kafka.Message) bool {
+	if err := c.reader.CommitMessages(ctx, msgs...); err != nil {
+		log.Printf("error committing offsets: %v", err)
+		return false
+	}
+	return true
+}
+
+// decodeMessage разбирает событие в формате из заголовка content-type
+func decodeMessage(msg kafka.Message) (TaskEvent, error) {
+	codec, err := CodecForContentType(headerValue(msg.Headers, HeaderContentType))
+	if err != nil {
+		return TaskEvent{}, err
+	}
+	return codec.Unmarshal(msg.Value)
+}
+
+func headerValue(headers []kafka.Header, key string) string {
+	for _, h := range headers {
+		if h.Key == key {
+			return string(h.Value)
+		}
+	}
+	return ""
+}
+
+// upgradeLegacy дополняет событие старого формата метаданными сообщения:
+// EventID строится из позиции в топике, а TaskID берется из ключа, если это id задачи
+// (так публикует outbox db-service; api-service писал в ключ название действия)
+func upgradeLegacy(event *TaskEvent, msg kafka.Message) {
+	event.EventID = fmt.Sprintf("%s-%d-%d", msg.Topic, msg.Partition, msg.Offset)
+	if id, err := strconv.Atoi(string(msg.Key)); err == nil {
+		event.TaskID = id
+	}
 }
 
 func (c *Consumer) Close() error {
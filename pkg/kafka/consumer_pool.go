@@ -0,0 +1,215 @@
+package kafka
+
+import (
+	"context"
+	"hash/fnv"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+)
+
+// WithWorkers включает параллельную обработку: сообщения распределяются по workers
+// обработчикам по хешу ключа (id задачи), поэтому события одной задачи обрабатываются
+// по порядку. Прочитано, но не обработано не больше maxInFlight сообщений; offset
+// партиции коммитится, только когда обработаны все сообщения до него.
+func WithWorkers(workers, maxInFlight int) ConsumerOption {
+	return func(c *Consumer) {
+		c.workers = workers
+		c.maxInFlight = max(maxInFlight, workers)
+	}
+}
+
+// startPool - Start с пулом обработчиков
+func (c *Consumer) startPool(ctx context.Context, handler func(TaskEvent) error) error {
+	tracker := newOffsetTracker()
+	// inFlight ограничивает число прочитанных, но еще не обработанных сообщений
+	inFlight := make(chan struct{}, c.maxInFlight)
+	done := make(chan kafka.Message, c.maxInFlight)
+
+	queues := make([]chan kafka.Message, c.workers)
+	var workers sync.WaitGroup
+	for i := range queues {
+		queues[i] = make(chan kafka.Message, c.maxInFlight)
+		workers.Add(1)
+		go func(queue <-chan kafka.Message) {
+			defer workers.Done()
+			for msg := range queue {
+				// После отмены ctx новые сообщения не начинаем: они не попадут в done,
+				// и offset'ы партиции после них не закоммитятся
+				if ctx.Err() != nil {
+					continue
+				}
+				if c.process(ctx, msg, handler) {
+					done <- msg
+				}
+			}
+		}(queues[i])
+	}
+
+	committed := make(chan struct{})
+	go func() {
+		defer close(committed)
+		c.commitLoop(ctx, tracker, done, inFlight)
+	}()
+
+	err := c.fetchLoop(ctx, tracker, queues, inFlight)
+
+	// Ждем обработчики уже прочитанных сообщений, затем финальный коммит
+	for _, queue := range queues {
+		close(queue)
+	}
+	workers.Wait()
+	close(done)
+	<-committed
+	return err
+}
+
+// fetchLoop читает сообщения и раздает их обработчикам, пока ctx не отменен
+func (c *Consumer) fetchLoop(ctx context.Context, tracker *offsetTracker, queues []chan kafka.Message, inFlight chan struct{}) error {
+	failures := 0
+	for {
+		select {
+		case inFlight <- struct{}{}:
+		case <-ctx.Done():
+			return nil
+		}
+
+		msg, err := c.reader.FetchMessage(ctx)
+		if err != nil {
+			<-inFlight
+			failures++
+			if done, err := c.handleFetchError(ctx, err, failures); done {
+				return err
+			}
+			continue
+		}
+		failures = 0
+		c.observeLag(msg)
+
+		tracker.add(msg)
+		queues[workerIndex(msg, len(queues))] <- msg
+	}
+}
+
+// commitLoop отмечает обработанные сообщения и коммитит продвинувшиеся партиции:
+// каждое сообщение или пачкой по batchSize/batchInterval. Когда done закрыт,
+// коммитит остаток с таймаутом shutdownTimeout.
+func (c *Consumer) commitLoop(ctx context.Context, tracker *offsetTracker, done <-chan kafka.Message, inFlight <-chan struct{}) {
+	// ready - последнее сообщение каждой партиции, до которого все обработано
+	ready := map[int]kafka.Message{}
+	advanced := 0
+	lastCommit := time.Now()
+
+	var tick <-chan time.Time
+	if c.batchInterval > 0 {
+		ticker := time.NewTicker(c.batchInterval)
+		defer ticker.Stop()
+		tick = ticker.C
+	}
+
+	commit := func(ctx context.Context) {
+		if len(ready) == 0 {
+			return
+		}
+		msgs := make([]kafka.Message, 0, len(ready))
+		for _, msg := range ready {
+			msgs = append(msgs, msg)
+		}
+		if c.commitMessages(ctx, msgs) {
+			clear(ready)
+			advanced = 0
+			lastCommit = time.Now()
+		}
+	}
+
+	for {
+		select {
+		case msg, ok := <-done:
+			if !ok {
+				commitCtx, cancel := context.WithTimeout(context.Background(), c.shutdownTimeout)
+				commit(commitCtx)
+				cancel()
+				return
+			}
+			<-inFlight
+			if last, n := tracker.markDone(msg); n > 0 {
+				ready[last.Partition] = last
+				advanced += n
+			}
+			if advanced >= c.batchSize {
+				commit(ctx)
+			}
+		case <-tick:
+			if time.Since(lastCommit) >= c.batchInterval {
+				commit(ctx)
+			}
+		}
+	}
+}
+
+// workerIndex выбирает обработчик по ключу; сообщения без ключа - по партиции,
+// чтобы сохранить их порядок внутри партиции
+func workerIndex(msg kafka.Message, workers int) int {
+	key := msg.Key
+	if len(key) == 0 {
+		key = []byte(strconv.Itoa(msg.Partition))
+	}
+	h := fnv.New32a()
+	_, _ = h.Write(key)
+	return int(h.Sum32() % uint32(workers))
+}
+
+// offsetTracker помнит прочитанные сообщения партиций в порядке чтения, чтобы
+// коммитить offset только после обработки всех предыдущих
+type offsetTracker struct {
+	mu         sync.Mutex
+	partitions map[int]*partitionProgress
+}
+
+type partitionProgress struct {
+	// pending - offset'ы прочитанных и еще не закоммиченных сообщений по порядку
+	pending []int64
+	done    map[int64]kafka.Message
+}
+
+func newOffsetTracker() *offsetTracker {
+	return &offsetTracker{partitions: map[int]*partitionProgress{}}
+}
+
+func (t *offsetTracker) add(msg kafka.Message) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	p, ok := t.partitions[msg.Partition]
+	if !ok {
+		p = &partitionProgress{done: map[int64]kafka.Message{}}
+		t.partitions[msg.Partition] = p
+	}
+	p.pending = append(p.pending, msg.Offset)
+}
+
+// markDone отмечает сообщение обработанным и возвращает последнее сообщение
+// непрерывно обработанного начала партиции и сколько сообщений к нему добавилось
+func (t *offsetTracker) markDone(msg kafka.Message) (kafka.Message, int) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	p := t.partitions[msg.Partition]
+	p.done[msg.Offset] = msg
+
+	var last kafka.Message
+	n := 0
+	for len(p.pending) > 0 {
+		head, ok := p.done[p.pending[0]]
+		if !ok {
+			break
+		}
+		delete(p.done, p.pending[0])
+		p.pending = p.pending[1:]
+		last = head
+		n++
+	}
+	return last, n
+}
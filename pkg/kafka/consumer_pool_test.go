@@ -0,0 +1,162 @@
+package kafka_test
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/N0F1X3d/todo/pkg/kafka"
+	"github.com/N0F1X3d/todo/pkg/kafka/kafkatest"
+	kafkago "github.com/segmentio/kafka-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// produceSequence пишет count событий задачи taskID; RequestID - номер события
+func produceSequence(t *testing.T, broker *kafkatest.Broker, taskID, count int) {
+	t.Helper()
+
+	for i := range count {
+		event := kafka.NewTaskEvent(context.Background(), kafka.ActionCompleteTask, time.Now())
+		event.TaskID = taskID
+		event.RequestID = strconv.Itoa(i)
+		value, err := kafka.JSONCodec.Marshal(event)
+		require.NoError(t, err)
+		broker.Produce(testTopic, kafkago.Message{Key: []byte(strconv.Itoa(taskID)), Value: value})
+	}
+}
+
+func TestConsumerPool_KeepsOrderPerKeyInParallel(t *testing.T) {
+	broker := kafkatest.NewBroker(4)
+	const tasks, perTask = 8, 10
+	for id := 1; id <= tasks; id++ {
+		produceSequence(t, broker, id, perTask)
+	}
+
+	var mu sync.Mutex
+	seen := map[int][]int{}
+	var running, maxRunning atomic.Int32
+
+	consumer := kafka.NewConsumerFromReader(broker.Reader(testTopic, testGroup), kafka.WithWorkers(4, 16))
+	startConsumer(t, consumer, func(event kafka.TaskEvent) error {
+		n := running.Add(1)
+		defer running.Add(-1)
+		for {
+			current := maxRunning.Load()
+			if n <= current || maxRunning.CompareAndSwap(current, n) {
+				break
+			}
+		}
+		time.Sleep(time.Millisecond)
+
+		seq, _ := strconv.Atoi(event.RequestID)
+		mu.Lock()
+		seen[event.TaskID] = append(seen[event.TaskID], seq)
+		mu.Unlock()
+		return nil
+	})
+
+	require.Eventually(t, func() bool {
+		var committed int64
+		for _, offset := range broker.Committed(testGroup, testTopic) {
+			committed += offset
+		}
+		return committed == tasks*perTask
+	}, 2*time.Second, time.Millisecond)
+
+	mu.Lock()
+	defer mu.Unlock()
+	for id := 1; id <= tasks; id++ {
+		require.Len(t, seen[id], perTask)
+		for i, seq := range seen[id] {
+			assert.Equal(t, i, seq, "events of task %d out of order", id)
+		}
+	}
+	assert.Greater(t, maxRunning.Load(), int32(1), "обработчики должны работать параллельно")
+}
+
+func TestConsumerPool_CommitsInOrderWithinPartition(t *testing.T) {
+	broker := kafkatest.NewBroker(1)
+	// Разные ключи в одной партиции попадают к разным обработчикам
+	produceSequence(t, broker, 1, 1)
+	produceSequence(t, broker, 2, 1)
+
+	release := make(chan struct{})
+	handled := make(chan int, 2)
+	consumer := kafka.NewConsumerFromReader(broker.Reader(testTopic, testGroup), kafka.WithWorkers(8, 8))
+	startConsumer(t, consumer, func(event kafka.TaskEvent) error {
+		if event.TaskID == 1 {
+			<-release
+		}
+		handled <- event.TaskID
+		return nil
+	})
+
+	// Второе сообщение обработано, но первое еще нет: коммитить нечего
+	require.Equal(t, 2, <-handled)
+	time.Sleep(20 * time.Millisecond)
+	assert.Empty(t, broker.Committed(testGroup, testTopic))
+
+	close(release)
+	require.Equal(t, 1, <-handled)
+	require.Eventually(t, func() bool {
+		return broker.Committed(testGroup, testTopic)[0] == 2
+	}, time.Second, time.Millisecond)
+}
+
+func TestConsumerPool_BoundsInFlight(t *testing.T) {
+	broker := kafkatest.NewBroker(4)
+	for id := 1; id <= 10; id++ {
+		produceSequence(t, broker, id, 1)
+	}
+
+	release := make(chan struct{})
+	var started atomic.Int32
+	consumer := kafka.NewConsumerFromReader(broker.Reader(testTopic, testGroup), kafka.WithWorkers(4, 4))
+	stop := startConsumer(t, consumer, func(kafka.TaskEvent) error {
+		started.Add(1)
+		<-release
+		return nil
+	})
+
+	time.Sleep(50 * time.Millisecond)
+	assert.LessOrEqual(t, started.Load(), int32(4))
+	lag := consumer.Lag()
+	var remaining int64
+	for _, n := range lag {
+		remaining += n
+	}
+	assert.GreaterOrEqual(t, remaining, int64(6), "не больше 4 сообщений прочитано")
+
+	close(release)
+	require.Eventually(t, func() bool { return started.Load() == 10 }, time.Second, time.Millisecond)
+	require.NoError(t, stop())
+}
+
+func TestConsumerPool_ShutdownCommitsFinishedHandlers(t *testing.T) {
+	broker := kafkatest.NewBroker(1)
+	produceSequence(t, broker, 1, 3)
+
+	release := make(chan struct{})
+	started := make(chan struct{}, 3)
+	consumer := kafka.NewConsumerFromReader(broker.Reader(testTopic, testGroup),
+		kafka.WithWorkers(2, 8), kafka.WithBatchCommit(100, time.Hour))
+	stop := startConsumer(t, consumer, func(kafka.TaskEvent) error {
+		started <- struct{}{}
+		<-release
+		return nil
+	})
+
+	<-started
+	stopped := make(chan error, 1)
+	go func() { stopped <- stop() }()
+
+	// Начатый обработчик дописывается, остальные сообщения той же задачи не начинаются
+	time.Sleep(20 * time.Millisecond)
+	close(release)
+	require.NoError(t, <-stopped)
+	assert.Equal(t, map[int]int64{0: 1}, broker.Committed(testGroup, testTopic))
+}
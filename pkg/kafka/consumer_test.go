@@ -0,0 +1,290 @@
+package kafka_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/N0F1X3d/todo/pkg/kafka"
+	"github.com/N0F1X3d/todo/pkg/kafka/kafkatest"
+	kafkago "github.com/segmentio/kafka-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testTopic = "task-events"
+	testGroup = "event-logger-group"
+)
+
+// produceEvents пишет в брокер события с заданными действиями под ключом key
+func produceEvents(t *testing.T, broker *kafkatest.Broker, key string, actions ...string) {
+	t.Helper()
+
+	for _, action := range actions {
+		value, err := kafka.JSONCodec.Marshal(kafka.NewTaskEvent(context.Background(), action, time.Now()))
+		require.NoError(t, err)
+		broker.Produce(testTopic, kafkago.Message{
+			Key:     []byte(key),
+			Value:   value,
+			Headers: []kafkago.Header{{Key: kafka.HeaderContentType, Value: []byte(kafka.ContentTypeJSON)}},
+		})
+	}
+}
+
+// startConsumer запускает Start до конца теста и возвращает функцию остановки,
+// отдающую результат Start
+func startConsumer(t *testing.T, consumer *kafka.Consumer, handler func(kafka.TaskEvent) error) func() error {
+	t.Helper()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		done <- consumer.Start(ctx, handler)
+	}()
+
+	var once sync.Once
+	var result error
+	stop := func() error {
+		once.Do(func() {
+			cancel()
+			select {
+			case result = <-done:
+			case <-time.After(2 * time.Second):
+				t.Fatal("Start did not return after cancel")
+			}
+		})
+		return result
+	}
+	t.Cleanup(func() { _ = stop() })
+	return stop
+}
+
+var fastRetry = kafka.RetryPolicy{InitialBackoff: time.Millisecond, MaxBackoff: 5 * time.Millisecond}
+
+func TestConsumer_CommitsAfterHandlerSucceeds(t *testing.T) {
+	broker := kafkatest.NewBroker(1)
+	produceEvents(t, broker, "1", kafka.ActionCreateTask, kafka.ActionCompleteTask)
+
+	var mu sync.Mutex
+	calls := 0
+	consumer := kafka.NewConsumerFromReader(broker.Reader(testTopic, testGroup), kafka.WithRetryPolicy(fastRetry))
+	startConsumer(t, consumer, func(kafka.TaskEvent) error {
+		mu.Lock()
+		defer mu.Unlock()
+		calls++
+		// Первые два вызова падают: offset 0 нельзя коммитить, пока обработчик не справится
+		if calls <= 2 {
+			return errors.New("storage unavailable")
+		}
+		return nil
+	})
+
+	require.Eventually(t, func() bool {
+		return broker.Committed(testGroup, testTopic)[0] == 2
+	}, time.Second, time.Millisecond)
+
+	mu.Lock()
+	defer mu.Unlock()
+	assert.Equal(t, 4, calls)
+}
+
+func TestConsumer_SetsPublishedAtFromMessageTime(t *testing.T) {
+	broker := kafkatest.NewBroker(1)
+	publishedAt := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	value, err := kafka.JSONCodec.Marshal(kafka.NewTaskEvent(context.Background(), kafka.ActionCreateTask, time.Now()))
+	require.NoError(t, err)
+	broker.Produce(testTopic, kafkago.Message{
+		Key:     []byte("1"),
+		Value:   value,
+		Time:    publishedAt,
+		Headers: []kafkago.Header{{Key: kafka.HeaderContentType, Value: []byte(kafka.ContentTypeJSON)}},
+	})
+
+	events := make(chan kafka.TaskEvent, 1)
+	consumer := kafka.NewConsumerFromReader(broker.Reader(testTopic, testGroup))
+	startConsumer(t, consumer, func(event kafka.TaskEvent) error {
+		events <- event
+		return nil
+	})
+
+	select {
+	case event := <-events:
+		assert.True(t, publishedAt.Equal(event.PublishedAt))
+	case <-time.After(2 * time.Second):
+		t.Fatal("event was not handled")
+	}
+}
+
+func TestConsumer_StopsRetryingOnCancelWithoutCommit(t *testing.T) {
+	broker := kafkatest.NewBroker(1)
+	produceEvents(t, broker, "1", kafka.ActionCreateTask)
+
+	attempts := make(chan struct{}, 100)
+	consumer := kafka.NewConsumerFromReader(broker.Reader(testTopic, testGroup), kafka.WithRetryPolicy(fastRetry))
+	stop := startConsumer(t, consumer, func(kafka.TaskEvent) error {
+		attempts <- struct{}{}
+		return errors.New("storage unavailable")
+	})
+
+	<-attempts
+	<-attempts
+	require.NoError(t, stop())
+	assert.Empty(t, broker.Committed(testGroup, testTopic))
+}
+
+func TestConsumer_GivesUpAfterMaxAttempts(t *testing.T) {
+	broker := kafkatest.NewBroker(1)
+	produceEvents(t, broker, "1", kafka.ActionCreateTask, kafka.ActionDeleteTask)
+	policy := fastRetry
+	policy.MaxAttempts = 3
+
+	var mu sync.Mutex
+	attempts := map[string]int{}
+	consumer := kafka.NewConsumerFromReader(broker.Reader(testTopic, testGroup), kafka.WithRetryPolicy(policy))
+	startConsumer(t, consumer, func(event kafka.TaskEvent) error {
+		mu.Lock()
+		defer mu.Unlock()
+		attempts[event.Action]++
+		if event.Action == kafka.ActionCreateTask {
+			return errors.New("poison")
+		}
+		return nil
+	})
+
+	require.Eventually(t, func() bool {
+		return broker.Committed(testGroup, testTopic)[0] == 2
+	}, time.Second, time.Millisecond)
+
+	mu.Lock()
+	defer mu.Unlock()
+	assert.Equal(t, 3, attempts[kafka.ActionCreateTask])
+	assert.Equal(t, 1, attempts[kafka.ActionDeleteTask])
+}
+
+func TestConsumer_BatchCommit(t *testing.T) {
+	broker := kafkatest.NewBroker(1)
+	produceEvents(t, broker, "1",
+		kafka.ActionCreateTask, kafka.ActionCreateTask, kafka.ActionCreateTask,
+		kafka.ActionCompleteTask, kafka.ActionDeleteTask)
+
+	consumer := kafka.NewConsumerFromReader(broker.Reader(testTopic, testGroup), kafka.WithBatchCommit(2, 20*time.Millisecond))
+	startConsumer(t, consumer, func(kafka.TaskEvent) error { return nil })
+
+	// Две полные пачки коммитятся сразу, остаток - по интервалу, когда сообщения кончились
+	require.Eventually(t, func() bool {
+		return broker.Committed(testGroup, testTopic)[0] == 5
+	}, time.Second, time.Millisecond)
+}
+
+func TestConsumer_CommitsPendingBatchOnShutdown(t *testing.T) {
+	broker := kafkatest.NewBroker(1)
+	produceEvents(t, broker, "1", kafka.ActionCreateTask, kafka.ActionCreateTask, kafka.ActionCreateTask)
+
+	handled := make(chan struct{}, 3)
+	consumer := kafka.NewConsumerFromReader(broker.Reader(testTopic, testGroup), kafka.WithBatchCommit(100, time.Hour))
+	stop := startConsumer(t, consumer, func(kafka.TaskEvent) error {
+		handled <- struct{}{}
+		return nil
+	})
+
+	for range 3 {
+		<-handled
+	}
+	assert.Empty(t, broker.Committed(testGroup, testTopic), "пачка еще не набрана")
+
+	require.NoError(t, stop())
+	assert.Equal(t, map[int]int64{0: 3}, broker.Committed(testGroup, testTopic))
+}
+
+func TestConsumer_WaitsForInFlightHandler(t *testing.T) {
+	broker := kafkatest.NewBroker(1)
+	produceEvents(t, broker, "1", kafka.ActionCreateTask)
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+	consumer := kafka.NewConsumerFromReader(broker.Reader(testTopic, testGroup))
+	stop := startConsumer(t, consumer, func(kafka.TaskEvent) error {
+		close(started)
+		<-release
+		return nil
+	})
+
+	<-started
+	stopped := make(chan error, 1)
+	go func() { stopped <- stop() }()
+
+	select {
+	case <-stopped:
+		t.Fatal("Start returned before the handler finished")
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	close(release)
+	require.NoError(t, <-stopped)
+	assert.Equal(t, map[int]int64{0: 1}, broker.Committed(testGroup, testTopic))
+}
+
+func TestConsumer_BacksOffOnBrokerErrors(t *testing.T) {
+	broker := kafkatest.NewBroker(1)
+	errBroker := errors.New("broker unavailable")
+	broker.FailFetches(errBroker, errBroker, errBroker)
+	produceEvents(t, broker, "1", kafka.ActionCreateTask)
+
+	consumer := kafka.NewConsumerFromReader(broker.Reader(testTopic, testGroup),
+		kafka.WithFetchBackoff(10*time.Millisecond, 40*time.Millisecond))
+
+	start := time.Now()
+	startConsumer(t, consumer, func(kafka.TaskEvent) error { return nil })
+
+	require.Eventually(t, func() bool {
+		return broker.Committed(testGroup, testTopic)[0] == 1
+	}, time.Second, time.Millisecond)
+	// 10ms + 20ms + 40ms между тремя ошибками
+	assert.GreaterOrEqual(t, time.Since(start), 70*time.Millisecond)
+}
+
+func TestConsumer_ReturnsWhenReaderClosed(t *testing.T) {
+	broker := kafkatest.NewBroker(1)
+	reader := broker.Reader(testTopic, testGroup)
+	consumer := kafka.NewConsumerFromReader(reader)
+
+	done := make(chan error, 1)
+	go func() {
+		done <- consumer.Start(context.Background(), func(kafka.TaskEvent) error { return nil })
+	}()
+	require.NoError(t, reader.Close())
+
+	select {
+	case err := <-done:
+		assert.Error(t, err)
+	case <-time.After(time.Second):
+		t.Fatal("Start did not return after the reader was closed")
+	}
+}
+
+func TestConsumer_ReportsLag(t *testing.T) {
+	broker := kafkatest.NewBroker(2)
+	produceEvents(t, broker, "1", kafka.ActionCreateTask, kafka.ActionCompleteTask, kafka.ActionDeleteTask)
+
+	release := make(chan struct{})
+	consumer := kafka.NewConsumerFromReader(broker.Reader(testTopic, testGroup))
+	startConsumer(t, consumer, func(kafka.TaskEvent) error {
+		<-release
+		return nil
+	})
+
+	// Первое сообщение у обработчика, еще два ждут в партиции
+	msgs := broker.Messages(testTopic)
+	partition := msgs[0].Partition
+	require.Eventually(t, func() bool {
+		return consumer.Lag()[partition] == 2
+	}, time.Second, time.Millisecond)
+
+	close(release)
+	require.Eventually(t, func() bool {
+		return consumer.Lag()[partition] == 0
+	}, time.Second, time.Millisecond)
+}
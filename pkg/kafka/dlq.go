@@ -0,0 +1,105 @@
+package kafka
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+)
+
+// Заголовки, которые Consumer добавляет к сообщению в dead-letter топике
+const (
+	HeaderDLQError     = "dlq-error"
+	HeaderDLQAttempts  = "dlq-attempts"
+	HeaderDLQTopic     = "dlq-topic"
+	HeaderDLQPartition = "dlq-partition"
+	HeaderDLQOffset    = "dlq-offset"
+	HeaderDLQFailedAt  = "dlq-failed-at"
+)
+
+const dlqHeaderPrefix = "dlq-"
+
+// MessageWriter - часть kafka.Writer для записи в dead-letter топик
+type MessageWriter interface {
+	WriteMessages(ctx context.Context, msgs ...kafka.Message) error
+}
+
+// DeadLetterTopic возвращает имя dead-letter топика для topic
+func DeadLetterTopic(topic string) string {
+	return topic + ".dlq"
+}
+
+// NewDeadLetterWriter создает writer dead-letter топика для topic
+func NewDeadLetterWriter(brokers []string, topic string) *kafka.Writer {
+	return &kafka.Writer{
+		Addr:                   kafka.TCP(brokers...),
+		Topic:                  DeadLetterTopic(topic),
+		Balancer:               &kafka.Hash{},
+		RequiredAcks:           kafka.RequireAll,
+		AllowAutoTopicCreation: true,
+	}
+}
+
+// DeadLetter - сообщение из dead-letter топика с причиной и исходной позицией
+type DeadLetter struct {
+	// Message - сообщение в dead-letter топике
+	Message   kafka.Message
+	Error     string
+	Attempts  int
+	Topic     string
+	Partition int
+	Offset    int64
+	FailedAt  time.Time
+}
+
+// ParseDeadLetter разбирает заголовки сообщения из dead-letter топика
+func ParseDeadLetter(msg kafka.Message) DeadLetter {
+	dl := DeadLetter{
+		Message: msg,
+		Error:   headerValue(msg.Headers, HeaderDLQError),
+		Topic:   headerValue(msg.Headers, HeaderDLQTopic),
+	}
+	dl.Attempts, _ = strconv.Atoi(headerValue(msg.Headers, HeaderDLQAttempts))
+	dl.Partition, _ = strconv.Atoi(headerValue(msg.Headers, HeaderDLQPartition))
+	dl.Offset, _ = strconv.ParseInt(headerValue(msg.Headers, HeaderDLQOffset), 10, 64)
+	dl.FailedAt, _ = time.Parse(time.RFC3339Nano, headerValue(msg.Headers, HeaderDLQFailedAt))
+	return dl
+}
+
+// ReplayMessage возвращает исходное сообщение для повторной отправки в основной топик:
+// ключ, тело и заголовки без служебных dlq-*
+func (d DeadLetter) ReplayMessage() kafka.Message {
+	var headers []kafka.Header
+	for _, h := range d.Message.Headers {
+		if !strings.HasPrefix(h.Key, dlqHeaderPrefix) {
+			headers = append(headers, h)
+		}
+	}
+	return kafka.Message{
+		Topic:   d.Topic,
+		Key:     d.Message.Key,
+		Value:   d.Message.Value,
+		Headers: headers,
+	}
+}
+
+// deadLetterMessage строит сообщение для dead-letter топика из необработанного msg
+func deadLetterMessage(msg kafka.Message, cause error, attempts int) kafka.Message {
+	headers := make([]kafka.Header, 0, len(msg.Headers)+6)
+	for _, h := range msg.Headers {
+		if !strings.HasPrefix(h.Key, dlqHeaderPrefix) {
+			headers = append(headers, h)
+		}
+	}
+	headers = append(headers,
+		kafka.Header{Key: HeaderDLQError, Value: []byte(cause.Error())},
+		kafka.Header{Key: HeaderDLQAttempts, Value: []byte(strconv.Itoa(attempts))},
+		kafka.Header{Key: HeaderDLQTopic, Value: []byte(msg.Topic)},
+		kafka.Header{Key: HeaderDLQPartition, Value: []byte(strconv.Itoa(msg.Partition))},
+		kafka.Header{Key: HeaderDLQOffset, Value: []byte(strconv.FormatInt(msg.Offset, 10))},
+		kafka.Header{Key: HeaderDLQFailedAt, Value: []byte(time.Now().UTC().Format(time.RFC3339Nano))},
+	)
+	return kafka.Message{Key: msg.Key, Value: msg.Value, Headers: headers}
+}
@@ -0,0 +1,67 @@
+package kafka_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/N0F1X3d/todo/pkg/kafka"
+	"github.com/N0F1X3d/todo/pkg/kafka/kafkatest"
+	kafkago "github.com/segmentio/kafka-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConsumer_DeadLettersFailedMessages(t *testing.T) {
+	broker := kafkatest.NewBroker(1)
+	produceEvents(t, broker, "1", kafka.ActionCreateTask, kafka.ActionDeleteTask)
+	// Испорченное сообщение уходит в DLQ без повторов
+	broker.Produce(testTopic, kafkago.Message{Value: []byte("{not json")})
+	// Первая запись в DLQ не удается: offset нельзя коммитить, пока сообщение не сохранено
+	broker.FailWrites(errors.New("broker unavailable"))
+
+	policy := fastRetry
+	policy.MaxAttempts = 2
+	dlqTopic := kafka.DeadLetterTopic(testTopic)
+	consumer := kafka.NewConsumerFromReader(broker.Reader(testTopic, testGroup),
+		kafka.WithRetryPolicy(policy),
+		kafka.WithDeadLetterQueue(broker.Writer(dlqTopic)),
+	)
+	startConsumer(t, consumer, func(event kafka.TaskEvent) error {
+		if event.Action == kafka.ActionCreateTask {
+			return errors.New("handler failed")
+		}
+		return nil
+	})
+
+	require.Eventually(t, func() bool {
+		return broker.Committed(testGroup, testTopic)[0] == 3
+	}, time.Second, time.Millisecond)
+
+	written := broker.Messages(dlqTopic)
+	require.Len(t, written, 2)
+
+	failed := kafka.ParseDeadLetter(written[0])
+	assert.Equal(t, "handler failed", failed.Error)
+	assert.Equal(t, 2, failed.Attempts)
+	assert.Equal(t, testTopic, failed.Topic)
+	assert.Equal(t, int64(0), failed.Offset)
+	assert.WithinDuration(t, time.Now(), failed.FailedAt, time.Second)
+
+	poison := kafka.ParseDeadLetter(written[1])
+	assert.Contains(t, poison.Error, "decode event")
+	assert.Equal(t, 1, poison.Attempts)
+	assert.Equal(t, int64(2), poison.Offset)
+
+	// При повторной отправке служебные заголовки снимаются
+	original := broker.Messages(testTopic)[0]
+	replay := failed.ReplayMessage()
+	assert.Equal(t, testTopic, replay.Topic)
+	assert.Equal(t, original.Key, replay.Key)
+	assert.Equal(t, original.Value, replay.Value)
+	assert.Equal(t, original.Headers, replay.Headers)
+}
+
+func TestDeadLetterTopic(t *testing.T) {
+	assert.Equal(t, "task-events.dlq", kafka.DeadLetterTopic("task-events"))
+}
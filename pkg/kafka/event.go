@@ -1,8 +1,111 @@
 package kafka
 
-import "time"
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"time"
+)
 
+// Действия с задачами в TaskEvent.Action
+const (
+	ActionCreateTask   = "create-task"
+	ActionListTasks    = "list-tasks"
+	ActionCompleteTask = "complete-task"
+	ActionDeleteTask   = "delete-task"
+)
+
+// Версии схемы TaskEvent
+const (
+	// SchemaVersionLegacy - сообщения старого формата: только action и db_request_time
+	SchemaVersionLegacy = 1
+	// SchemaVersion - текущая версия схемы
+	SchemaVersion = 2
+)
+
+// TaskSnapshot - состояние задачи до или после изменения
+type TaskSnapshot struct {
+	ID          int       `json:"id"`
+	UUID        string    `json:"uuid,omitempty"`
+	Title       string    `json:"title"`
+	Description string    `json:"description,omitempty"`
+	Completed   bool      `json:"completed"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// TaskEvent - событие о задаче. Before пуст для create-task, After - для delete-task,
+// у list-tasks нет ни TaskID, ни снимков.
 type TaskEvent struct {
-	Action        string    `json:"action"`
+	EventID       string `json:"event_id"`
+	SchemaVersion int    `json:"schema_version"`
+	Action        string `json:"action"`
+	TaskID        int    `json:"task_id,omitempty"`
+	// Actor - кто выполнил действие (проверенная identity клиента)
+	Actor string `json:"actor,omitempty"`
+	// ForwardedActor - от чьего имени, по словам клиента (заголовок X-Actor)
+	ForwardedActor string        `json:"forwarded_actor,omitempty"`
+	OccurredAt     time.Time     `json:"occurred_at"`
+	RequestID      string        `json:"request_id,omitempty"`
+	TraceID        string        `json:"trace_id,omitempty"`
+	Before         *TaskSnapshot `json:"before,omitempty"`
+	After          *TaskSnapshot `json:"after,omitempty"`
+	// DBRequestTime - когда начался запрос к БД (есть и в старом формате)
 	DBRequestTime time.Time `json:"db_request_time"`
+	// PublishedAt - время записи сообщения в топик; заполняет Consumer, в сообщение не входит
+	PublishedAt time.Time `json:"-"`
+}
+
+// NewTaskEvent создает событие текущей версии схемы с новым EventID
+// и метаданными запроса из ctx
+func NewTaskEvent(ctx context.Context, action string, dbRequestTime time.Time) TaskEvent {
+	meta := EventMetaFromContext(ctx)
+	return TaskEvent{
+		EventID:        NewEventID(),
+		SchemaVersion:  SchemaVersion,
+		Action:         action,
+		Actor:          meta.Actor,
+		ForwardedActor: meta.ForwardedActor,
+		OccurredAt:     time.Now().UTC(),
+		RequestID:      meta.RequestID,
+		TraceID:        meta.TraceID,
+		DBRequestTime:  dbRequestTime,
+	}
+}
+
+// NewEventID возвращает случайный UUID v4
+func NewEventID() string {
+	var b [16]byte
+	_, _ = rand.Read(b[:])
+	b[6] = (b[6] & 0x0f) | 0x40
+	b[8] = (b[8] & 0x3f) | 0x80
+
+	buf := make([]byte, 36)
+	hex.Encode(buf[0:8], b[0:4])
+	buf[8] = '-'
+	hex.Encode(buf[9:13], b[4:6])
+	buf[13] = '-'
+	hex.Encode(buf[14:18], b[6:8])
+	buf[18] = '-'
+	hex.Encode(buf[19:23], b[8:10])
+	buf[23] = '-'
+	hex.Encode(buf[24:], b[10:])
+	return string(buf)
+}
+
+// DecodeTaskEvent разбирает событие любой версии схемы. У сообщения старого формата
+// нет schema_version: оно получает SchemaVersionLegacy, а OccurredAt берется из
+// DBRequestTime. EventID и TaskID такого сообщения заполняет Consumer из метаданных Kafka.
+func DecodeTaskEvent(data []byte) (TaskEvent, error) {
+	var event TaskEvent
+	if err := json.Unmarshal(data, &event); err != nil {
+		return TaskEvent{}, err
+	}
+
+	if event.SchemaVersion == 0 {
+		event.SchemaVersion = SchemaVersionLegacy
+		event.OccurredAt = event.DBRequestTime
+	}
+	return event, nil
 }
@@ -0,0 +1,66 @@
+package kafka_test
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/N0F1X3d/todo/pkg/kafka"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDecodeTaskEvent_Legacy(t *testing.T) {
+	// Формат сообщений, которые уже лежат в топике
+	data := []byte(`{"action":"create-task","db_request_time":"2026-01-02T03:04:05Z"}`)
+
+	event, err := kafka.DecodeTaskEvent(data)
+	require.NoError(t, err)
+
+	requestTime := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	assert.Equal(t, kafka.SchemaVersionLegacy, event.SchemaVersion)
+	assert.Equal(t, kafka.ActionCreateTask, event.Action)
+	assert.True(t, requestTime.Equal(event.DBRequestTime))
+	assert.True(t, requestTime.Equal(event.OccurredAt))
+	assert.Nil(t, event.After)
+}
+
+func TestDecodeTaskEvent_RoundTrip(t *testing.T) {
+	ctx := kafka.ContextWithEventMeta(context.Background(), kafka.EventMeta{
+		Actor:          "api-service",
+		ForwardedActor: "alice",
+		RequestID:      "req-1",
+		TraceID:        "4bf92f3577b34da6a3ce929d0e0e4736",
+	})
+	now := time.Now().UTC().Truncate(time.Microsecond)
+
+	event := kafka.NewTaskEvent(ctx, kafka.ActionCompleteTask, now)
+	event.TaskID = 7
+	event.Before = &kafka.TaskSnapshot{ID: 7, Title: "task", CreatedAt: now, UpdatedAt: now}
+	event.After = &kafka.TaskSnapshot{ID: 7, Title: "task", Completed: true, CreatedAt: now, UpdatedAt: now}
+
+	data, err := json.Marshal(event)
+	require.NoError(t, err)
+
+	decoded, err := kafka.DecodeTaskEvent(data)
+	require.NoError(t, err)
+
+	assert.Equal(t, kafka.SchemaVersion, decoded.SchemaVersion)
+	assert.Equal(t, event.EventID, decoded.EventID)
+	assert.Equal(t, "api-service", decoded.Actor)
+	assert.Equal(t, "alice", decoded.ForwardedActor)
+	assert.Equal(t, "req-1", decoded.RequestID)
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", decoded.TraceID)
+	assert.Equal(t, 7, decoded.TaskID)
+	assert.False(t, decoded.Before.Completed)
+	assert.True(t, decoded.After.Completed)
+}
+
+func TestNewEventID_Unique(t *testing.T) {
+	first, second := kafka.NewEventID(), kafka.NewEventID()
+
+	assert.Len(t, first, 36)
+	assert.NotEqual(t, first, second)
+	assert.Equal(t, byte('4'), first[14])
+}
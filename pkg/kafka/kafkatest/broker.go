@@ -0,0 +1,232 @@
+// Package kafkatest - брокер Kafka в памяти для тестов кода поверх pkg/kafka:
+// партиции по хешу ключа, offset'ы consumer group, ошибки по требованию.
+package kafkatest
+
+import (
+	"context"
+	"hash/fnv"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+)
+
+// Broker хранит сообщения топиков и закоммиченные offset'ы групп
+type Broker struct {
+	mu         sync.Mutex
+	partitions int
+	topics     map[string][][]kafka.Message
+	// committed - следующий offset для чтения по группе, топику и партиции
+	committed   map[string]map[int]int64
+	fetchErrors []error
+	writeErrors []error
+	// produced закрывается и заменяется при каждой записи, чтобы разбудить читателей
+	produced chan struct{}
+}
+
+// NewBroker создает брокер, в котором у каждого топика partitions партиций
+func NewBroker(partitions int) *Broker {
+	return &Broker{
+		partitions: partitions,
+		topics:     map[string][][]kafka.Message{},
+		committed:  map[string]map[int]int64{},
+		produced:   make(chan struct{}),
+	}
+}
+
+// Produce записывает сообщения в topic. Партиция выбирается по хешу ключа
+// (сообщения без ключа попадают в партицию 0), Offset и Time проставляются брокером.
+func (b *Broker) Produce(topic string, msgs ...kafka.Message) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.produceLocked(topic, msgs)
+}
+
+func (b *Broker) produceLocked(topic string, msgs []kafka.Message) {
+	parts := b.topicLocked(topic)
+	for _, msg := range msgs {
+		partition := 0
+		if len(msg.Key) > 0 {
+			h := fnv.New32a()
+			_, _ = h.Write(msg.Key)
+			partition = int(h.Sum32() % uint32(b.partitions))
+		}
+		msg.Topic = topic
+		msg.Partition = partition
+		msg.Offset = int64(len(parts[partition]))
+		if msg.Time.IsZero() {
+			msg.Time = time.Now()
+		}
+		parts[partition] = append(parts[partition], msg)
+	}
+	close(b.produced)
+	b.produced = make(chan struct{})
+}
+
+func (b *Broker) topicLocked(topic string) [][]kafka.Message {
+	parts, ok := b.topics[topic]
+	if !ok {
+		parts = make([][]kafka.Message, b.partitions)
+		b.topics[topic] = parts
+	}
+	return parts
+}
+
+// Messages возвращает сообщения топика: по партициям, внутри - по offset
+func (b *Broker) Messages(topic string) []kafka.Message {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	var all []kafka.Message
+	for _, part := range b.topics[topic] {
+		all = append(all, part...)
+	}
+	return all
+}
+
+// Committed возвращает следующий offset для чтения группой по партициям топика
+func (b *Broker) Committed(group, topic string) map[int]int64 {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	offsets := map[int]int64{}
+	for p, offset := range b.committed[group+"/"+topic] {
+		offsets[p] = offset
+	}
+	return offsets
+}
+
+// FailFetches заставляет следующие len(errs) вызовов FetchMessage вернуть errs по порядку
+func (b *Broker) FailFetches(errs ...error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.fetchErrors = append(b.fetchErrors, errs...)
+}
+
+// FailWrites заставляет следующие len(errs) вызовов WriteMessages вернуть errs по порядку
+func (b *Broker) FailWrites(errs ...error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.writeErrors = append(b.writeErrors, errs...)
+}
+
+// Writer возвращает writer топика topic (kafka.MessageWriter)
+func (b *Broker) Writer(topic string) *Writer {
+	return &Writer{broker: b, topic: topic}
+}
+
+// Reader возвращает reader топика topic в группе group (kafka.MessageReader).
+// Чтение начинается с закоммиченных группой offset'ов.
+func (b *Broker) Reader(topic, group string) *Reader {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	pos := map[int]int64{}
+	for p, offset := range b.committed[group+"/"+topic] {
+		pos[p] = offset
+	}
+	return &Reader{broker: b, topic: topic, group: group, pos: pos}
+}
+
+// Writer пишет сообщения в топик брокера
+type Writer struct {
+	broker *Broker
+	topic  string
+}
+
+func (w *Writer) WriteMessages(ctx context.Context, msgs ...kafka.Message) error {
+	w.broker.mu.Lock()
+	defer w.broker.mu.Unlock()
+
+	if len(w.broker.writeErrors) > 0 {
+		err := w.broker.writeErrors[0]
+		w.broker.writeErrors = w.broker.writeErrors[1:]
+		return err
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	w.broker.produceLocked(w.topic, msgs)
+	return nil
+}
+
+func (w *Writer) Close() error { return nil }
+
+// Reader читает топик по очереди из всех партиций, сохраняя порядок внутри партиции
+type Reader struct {
+	broker *Broker
+	topic  string
+	group  string
+	pos    map[int]int64
+	next   int
+	closed bool
+}
+
+func (r *Reader) FetchMessage(ctx context.Context) (kafka.Message, error) {
+	for {
+		b := r.broker
+		b.mu.Lock()
+		if r.closed {
+			b.mu.Unlock()
+			return kafka.Message{}, io.EOF
+		}
+		if len(b.fetchErrors) > 0 {
+			err := b.fetchErrors[0]
+			b.fetchErrors = b.fetchErrors[1:]
+			b.mu.Unlock()
+			return kafka.Message{}, err
+		}
+
+		parts := b.topicLocked(r.topic)
+		for i := range parts {
+			p := (r.next + i) % len(parts)
+			if r.pos[p] < int64(len(parts[p])) {
+				msg := parts[p][r.pos[p]]
+				msg.HighWaterMark = int64(len(parts[p]))
+				r.pos[p]++
+				r.next = p + 1
+				b.mu.Unlock()
+				return msg, nil
+			}
+		}
+		produced := b.produced
+		b.mu.Unlock()
+
+		select {
+		case <-ctx.Done():
+			return kafka.Message{}, ctx.Err()
+		case <-produced:
+		}
+	}
+}
+
+// CommitMessages сдвигает offset группы за каждое из msgs (назад не двигает)
+func (r *Reader) CommitMessages(_ context.Context, msgs ...kafka.Message) error {
+	b := r.broker
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	key := r.group + "/" + r.topic
+	if b.committed[key] == nil {
+		b.committed[key] = map[int]int64{}
+	}
+	for _, msg := range msgs {
+		if msg.Offset+1 > b.committed[key][msg.Partition] {
+			b.committed[key][msg.Partition] = msg.Offset + 1
+		}
+	}
+	return nil
+}
+
+// Close прерывает FetchMessage с io.EOF, как kafka.Reader
+func (r *Reader) Close() error {
+	b := r.broker
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	r.closed = true
+	close(b.produced)
+	b.produced = make(chan struct{})
+	return nil
+}
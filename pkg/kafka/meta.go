@@ -0,0 +1,35 @@
+package kafka
+
+import "context"
+
+// Ключи gRPC metadata, через которые api-service передает метаданные запроса в db-service
+const (
+	MetadataRequestID = "x-request-id"
+	MetadataTraceID   = "x-trace-id"
+	// MetadataActor - пользователь, от имени которого действует клиент (EventMeta.ForwardedActor)
+	MetadataActor = "x-actor"
+)
+
+// EventMeta - метаданные запроса, попадающие в TaskEvent
+type EventMeta struct {
+	// Actor - проверенная identity клиента (CN сертификата при mTLS)
+	Actor string
+	// ForwardedActor - пользователь, от имени которого клиент выполнил запрос
+	// (заголовок X-Actor). Передается клиентом и не проверяется.
+	ForwardedActor string
+	RequestID      string
+	TraceID        string
+}
+
+type eventMetaKey struct{}
+
+// ContextWithEventMeta сохраняет метаданные запроса в ctx
+func ContextWithEventMeta(ctx context.Context, meta EventMeta) context.Context {
+	return context.WithValue(ctx, eventMetaKey{}, meta)
+}
+
+// EventMetaFromContext возвращает метаданные запроса из ctx (пустые, если их нет)
+func EventMetaFromContext(ctx context.Context) EventMeta {
+	meta, _ := ctx.Value(eventMetaKey{}).(EventMeta)
+	return meta
+}
@@ -2,40 +2,164 @@ package kafka
 
 import (
 	"context"
-	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
 	"time"
 
+	"github.com/N0F1X3d/todo/pkg/proto/events"
+	"github.com/N0F1X3d/todo/pkg/schemaregistry"
 	"github.com/segmentio/kafka-go"
 )
 
+// Message - готовое к отправке сообщение: ключ партиционирования и событие в JSON
+// (в этом виде события хранит outbox db-service)
+type Message struct {
+	Key   string
+	Value []byte
+}
+
+// ErrEncode - событие не удалось перекодировать в формат producer: повтор не поможет
+var ErrEncode = errors.New("encode event")
+
 type Producer struct {
-	writer *kafka.Writer
+	writer  *kafka.Writer
+	brokers []string
+	topic   string
+	codec   Codec
+	// schemaVersion - версия схемы в registry, 0 - схема не регистрировалась
+	schemaVersion int
 }
 
-func NewProducer(brokers []string, topic string) *Producer {
-	return &Producer{
+// ProducerOption настраивает Producer
+type ProducerOption func(*Producer)
+
+// WithCodec задает формат отправляемых событий (по умолчанию JSONCodec)
+func WithCodec(codec Codec) ProducerOption {
+	return func(p *Producer) {
+		p.codec = codec
+	}
+}
+
+// NewProducer создает producer топика topic. Партиция выбирается по хешу ключа,
+// поэтому сообщения с одним ключом читаются в порядке отправки.
+func NewProducer(brokers []string, topic string, opts ...ProducerOption) *Producer {
+	p := &Producer{
 		writer: &kafka.Writer{
 			Addr:         kafka.TCP(brokers...),
 			Topic:        topic,
-			Balancer:     &kafka.LeastBytes{},
+			Balancer:     &kafka.Hash{},
 			RequiredAcks: kafka.RequireAll,
 			BatchTimeout: 10 * time.Millisecond,
 		},
+		brokers: brokers,
+		topic:   topic,
+		codec:   JSONCodec,
 	}
+	for _, opt := range opts {
+		opt(p)
+	}
+	return p
+}
+
+// RegisterSchema регистрирует схему TaskEvent для топика в registry. Несовместимая
+// с уже зарегистрированной схема - ошибка; версия попадает в заголовок сообщений.
+func (p *Producer) RegisterSchema(ctx context.Context, registry schemaregistry.Registry) error {
+	schema, err := schemaregistry.ProtoSchema(&events.TaskEvent{})
+	if err != nil {
+		return err
+	}
+	version, err := registry.Register(ctx, schemaregistry.Subject(p.topic), schema)
+	if err != nil {
+		return err
+	}
+	p.schemaVersion = version
+	return nil
 }
 
 func (p *Producer) Send(ctx context.Context, key string, event TaskEvent) error {
-	value, err := json.Marshal(event)
+	value, err := p.codec.Marshal(event)
 	if err != nil {
 		return err
 	}
 	return p.writer.WriteMessages(ctx, kafka.Message{
-		Key:   []byte(key),
-		Value: value,
-		Time:  time.Now(),
+		Key:     []byte(key),
+		Value:   value,
+		Headers: p.headers(),
+		Time:    time.Now(),
 	})
 }
 
+// SendMessages отправляет сообщения одной пачкой, сохраняя их порядок внутри ключа.
+// Если формат producer не JSON, события перекодируются.
+func (p *Producer) SendMessages(ctx context.Context, msgs []Message) error {
+	now := time.Now()
+	headers := p.headers()
+	kafkaMsgs := make([]kafka.Message, 0, len(msgs))
+	for _, msg := range msgs {
+		value, err := p.encode(msg.Value)
+		if err != nil {
+			return fmt.Errorf("%w %s: %w", ErrEncode, msg.Key, err)
+		}
+		kafkaMsgs = append(kafkaMsgs, kafka.Message{
+			Key:     []byte(msg.Key),
+			Value:   value,
+			Headers: headers,
+			Time:    now,
+		})
+	}
+	return p.writer.WriteMessages(ctx, kafkaMsgs...)
+}
+
+// encode перекодирует событие из JSON в формат producer
+func (p *Producer) encode(value []byte) ([]byte, error) {
+	if p.codec.ContentType() == ContentTypeJSON {
+		return value, nil
+	}
+	event, err := DecodeTaskEvent(value)
+	if err != nil {
+		return nil, err
+	}
+	return p.codec.Marshal(event)
+}
+
+func (p *Producer) headers() []kafka.Header {
+	headers := []kafka.Header{{Key: HeaderContentType, Value: []byte(p.codec.ContentType())}}
+	if p.schemaVersion > 0 {
+		headers = append(headers, kafka.Header{Key: HeaderSchemaVersion, Value: []byte(strconv.Itoa(p.schemaVersion))})
+	}
+	return headers
+}
+
+// Ping проверяет, что хотя бы один из брокеров доступен и отвечает на запрос метаданных
+func (p *Producer) Ping(ctx context.Context) error {
+	if len(p.brokers) == 0 {
+		return errors.New("no kafka brokers configured")
+	}
+
+	var lastErr error
+	for _, broker := range p.brokers {
+		conn, err := kafka.DialContext(ctx, "tcp", broker)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+
+		if deadline, ok := ctx.Deadline(); ok {
+			_ = conn.SetDeadline(deadline)
+		}
+		_, err = conn.Brokers()
+		_ = conn.Close()
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		return nil
+	}
+
+	return fmt.Errorf("kafka brokers unavailable: %w", lastErr)
+}
+
 func (p *Producer) Close() error {
 	return p.writer.Close()
 }
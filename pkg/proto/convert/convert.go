@@ -0,0 +1,83 @@
+// Package convert содержит преобразования между версиями контракта TaskService
+// (proto v1 и proto/v2) и типами Go, общие для db-service и api-service.
+package convert
+
+import (
+	"errors"
+	"math"
+	"time"
+
+	pb "github.com/N0F1X3d/todo/pkg/proto"
+	pbv2 "github.com/N0F1X3d/todo/pkg/proto/v2"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// ErrIDOutOfRange - id задачи не помещается в int32 контракта v1
+var ErrIDOutOfRange = errors.New("task id out of int32 range")
+
+// Timestamp конвертирует time.Time в google.protobuf.Timestamp. Нулевое время - nil.
+func Timestamp(t time.Time) *timestamppb.Timestamp {
+	if t.IsZero() {
+		return nil
+	}
+	return timestamppb.New(t)
+}
+
+// Time конвертирует google.protobuf.Timestamp в time.Time. nil - нулевое время.
+func Time(ts *timestamppb.Timestamp) time.Time {
+	if ts == nil {
+		return time.Time{}
+	}
+	return ts.AsTime()
+}
+
+// FormatTimestamp форматирует Timestamp в RFC3339 в локальной зоне, как в контракте v1.
+// nil - пустая строка.
+func FormatTimestamp(ts *timestamppb.Timestamp) string {
+	if ts == nil {
+		return ""
+	}
+	return ts.AsTime().Local().Format(time.RFC3339)
+}
+
+// ID32 сужает id задачи до int32 контракта v1
+func ID32(id int64) (int32, error) {
+	if id > math.MaxInt32 || id < math.MinInt32 {
+		return 0, ErrIDOutOfRange
+	}
+	return int32(id), nil
+}
+
+// TaskToV1 конвертирует задачу v2 в ответ v1
+func TaskToV1(task *pbv2.Task) (*pb.TaskResponse, error) {
+	if task == nil {
+		return nil, nil
+	}
+
+	id, err := ID32(task.GetId())
+	if err != nil {
+		return nil, err
+	}
+
+	return &pb.TaskResponse{
+		Id:          id,
+		Title:       task.GetTitle(),
+		Description: task.GetDescription(),
+		Completed:   task.GetCompleted(),
+		CreatedAt:   FormatTimestamp(task.GetCreatedAt()),
+		UpdatedAt:   FormatTimestamp(task.GetUpdatedAt()),
+	}, nil
+}
+
+// TasksToV1 конвертирует список задач v2 в ответы v1
+func TasksToV1(tasks []*pbv2.Task) ([]*pb.TaskResponse, error) {
+	result := make([]*pb.TaskResponse, 0, len(tasks))
+	for _, task := range tasks {
+		converted, err := TaskToV1(task)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, converted)
+	}
+	return result, nil
+}
@@ -0,0 +1,54 @@
+package convert_test
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/N0F1X3d/todo/pkg/proto/convert"
+	pbv2 "github.com/N0F1X3d/todo/pkg/proto/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestTimestamp_RoundTrip(t *testing.T) {
+	now := time.Date(2024, 5, 1, 12, 30, 15, 123456000, time.UTC)
+
+	ts := convert.Timestamp(now)
+
+	assert.True(t, now.Equal(convert.Time(ts)))
+}
+
+func TestTimestamp_Zero(t *testing.T) {
+	assert.Nil(t, convert.Timestamp(time.Time{}))
+	assert.True(t, convert.Time(nil).IsZero())
+	assert.Empty(t, convert.FormatTimestamp(nil))
+}
+
+func TestTaskToV1(t *testing.T) {
+	created := time.Now()
+	task := &pbv2.Task{
+		Id:          7,
+		Uuid:        proto.String("0b7e4c8e-6a0b-4b8f-9a77-5a1f0b7d2c11"),
+		Title:       "task",
+		Description: "desc",
+		Completed:   true,
+		CreatedAt:   convert.Timestamp(created),
+		UpdatedAt:   convert.Timestamp(created),
+	}
+
+	resp, err := convert.TaskToV1(task)
+
+	require.NoError(t, err)
+	assert.Equal(t, int32(7), resp.Id)
+	assert.Equal(t, "task", resp.Title)
+	assert.True(t, resp.Completed)
+	assert.Equal(t, created.Format(time.RFC3339), resp.CreatedAt)
+}
+
+func TestTaskToV1_IDOutOfRange(t *testing.T) {
+	_, err := convert.TaskToV1(&pbv2.Task{Id: math.MaxInt32 + 1})
+
+	assert.ErrorIs(t, err, convert.ErrIDOutOfRange)
+}
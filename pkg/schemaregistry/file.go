@@ -0,0 +1,120 @@
+package schemaregistry
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// FileRegistry хранит схемы в JSON-файле. Подходит для одного процесса или
+// нескольких, регистрирующих одинаковые схемы: запись не защищена от гонок между процессами.
+type FileRegistry struct {
+	mu   sync.Mutex
+	path string
+}
+
+type fileContents struct {
+	// Subjects - версии схем по субъектам, версия i+1 лежит по индексу i
+	Subjects map[string][]Schema `json:"subjects"`
+}
+
+// NewFileRegistry создает registry поверх файла path; файл создается при первой регистрации
+func NewFileRegistry(path string) *FileRegistry {
+	return &FileRegistry{path: path}
+}
+
+// Register регистрирует схему, проверив обратную совместимость с последней версией
+func (r *FileRegistry) Register(_ context.Context, subject string, schema Schema) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	contents, err := r.load()
+	if err != nil {
+		return 0, err
+	}
+
+	versions := contents.Subjects[subject]
+	if n := len(versions); n > 0 {
+		latest := versions[n-1]
+		if latest.Message == schema.Message && bytes.Equal(latest.Descriptor, schema.Descriptor) {
+			return n, nil
+		}
+		if err := CheckBackward(latest, schema); err != nil {
+			return 0, fmt.Errorf("subject %s: %w", subject, err)
+		}
+	} else if _, err := schema.MessageDescriptor(); err != nil {
+		return 0, err
+	}
+
+	contents.Subjects[subject] = append(versions, schema)
+	if err := r.save(contents); err != nil {
+		return 0, err
+	}
+	return len(versions) + 1, nil
+}
+
+// Schema возвращает схему субъекта заданной версии
+func (r *FileRegistry) Schema(_ context.Context, subject string, version int) (Schema, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	contents, err := r.load()
+	if err != nil {
+		return Schema{}, err
+	}
+	versions := contents.Subjects[subject]
+	if version < 1 || version > len(versions) {
+		return Schema{}, fmt.Errorf("%s version %d: %w", subject, version, ErrNotFound)
+	}
+	return versions[version-1], nil
+}
+
+func (r *FileRegistry) load() (*fileContents, error) {
+	contents := &fileContents{Subjects: map[string][]Schema{}}
+
+	data, err := os.ReadFile(r.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return contents, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, contents); err != nil {
+		return nil, fmt.Errorf("parse %s: %w", r.path, err)
+	}
+	if contents.Subjects == nil {
+		contents.Subjects = map[string][]Schema{}
+	}
+	return contents, nil
+}
+
+// save пишет файл через временный и rename, чтобы читатели не видели его наполовину
+func (r *FileRegistry) save(contents *fileContents) error {
+	data, err := json.MarshalIndent(contents, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(r.path), 0o755); err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(r.path), filepath.Base(r.path)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), r.path)
+}
@@ -0,0 +1,188 @@
+// Package schemaregistry - локальная замена schema registry для схем событий Kafka.
+// Схема - protobuf-описание сообщения; при регистрации новой версии проверяется
+// обратная совместимость с последней: читатель новой схемы должен понимать данные,
+// записанные старой (и в protobuf, и в JSON).
+package schemaregistry
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+var (
+	// ErrNotFound - субъекта или версии нет в registry
+	ErrNotFound = errors.New("schema not found")
+	// ErrIncompatible - новая схема несовместима с последней зарегистрированной
+	ErrIncompatible = errors.New("schema is not backward compatible")
+)
+
+// Schema - protobuf-схема сообщения Message, описанная набором файлов Descriptor
+// (сериализованный FileDescriptorSet с зависимостями)
+type Schema struct {
+	Message    string `json:"message"`
+	Descriptor []byte `json:"descriptor"`
+}
+
+// Registry хранит версии схем по субъектам (обычно "<topic>-value")
+type Registry interface {
+	// Register регистрирует схему и возвращает ее версию. Схема, совпадающая
+	// с последней версией, не создает новую.
+	Register(ctx context.Context, subject string, schema Schema) (int, error)
+	// Schema возвращает схему субъекта заданной версии
+	Schema(ctx context.Context, subject string, version int) (Schema, error)
+}
+
+// Subject возвращает субъект для значений сообщений топика
+func Subject(topic string) string {
+	return topic + "-value"
+}
+
+// ProtoSchema строит схему сообщения msg вместе со всеми импортами его файла
+func ProtoSchema(msg proto.Message) (Schema, error) {
+	desc := msg.ProtoReflect().Descriptor()
+
+	set := &descriptorpb.FileDescriptorSet{}
+	seen := map[string]bool{}
+	var add func(fd protoreflect.FileDescriptor)
+	add = func(fd protoreflect.FileDescriptor) {
+		if seen[fd.Path()] {
+			return
+		}
+		seen[fd.Path()] = true
+		imports := fd.Imports()
+		for i := 0; i < imports.Len(); i++ {
+			add(imports.Get(i).FileDescriptor)
+		}
+		set.File = append(set.File, protodesc.ToFileDescriptorProto(fd))
+	}
+	add(desc.ParentFile())
+
+	data, err := proto.MarshalOptions{Deterministic: true}.Marshal(set)
+	if err != nil {
+		return Schema{}, err
+	}
+	return Schema{Message: string(desc.FullName()), Descriptor: data}, nil
+}
+
+// MessageDescriptor разбирает схему и возвращает описание ее сообщения
+func (s Schema) MessageDescriptor() (protoreflect.MessageDescriptor, error) {
+	var set descriptorpb.FileDescriptorSet
+	if err := proto.Unmarshal(s.Descriptor, &set); err != nil {
+		return nil, fmt.Errorf("parse descriptor: %w", err)
+	}
+	files, err := protodesc.NewFiles(&set)
+	if err != nil {
+		return nil, fmt.Errorf("build descriptor: %w", err)
+	}
+	desc, err := files.FindDescriptorByName(protoreflect.FullName(s.Message))
+	if err != nil {
+		return nil, fmt.Errorf("message %s: %w", s.Message, err)
+	}
+	md, ok := desc.(protoreflect.MessageDescriptor)
+	if !ok {
+		return nil, fmt.Errorf("%s is not a message", s.Message)
+	}
+	return md, nil
+}
+
+// CheckBackward проверяет, что данные, записанные схемой old, читаются схемой next.
+// Разрешено добавлять поля и удалять их с резервированием номера; нельзя менять
+// номер, имя (ломает JSON), тип или повторяемость существующего поля.
+func CheckBackward(old, next Schema) error {
+	oldDesc, err := old.MessageDescriptor()
+	if err != nil {
+		return fmt.Errorf("old schema: %w", err)
+	}
+	nextDesc, err := next.MessageDescriptor()
+	if err != nil {
+		return fmt.Errorf("new schema: %w", err)
+	}
+
+	var problems []string
+	compareMessages(oldDesc, nextDesc, map[[2]protoreflect.FullName]bool{}, &problems)
+	if len(problems) > 0 {
+		return fmt.Errorf("%w: %s", ErrIncompatible, strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+func compareMessages(old, next protoreflect.MessageDescriptor, seen map[[2]protoreflect.FullName]bool, problems *[]string) {
+	key := [2]protoreflect.FullName{old.FullName(), next.FullName()}
+	if seen[key] {
+		return
+	}
+	seen[key] = true
+
+	oldFields := old.Fields()
+	for i := 0; i < oldFields.Len(); i++ {
+		f := oldFields.Get(i)
+		nf := next.Fields().ByNumber(f.Number())
+		if nf == nil {
+			if !next.ReservedRanges().Has(f.Number()) {
+				*problems = append(*problems, fmt.Sprintf("%s: field %d removed without reserving its number", f.FullName(), f.Number()))
+			}
+			continue
+		}
+
+		if nf.Name() != f.Name() {
+			*problems = append(*problems, fmt.Sprintf("%s: field %d renamed to %s", f.FullName(), f.Number(), nf.Name()))
+		}
+		if nf.Cardinality() != f.Cardinality() {
+			*problems = append(*problems, fmt.Sprintf("%s: cardinality changed from %s to %s", f.FullName(), f.Cardinality(), nf.Cardinality()))
+		}
+		if !compatibleKinds(f.Kind(), nf.Kind()) {
+			*problems = append(*problems, fmt.Sprintf("%s: type changed from %s to %s", f.FullName(), f.Kind(), nf.Kind()))
+			continue
+		}
+
+		switch f.Kind() {
+		case protoreflect.MessageKind, protoreflect.GroupKind:
+			compareMessages(f.Message(), nf.Message(), seen, problems)
+		case protoreflect.EnumKind:
+			compareEnums(f.Enum(), nf.Enum(), problems)
+		}
+	}
+
+	// Новое поле со старым именем прочитает из JSON чужое значение
+	nextFields := next.Fields()
+	for i := 0; i < nextFields.Len(); i++ {
+		nf := nextFields.Get(i)
+		if oldFields.ByNumber(nf.Number()) != nil {
+			continue
+		}
+		if f := oldFields.ByName(nf.Name()); f != nil {
+			*problems = append(*problems, fmt.Sprintf("%s: name reused by field %d (was %d)", nf.FullName(), nf.Number(), f.Number()))
+		}
+	}
+}
+
+func compareEnums(old, next protoreflect.EnumDescriptor, problems *[]string) {
+	values := old.Values()
+	for i := 0; i < values.Len(); i++ {
+		v := values.Get(i)
+		if next.Values().ByNumber(v.Number()) == nil {
+			*problems = append(*problems, fmt.Sprintf("%s: enum value %d removed", v.FullName(), v.Number()))
+		}
+	}
+}
+
+// compatibleKinds допускает только расширение целых без смены кодирования
+func compatibleKinds(old, next protoreflect.Kind) bool {
+	if old == next {
+		return true
+	}
+	switch {
+	case old == protoreflect.Int32Kind && next == protoreflect.Int64Kind,
+		old == protoreflect.Uint32Kind && next == protoreflect.Uint64Kind,
+		old == protoreflect.Sint32Kind && next == protoreflect.Sint64Kind:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,134 @@
+package schemaregistry_test
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/N0F1X3d/todo/pkg/proto/events"
+	"github.com/N0F1X3d/todo/pkg/schemaregistry"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+func field(name string, number int32, typ descriptorpb.FieldDescriptorProto_Type) *descriptorpb.FieldDescriptorProto {
+	return &descriptorpb.FieldDescriptorProto{
+		Name:     proto.String(name),
+		JsonName: proto.String(name),
+		Number:   proto.Int32(number),
+		Label:    descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum(),
+		Type:     typ.Enum(),
+	}
+}
+
+// eventSchema строит схему test.Event с полями fields и зарезервированными номерами reserved
+func eventSchema(t *testing.T, fields []*descriptorpb.FieldDescriptorProto, reserved ...int32) schemaregistry.Schema {
+	t.Helper()
+
+	msg := &descriptorpb.DescriptorProto{Name: proto.String("Event"), Field: fields}
+	for _, n := range reserved {
+		msg.ReservedRange = append(msg.ReservedRange, &descriptorpb.DescriptorProto_ReservedRange{
+			Start: proto.Int32(n),
+			End:   proto.Int32(n + 1),
+		})
+	}
+	set := &descriptorpb.FileDescriptorSet{File: []*descriptorpb.FileDescriptorProto{{
+		Name:        proto.String("test/event.proto"),
+		Package:     proto.String("test"),
+		Syntax:      proto.String("proto3"),
+		MessageType: []*descriptorpb.DescriptorProto{msg},
+	}}}
+
+	data, err := proto.Marshal(set)
+	require.NoError(t, err)
+	return schemaregistry.Schema{Message: "test.Event", Descriptor: data}
+}
+
+var (
+	idField     = field("id", 1, descriptorpb.FieldDescriptorProto_TYPE_STRING)
+	actionField = field("action", 2, descriptorpb.FieldDescriptorProto_TYPE_STRING)
+	countField  = field("count", 3, descriptorpb.FieldDescriptorProto_TYPE_INT32)
+)
+
+func TestFileRegistry_RegistersVersions(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "schemas.json")
+	reg := schemaregistry.NewFileRegistry(path)
+
+	v1 := eventSchema(t, []*descriptorpb.FieldDescriptorProto{idField, actionField})
+	version, err := reg.Register(ctx, "events-value", v1)
+	require.NoError(t, err)
+	assert.Equal(t, 1, version)
+
+	// Повторная регистрация той же схемы не создает версию
+	version, err = reg.Register(ctx, "events-value", v1)
+	require.NoError(t, err)
+	assert.Equal(t, 1, version)
+
+	v2 := eventSchema(t, []*descriptorpb.FieldDescriptorProto{idField, actionField, countField})
+	version, err = reg.Register(ctx, "events-value", v2)
+	require.NoError(t, err)
+	assert.Equal(t, 2, version)
+
+	// Версии переживают перезапуск
+	stored, err := schemaregistry.NewFileRegistry(path).Schema(ctx, "events-value", 2)
+	require.NoError(t, err)
+	assert.Equal(t, v2, stored)
+
+	_, err = reg.Schema(ctx, "events-value", 3)
+	assert.ErrorIs(t, err, schemaregistry.ErrNotFound)
+}
+
+func TestFileRegistry_RejectsIncompatible(t *testing.T) {
+	ctx := context.Background()
+	reg := schemaregistry.NewFileRegistry(filepath.Join(t.TempDir(), "schemas.json"))
+
+	_, err := reg.Register(ctx, "events-value", eventSchema(t, []*descriptorpb.FieldDescriptorProto{idField, actionField, countField}))
+	require.NoError(t, err)
+
+	tests := []struct {
+		name   string
+		schema schemaregistry.Schema
+	}{
+		{"removed field", eventSchema(t, []*descriptorpb.FieldDescriptorProto{idField, actionField})},
+		{"renamed field", eventSchema(t, []*descriptorpb.FieldDescriptorProto{idField, actionField,
+			field("total", 3, descriptorpb.FieldDescriptorProto_TYPE_INT32)})},
+		{"changed type", eventSchema(t, []*descriptorpb.FieldDescriptorProto{idField, actionField,
+			field("count", 3, descriptorpb.FieldDescriptorProto_TYPE_STRING)})},
+		{"reused name", eventSchema(t, []*descriptorpb.FieldDescriptorProto{idField, actionField,
+			field("count", 4, descriptorpb.FieldDescriptorProto_TYPE_INT32)}, 3)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := reg.Register(ctx, "events-value", tt.schema)
+			assert.ErrorIs(t, err, schemaregistry.ErrIncompatible)
+		})
+	}
+}
+
+func TestFileRegistry_AllowsReservedRemovalAndWidening(t *testing.T) {
+	ctx := context.Background()
+	reg := schemaregistry.NewFileRegistry(filepath.Join(t.TempDir(), "schemas.json"))
+
+	_, err := reg.Register(ctx, "events-value", eventSchema(t, []*descriptorpb.FieldDescriptorProto{idField, actionField, countField}))
+	require.NoError(t, err)
+
+	next := eventSchema(t, []*descriptorpb.FieldDescriptorProto{idField,
+		field("count", 3, descriptorpb.FieldDescriptorProto_TYPE_INT64)}, 2)
+	version, err := reg.Register(ctx, "events-value", next)
+	require.NoError(t, err)
+	assert.Equal(t, 2, version)
+}
+
+func TestProtoSchema_TaskEvent(t *testing.T) {
+	schema, err := schemaregistry.ProtoSchema(&events.TaskEvent{})
+	require.NoError(t, err)
+	assert.Equal(t, "proto.events.TaskEvent", schema.Message)
+
+	md, err := schema.MessageDescriptor()
+	require.NoError(t, err)
+	assert.NotNil(t, md.Fields().ByName("after"))
+	require.NoError(t, schemaregistry.CheckBackward(schema, schema))
+}
@@ -0,0 +1,46 @@
+package tlsconfig
+
+import (
+	"context"
+
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/peer"
+)
+
+// PeerIdentity описывает клиента, предъявившего сертификат при mTLS
+type PeerIdentity struct {
+	CommonName string   `json:"common_name"`
+	DNSNames   []string `json:"dns_names,omitempty"`
+	URIs       []string `json:"uris,omitempty"`
+}
+
+// PeerIdentityFromContext извлекает identity клиента из контекста gRPC вызова.
+// Возвращает false, если соединение без TLS или клиент не предъявил
+// сертификат, прошедший проверку.
+func PeerIdentityFromContext(ctx context.Context) (PeerIdentity, bool) {
+	p, ok := peer.FromContext(ctx)
+	if !ok || p.AuthInfo == nil {
+		return PeerIdentity{}, false
+	}
+
+	tlsInfo, ok := p.AuthInfo.(credentials.TLSInfo)
+	if !ok {
+		return PeerIdentity{}, false
+	}
+
+	// Доверяем только проверенной цепочке, а не любому предъявленному сертификату
+	if len(tlsInfo.State.VerifiedChains) == 0 || len(tlsInfo.State.VerifiedChains[0]) == 0 {
+		return PeerIdentity{}, false
+	}
+	cert := tlsInfo.State.VerifiedChains[0][0]
+
+	identity := PeerIdentity{
+		CommonName: cert.Subject.CommonName,
+		DNSNames:   cert.DNSNames,
+	}
+	for _, uri := range cert.URIs {
+		identity.URIs = append(identity.URIs, uri.String())
+	}
+
+	return identity, true
+}
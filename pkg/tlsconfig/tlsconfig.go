@@ -0,0 +1,217 @@
+package tlsconfig
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/N0F1X3d/todo/pkg/logger"
+)
+
+// Reloader хранит сертификат, ключ и CA, загруженные с диска,
+// и перечитывает их, когда файлы меняются (ротация сертификатов).
+// Конфиги, созданные через ServerConfig/ClientConfig, всегда используют
+// актуальные данные без пересоздания gRPC сервера или соединения.
+type Reloader struct {
+	certFile string
+	keyFile  string
+	caFile   string
+
+	mu       sync.RWMutex
+	cert     *tls.Certificate
+	caPool   *x509.CertPool
+	modTimes map[string]time.Time
+}
+
+// NewReloader загружает файлы и возвращает Reloader.
+// certFile/keyFile можно не указывать (клиент без сертификата),
+// caFile можно не указывать (используются системные корневые CA).
+func NewReloader(certFile, keyFile, caFile string) (*Reloader, error) {
+	if (certFile == "") != (keyFile == "") {
+		return nil, errors.New("tls cert file and key file must be set together")
+	}
+
+	r := &Reloader{
+		certFile: certFile,
+		keyFile:  keyFile,
+		caFile:   caFile,
+		modTimes: make(map[string]time.Time),
+	}
+
+	if err := r.Reload(); err != nil {
+		return nil, err
+	}
+
+	return r, nil
+}
+
+// Reload перечитывает сертификат, ключ и CA с диска
+func (r *Reloader) Reload() error {
+	var cert *tls.Certificate
+	if r.certFile != "" {
+		c, err := tls.LoadX509KeyPair(r.certFile, r.keyFile)
+		if err != nil {
+			return fmt.Errorf("failed to load tls key pair: %w", err)
+		}
+		cert = &c
+	}
+
+	var pool *x509.CertPool
+	if r.caFile != "" {
+		pem, err := os.ReadFile(r.caFile)
+		if err != nil {
+			return fmt.Errorf("failed to read tls ca file: %w", err)
+		}
+		pool = x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return fmt.Errorf("no certificates found in tls ca file %s", r.caFile)
+		}
+	}
+
+	modTimes := r.currentModTimes()
+
+	r.mu.Lock()
+	r.cert = cert
+	r.caPool = pool
+	r.modTimes = modTimes
+	r.mu.Unlock()
+
+	return nil
+}
+
+// Changed сообщает, изменились ли файлы на диске с момента последней загрузки
+func (r *Reloader) Changed() bool {
+	current := r.currentModTimes()
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for file, modTime := range current {
+		if !modTime.Equal(r.modTimes[file]) {
+			return true
+		}
+	}
+	return false
+}
+
+// Watch проверяет файлы с интервалом interval и перезагружает их при изменении.
+// Ошибка загрузки не сбрасывает ранее загруженный сертификат.
+func (r *Reloader) Watch(ctx context.Context, interval time.Duration, log *logger.Logger) {
+	const op = "Watch"
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if !r.Changed() {
+				continue
+			}
+			if err := r.Reload(); err != nil {
+				log.ErrorWithContext("failed to reload tls certificates", err, op)
+				continue
+			}
+			log.Info("tls certificates reloaded", "cert_file", r.certFile, "ca_file", r.caFile)
+		}
+	}
+}
+
+// Certificate возвращает текущий сертификат (может быть nil)
+func (r *Reloader) Certificate() *tls.Certificate {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.cert
+}
+
+// CAPool возвращает текущий пул CA (nil - системные CA)
+func (r *Reloader) CAPool() *x509.CertPool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.caPool
+}
+
+func (r *Reloader) currentModTimes() map[string]time.Time {
+	modTimes := make(map[string]time.Time, 3)
+	for _, file := range []string{r.certFile, r.keyFile, r.caFile} {
+		if file == "" {
+			continue
+		}
+		if info, err := os.Stat(file); err == nil {
+			modTimes[file] = info.ModTime()
+		}
+	}
+	return modTimes
+}
+
+// ServerConfig возвращает tls.Config для сервера.
+// Если requireClientCert, клиент обязан предъявить сертификат,
+// подписанный CA из Reloader (mTLS).
+func ServerConfig(r *Reloader, requireClientCert bool) *tls.Config {
+	return &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		GetConfigForClient: func(*tls.ClientHelloInfo) (*tls.Config, error) {
+			cert := r.Certificate()
+			if cert == nil {
+				return nil, errors.New("server tls certificate is not configured")
+			}
+
+			cfg := &tls.Config{
+				MinVersion:   tls.VersionTLS12,
+				Certificates: []tls.Certificate{*cert},
+				ClientCAs:    r.CAPool(),
+				NextProtos:   []string{"h2"},
+			}
+			if requireClientCert {
+				cfg.ClientAuth = tls.RequireAndVerifyClientCert
+			}
+			return cfg, nil
+		},
+	}
+}
+
+// ClientConfig возвращает tls.Config для клиента.
+// Проверка сертификата сервера выполняется вручную в VerifyConnection,
+// чтобы использовать актуальный (перезагруженный) пул CA.
+func ClientConfig(r *Reloader, serverName string) *tls.Config {
+	return &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		ServerName: serverName,
+		GetClientCertificate: func(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+			if cert := r.Certificate(); cert != nil {
+				return cert, nil
+			}
+			// Пустой сертификат: сервер сам решит, допустимо ли это
+			return &tls.Certificate{}, nil
+		},
+		// Стандартная проверка отключена и заменена VerifyConnection ниже
+		InsecureSkipVerify: true,
+		VerifyConnection: func(cs tls.ConnectionState) error {
+			if len(cs.PeerCertificates) == 0 {
+				return errors.New("server did not present a certificate")
+			}
+
+			opts := x509.VerifyOptions{
+				DNSName:       serverName,
+				Roots:         r.CAPool(),
+				Intermediates: x509.NewCertPool(),
+			}
+			if opts.DNSName == "" {
+				opts.DNSName = cs.ServerName
+			}
+			for _, cert := range cs.PeerCertificates[1:] {
+				opts.Intermediates.AddCert(cert)
+			}
+
+			_, err := cs.PeerCertificates[0].Verify(opts)
+			return err
+		},
+	}
+}
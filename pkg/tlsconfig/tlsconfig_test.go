@@ -0,0 +1,279 @@
+package tlsconfig_test
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/N0F1X3d/todo/pkg/logger"
+	"github.com/N0F1X3d/todo/pkg/tlsconfig"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+// testCA - CA, сгенерированный на время теста
+type testCA struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+	pem  []byte
+}
+
+func newTestCA(t *testing.T, name string) *testCA {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	tmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(time.Now().UnixNano()),
+		Subject:               pkix.Name{CommonName: name},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageDigitalSignature,
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	require.NoError(t, err)
+
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+
+	return &testCA{
+		cert: cert,
+		key:  key,
+		pem:  pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+	}
+}
+
+// issue выпускает leaf-сертификат и возвращает PEM сертификата и ключа
+func (ca *testCA) issue(t *testing.T, commonName string, dnsNames []string, usage x509.ExtKeyUsage) ([]byte, []byte) {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: commonName},
+		DNSNames:     dnsNames,
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{usage},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, ca.cert, &key.PublicKey, ca.key)
+	require.NoError(t, err)
+
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+}
+
+type certFiles struct {
+	cert string
+	key  string
+	ca   string
+}
+
+func writeFiles(t *testing.T, dir, prefix string, certPEM, keyPEM, caPEM []byte) certFiles {
+	t.Helper()
+
+	files := certFiles{
+		cert: filepath.Join(dir, prefix+".crt"),
+		key:  filepath.Join(dir, prefix+".key"),
+		ca:   filepath.Join(dir, prefix+"-ca.crt"),
+	}
+	require.NoError(t, os.WriteFile(files.cert, certPEM, 0600))
+	require.NoError(t, os.WriteFile(files.key, keyPEM, 0600))
+	require.NoError(t, os.WriteFile(files.ca, caPEM, 0600))
+	return files
+}
+
+// startServer запускает gRPC сервер с health-сервисом и возвращает его адрес
+// и канал, в который пишется identity клиента для каждого вызова
+func startServer(t *testing.T, reloader *tlsconfig.Reloader, requireClientCert bool) (string, chan tlsconfig.PeerIdentity) {
+	t.Helper()
+
+	identities := make(chan tlsconfig.PeerIdentity, 10)
+	interceptor := func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		if identity, ok := tlsconfig.PeerIdentityFromContext(ctx); ok {
+			identities <- identity
+		}
+		return handler(ctx, req)
+	}
+
+	srv := grpc.NewServer(
+		grpc.Creds(credentials.NewTLS(tlsconfig.ServerConfig(reloader, requireClientCert))),
+		grpc.UnaryInterceptor(interceptor),
+	)
+	healthpb.RegisterHealthServer(srv, health.NewServer())
+
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	go func() { _ = srv.Serve(lis) }()
+	t.Cleanup(srv.Stop)
+
+	return lis.Addr().String(), identities
+}
+
+func healthCheck(addr string, reloader *tlsconfig.Reloader) error {
+	conn, err := grpc.NewClient(addr,
+		grpc.WithTransportCredentials(credentials.NewTLS(tlsconfig.ClientConfig(reloader, "localhost"))),
+	)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	_, err = healthpb.NewHealthClient(conn).Check(ctx, &healthpb.HealthCheckRequest{})
+	return err
+}
+
+func TestMutualTLS_Success(t *testing.T) {
+	dir := t.TempDir()
+	ca := newTestCA(t, "test-ca")
+
+	serverCert, serverKey := ca.issue(t, "db-service", []string{"localhost"}, x509.ExtKeyUsageServerAuth)
+	clientCert, clientKey := ca.issue(t, "api-service", []string{"api-service.internal"}, x509.ExtKeyUsageClientAuth)
+
+	serverFiles := writeFiles(t, dir, "server", serverCert, serverKey, ca.pem)
+	clientFiles := writeFiles(t, dir, "client", clientCert, clientKey, ca.pem)
+
+	serverReloader, err := tlsconfig.NewReloader(serverFiles.cert, serverFiles.key, serverFiles.ca)
+	require.NoError(t, err)
+	clientReloader, err := tlsconfig.NewReloader(clientFiles.cert, clientFiles.key, clientFiles.ca)
+	require.NoError(t, err)
+
+	addr, identities := startServer(t, serverReloader, true)
+
+	require.NoError(t, healthCheck(addr, clientReloader))
+
+	select {
+	case identity := <-identities:
+		assert.Equal(t, "api-service", identity.CommonName)
+		assert.Equal(t, []string{"api-service.internal"}, identity.DNSNames)
+	case <-time.After(time.Second):
+		t.Fatal("peer identity was not available to the handler")
+	}
+}
+
+func TestMutualTLS_ClientWithoutCertificateRejected(t *testing.T) {
+	dir := t.TempDir()
+	ca := newTestCA(t, "test-ca")
+
+	serverCert, serverKey := ca.issue(t, "db-service", []string{"localhost"}, x509.ExtKeyUsageServerAuth)
+	serverFiles := writeFiles(t, dir, "server", serverCert, serverKey, ca.pem)
+
+	serverReloader, err := tlsconfig.NewReloader(serverFiles.cert, serverFiles.key, serverFiles.ca)
+	require.NoError(t, err)
+	clientReloader, err := tlsconfig.NewReloader("", "", serverFiles.ca)
+	require.NoError(t, err)
+
+	addr, _ := startServer(t, serverReloader, true)
+
+	assert.Error(t, healthCheck(addr, clientReloader))
+}
+
+func TestTLS_ServerOnly(t *testing.T) {
+	dir := t.TempDir()
+	ca := newTestCA(t, "test-ca")
+
+	serverCert, serverKey := ca.issue(t, "db-service", []string{"localhost"}, x509.ExtKeyUsageServerAuth)
+	serverFiles := writeFiles(t, dir, "server", serverCert, serverKey, ca.pem)
+
+	serverReloader, err := tlsconfig.NewReloader(serverFiles.cert, serverFiles.key, "")
+	require.NoError(t, err)
+	clientReloader, err := tlsconfig.NewReloader("", "", serverFiles.ca)
+	require.NoError(t, err)
+
+	addr, identities := startServer(t, serverReloader, false)
+
+	require.NoError(t, healthCheck(addr, clientReloader))
+	assert.Empty(t, identities)
+}
+
+func TestTLS_UntrustedServerRejected(t *testing.T) {
+	dir := t.TempDir()
+	serverCA := newTestCA(t, "server-ca")
+	otherCA := newTestCA(t, "other-ca")
+
+	serverCert, serverKey := serverCA.issue(t, "db-service", []string{"localhost"}, x509.ExtKeyUsageServerAuth)
+	serverFiles := writeFiles(t, dir, "server", serverCert, serverKey, serverCA.pem)
+	otherFiles := writeFiles(t, dir, "other", serverCert, serverKey, otherCA.pem)
+
+	serverReloader, err := tlsconfig.NewReloader(serverFiles.cert, serverFiles.key, "")
+	require.NoError(t, err)
+	clientReloader, err := tlsconfig.NewReloader("", "", otherFiles.ca)
+	require.NoError(t, err)
+
+	addr, _ := startServer(t, serverReloader, false)
+
+	assert.Error(t, healthCheck(addr, clientReloader))
+}
+
+func TestReloader_PicksUpRotatedCertificates(t *testing.T) {
+	dir := t.TempDir()
+	oldCA := newTestCA(t, "old-ca")
+	newCA := newTestCA(t, "new-ca")
+
+	serverCert, serverKey := oldCA.issue(t, "db-service", []string{"localhost"}, x509.ExtKeyUsageServerAuth)
+	serverFiles := writeFiles(t, dir, "server", serverCert, serverKey, oldCA.pem)
+
+	serverReloader, err := tlsconfig.NewReloader(serverFiles.cert, serverFiles.key, "")
+	require.NoError(t, err)
+
+	addr, _ := startServer(t, serverReloader, false)
+
+	// Клиент уже доверяет только новому CA: до ротации соединение не проходит
+	newClientFiles := writeFiles(t, dir, "client", nil, nil, newCA.pem)
+	clientReloader, err := tlsconfig.NewReloader("", "", newClientFiles.ca)
+	require.NoError(t, err)
+	require.Error(t, healthCheck(addr, clientReloader))
+
+	// Ротация: на диск пишется сертификат от нового CA
+	rotatedCert, rotatedKey := newCA.issue(t, "db-service", []string{"localhost"}, x509.ExtKeyUsageServerAuth)
+	require.NoError(t, os.WriteFile(serverFiles.cert, rotatedCert, 0600))
+	require.NoError(t, os.WriteFile(serverFiles.key, rotatedKey, 0600))
+	future := time.Now().Add(time.Minute)
+	require.NoError(t, os.Chtimes(serverFiles.cert, future, future))
+	require.NoError(t, os.Chtimes(serverFiles.key, future, future))
+
+	require.True(t, serverReloader.Changed())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go serverReloader.Watch(ctx, 10*time.Millisecond, logger.New("pkg", "test-logs"))
+
+	require.Eventually(t, func() bool {
+		return !serverReloader.Changed()
+	}, 2*time.Second, 10*time.Millisecond)
+
+	// Тот же сервер без перезапуска отдает новый сертификат
+	assert.NoError(t, healthCheck(addr, clientReloader))
+}
+
+func TestNewReloader_CertWithoutKey(t *testing.T) {
+	_, err := tlsconfig.NewReloader("server.crt", "", "")
+	assert.Error(t, err)
+}